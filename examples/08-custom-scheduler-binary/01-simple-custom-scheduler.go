@@ -27,44 +27,471 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
-	"math"
+	"net/http"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 )
 
 // Scheduler is the main scheduler struct
 type Scheduler struct {
 	clientset *kubernetes.Clientset
 	schedulerName string
+
+	// profiles are keyed by the spec.schedulerName they serve; by default a
+	// single profile named schedulerName
+	profiles map[string]Profile
+
+	// podLister reads bound pods from the informer cache for node accounting
+	podLister corelisters.PodLister
+
+	// assumed holds pods bound by this scheduler that the informer
+	// has not observed on their node yet
+	assumed *assumeCache
+
+	// percentageOfNodesToScore caps how many feasible nodes are scored once
+	// there are more than minFeasibleNodesToScore (0 = adaptive default)
+	percentageOfNodesToScore int32
+
+	// nextStartNodeIndex rotates the sampled window so every node gets scored
+	nextStartNodeIndex int
+
+	// limitOvercommitRatio, when > 0, also rejects nodes where the sum of
+	// CPU/memory limits would exceed allocatable × ratio (0 = requests only)
+	limitOvercommitRatio float64
+
+	// metrics are exposed on /metrics by serveMetrics
+	metrics *schedulerMetrics
+
+	// recorder emits Events on pods the scheduler can't handle
+	recorder record.EventRecorder
+
+	// scheduleMu serializes schedulePod between the informer and requeues
+	scheduleMu sync.Mutex
 }
 
+// loadingLabel marks pods that are still loading their model; set by the
+// LLMCluster controller until the pod is Ready. They are never preempted.
+const loadingLabel = "serving.ai/loading"
+
+// requeueDelay is how long a pod waits before a retry after a failed cycle
+const requeueDelay = 5 * time.Second
+
+const (
+	// minFeasibleNodesToScore: below this many feasible nodes, score them all
+	minFeasibleNodesToScore = 100
+
+	// minFeasibleNodesPercentageToScore floors the adaptive percentage
+	minFeasibleNodesPercentageToScore = 5
+)
+
 // NewScheduler creates a new scheduler
 func NewScheduler(clientset *kubernetes.Clientset, schedulerName string) *Scheduler {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+
 	return &Scheduler{
 		clientset:     clientset,
 		schedulerName: schedulerName,
+		profiles:      map[string]Profile{schedulerName: defaultProfile(schedulerName)},
+		assumed:       newAssumeCache(),
+		metrics:       newSchedulerMetrics(),
+		recorder:      broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: schedulerName}),
+	}
+}
+
+// Scoring strategies
+const (
+	// strategySpread prefers nodes with the most free resources
+	strategySpread = "spread"
+	// strategyBinpack prefers the most allocated nodes, keeping others empty
+	strategyBinpack = "binpack"
+)
+
+// Profile is a named scheduling configuration. One binary can serve several
+// profiles; each pod picks one through spec.schedulerName.
+type Profile struct {
+	// Name is the spec.schedulerName this profile serves
+	Name string `json:"name"`
+
+	// Strategy is "spread" (default) or "binpack"
+	Strategy string `json:"strategy,omitempty"`
+
+	// Weights multiply each score component
+	Weights ScoreWeights `json:"weights,omitempty"`
+
+	// RequireGPUNodes filters out nodes without nvidia.com/gpu capacity,
+	// even for pods that don't request GPUs
+	RequireGPUNodes bool `json:"requireGPUNodes,omitempty"`
+}
+
+// ScoreWeights are per-component score multipliers
+type ScoreWeights struct {
+	CPU    int64 `json:"cpu"`
+	Memory int64 `json:"memory"`
+	GPU    int64 `json:"gpu"`
+	Zone   int64 `json:"zone"`
+}
+
+// defaultProfile returns the original single-profile behaviour
+func defaultProfile(name string) Profile {
+	return Profile{
+		Name:     name,
+		Strategy: strategySpread,
+		Weights:  ScoreWeights{CPU: 10, Memory: 10, GPU: 20, Zone: 5},
+	}
+}
+
+// profilesKey is the ConfigMap data key holding the JSON profile list
+const profilesKey = "profiles.json"
+
+// loadProfiles reads profiles from the ConfigMap "namespace/name", e.g.
+//
+//	[{"name": "gpu-binpack", "strategy": "binpack", "weights": {"gpu": 20}},
+//	 {"name": "gpu-spread", "weights": {"cpu": 10, "memory": 10, "gpu": 20, "zone": 5}}]
+//
+// and merges them over defaults (see parseProfiles).
+func loadProfiles(ctx context.Context, clientset *kubernetes.Clientset, ref string, defaults map[string]Profile) (map[string]Profile, error) {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("profiles ConfigMap %q must be namespace/name", ref)
+	}
+
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get profiles ConfigMap %s: %w", ref, err)
+	}
+	data, ok := cm.Data[profilesKey]
+	if !ok {
+		return nil, fmt.Errorf("profiles ConfigMap %s has no %s key", ref, profilesKey)
+	}
+
+	return parseProfiles(data, defaults)
+}
+
+// parseProfiles parses a profiles.json list and merges it over defaults: a
+// listed profile replaces the default of the same name, and defaults not
+// listed are kept, so a ConfigMap adding one profile doesn't stop pods using
+// the default scheduler name from being scheduled.
+func parseProfiles(data string, defaults map[string]Profile) (map[string]Profile, error) {
+	var list []Profile
+	if err := json.Unmarshal([]byte(data), &list); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", profilesKey, err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%s: no profiles defined", profilesKey)
+	}
+
+	listed := make(map[string]bool, len(list))
+	profiles := make(map[string]Profile, len(defaults)+len(list))
+	for name, p := range defaults {
+		profiles[name] = p
+	}
+	for _, p := range list {
+		if p.Name == "" {
+			return nil, fmt.Errorf("%s: profile without a name", profilesKey)
+		}
+		if listed[p.Name] {
+			return nil, fmt.Errorf("%s: duplicate profile %q", profilesKey, p.Name)
+		}
+		listed[p.Name] = true
+		switch p.Strategy {
+		case "":
+			p.Strategy = strategySpread
+		case strategySpread, strategyBinpack:
+		default:
+			return nil, fmt.Errorf("%s: profile %q has unknown strategy %q", profilesKey, p.Name, p.Strategy)
+		}
+		profiles[p.Name] = p
+	}
+	return profiles, nil
+}
+
+// Scheduling attempt results (the "result" metric label)
+const (
+	resultScheduled     = "scheduled"
+	resultUnschedulable = "unschedulable"
+	resultError         = "error"
+)
+
+// latencyBuckets are histogram upper bounds in seconds
+var latencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// schedulerMetrics holds Prometheus-style counters and histograms. They are
+// rendered in the text exposition format by hand so this single-file example
+// needs no dependency beyond client-go.
+type schedulerMetrics struct {
+	mu                 sync.Mutex
+	attempts           map[string]uint64 // by result
+	podsScheduled      uint64
+	bindingFailures    uint64
+	schedulingDuration map[string]*histogram // by result
+	filterDuration     *histogram
+	scoreDuration      *histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, non-cumulative
+	count  uint64
+	sum    float64
+}
+
+func newHistogram() *histogram {
+	return &histogram{counts: make([]uint64, len(latencyBuckets))}
+}
+
+func (h *histogram) observe(seconds float64) {
+	h.count++
+	h.sum += seconds
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+func newSchedulerMetrics() *schedulerMetrics {
+	return &schedulerMetrics{
+		attempts:           make(map[string]uint64),
+		schedulingDuration: make(map[string]*histogram),
+		filterDuration:     newHistogram(),
+		scoreDuration:      newHistogram(),
+	}
+}
+
+// observeAttempt records one scheduling attempt and its end-to-end latency
+func (m *schedulerMetrics) observeAttempt(result string, start time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts[result]++
+	if result == resultScheduled {
+		m.podsScheduled++
+	}
+	h, ok := m.schedulingDuration[result]
+	if !ok {
+		h = newHistogram()
+		m.schedulingDuration[result] = h
+	}
+	h.observe(time.Since(start).Seconds())
+}
+
+func (m *schedulerMetrics) observeFilter(start time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.filterDuration.observe(time.Since(start).Seconds())
+}
+
+func (m *schedulerMetrics) observeScore(start time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scoreDuration.observe(time.Since(start).Seconds())
+}
+
+func (m *schedulerMetrics) incBindingFailures() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bindingFailures++
+}
+
+// render writes all metrics in the Prometheus text exposition format
+func (m *schedulerMetrics) render() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	b.WriteString("# HELP custom_scheduler_schedule_attempts_total Scheduling attempts by result.\n")
+	b.WriteString("# TYPE custom_scheduler_schedule_attempts_total counter\n")
+	for _, result := range sortedKeys(m.attempts) {
+		fmt.Fprintf(&b, "custom_scheduler_schedule_attempts_total{result=%q} %d\n", result, m.attempts[result])
+	}
+
+	b.WriteString("# HELP custom_scheduler_pods_scheduled_total Pods successfully bound to a node.\n")
+	b.WriteString("# TYPE custom_scheduler_pods_scheduled_total counter\n")
+	fmt.Fprintf(&b, "custom_scheduler_pods_scheduled_total %d\n", m.podsScheduled)
+
+	b.WriteString("# HELP custom_scheduler_binding_failures_total Failed Bind API calls.\n")
+	b.WriteString("# TYPE custom_scheduler_binding_failures_total counter\n")
+	fmt.Fprintf(&b, "custom_scheduler_binding_failures_total %d\n", m.bindingFailures)
+
+	b.WriteString("# HELP custom_scheduler_scheduling_duration_seconds End-to-end scheduling latency by result.\n")
+	b.WriteString("# TYPE custom_scheduler_scheduling_duration_seconds histogram\n")
+	for _, result := range sortedKeys(m.schedulingDuration) {
+		writeHistogram(&b, "custom_scheduler_scheduling_duration_seconds", fmt.Sprintf("result=%q", result), m.schedulingDuration[result])
+	}
+
+	b.WriteString("# HELP custom_scheduler_filter_duration_seconds Latency of the filter phase.\n")
+	b.WriteString("# TYPE custom_scheduler_filter_duration_seconds histogram\n")
+	writeHistogram(&b, "custom_scheduler_filter_duration_seconds", "", m.filterDuration)
+
+	b.WriteString("# HELP custom_scheduler_score_duration_seconds Latency of the score phase.\n")
+	b.WriteString("# TYPE custom_scheduler_score_duration_seconds histogram\n")
+	writeHistogram(&b, "custom_scheduler_score_duration_seconds", "", m.scoreDuration)
+
+	return b.String()
+}
+
+func writeHistogram(b *strings.Builder, name, labels string, h *histogram) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	var cumulative uint64
+	for i, bound := range latencyBuckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(b, "%s_bucket{%s%sle=\"%s\"} %d\n", name, labels, sep, strconv.FormatFloat(bound, 'f', -1, 64), cumulative)
+	}
+	fmt.Fprintf(b, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.count)
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(b, "%s_sum%s %g\n", name, labels, h.sum)
+	fmt.Fprintf(b, "%s_count%s %d\n", name, labels, h.count)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// serveMetrics exposes /metrics and /healthz until ctx is cancelled
+func (s *Scheduler) serveMetrics(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(s.metrics.render()))
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Metrics server stopped: %v", err)
+		}
+	}()
+}
+
+// assumeCache reserves a pod's resources on its chosen node from the bind
+// decision until the informer reports the pod as bound. Without it, two pods
+// scheduled back-to-back can both land on a node with room for only one,
+// because the first binding isn't visible in the informer cache yet.
+type assumeCache struct {
+	mu   sync.Mutex
+	pods map[types.UID]assumedPod
+}
+
+type assumedPod struct {
+	nodeName string
+	requests v1.ResourceList
+	limits   v1.ResourceList
+}
+
+func newAssumeCache() *assumeCache {
+	return &assumeCache{pods: make(map[types.UID]assumedPod)}
+}
+
+// assume reserves pod's requests (and limits) on nodeName
+func (c *assumeCache) assume(pod *v1.Pod, nodeName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pods[pod.UID] = assumedPod{nodeName: nodeName, requests: podRequests(pod), limits: podLimits(pod)}
+}
+
+// forget releases a reservation (bind failed, or the pod is now observed)
+func (c *assumeCache) forget(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pods, uid)
+}
+
+// isAssumed reports whether the pod still holds a reservation
+func (c *assumeCache) isAssumed(uid types.UID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.pods[uid]
+	return ok
+}
+
+// reservedByNode returns the total requests and limits reserved per node
+func (c *assumeCache) reservedByNode() map[string]nodeUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	usage := make(map[string]nodeUsage)
+	for _, p := range c.pods {
+		usage[p.nodeName] = usage[p.nodeName].add(p.requests, p.limits)
+	}
+	return usage
+}
+
+// nodeUsage is what the pods bound to or reserved on a node request and
+// limit. The zero value is an empty node.
+type nodeUsage struct {
+	requests v1.ResourceList
+	limits   v1.ResourceList
+}
+
+// add returns u with requests and limits added
+func (u nodeUsage) add(requests, limits v1.ResourceList) nodeUsage {
+	if u.requests == nil {
+		u = nodeUsage{requests: v1.ResourceList{}, limits: v1.ResourceList{}}
+	}
+	addResources(u.requests, requests)
+	addResources(u.limits, limits)
+	return u
+}
+
+// without returns a copy of u with the pods' requests and limits taken out
+func (u nodeUsage) without(pods []*v1.Pod) nodeUsage {
+	out := nodeUsage{}.add(u.requests, u.limits)
+	for _, p := range pods {
+		subResources(out.requests, podRequests(p))
+		subResources(out.limits, podLimits(p))
 	}
+	return out
 }
 
 // Run starts the scheduler
 func (s *Scheduler) Run(ctx context.Context) error {
-	log.Printf("🚀 Starting custom scheduler: %s", s.schedulerName)
+	log.Printf("🚀 Starting custom scheduler: %s (profiles: %s)", s.schedulerName, strings.Join(sortedKeys(s.profiles), ", "))
 
 	// Create informer factory (resync every 10 minutes)
 	factory := informers.NewSharedInformerFactory(s.clientset, 10*time.Minute)
 
 	// Create pod informer
 	podInformer := factory.Core().V1().Pods().Informer()
+	s.podLister = factory.Core().V1().Pods().Lister()
 
 	// Add event handler for pod changes
 	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -74,8 +501,17 @@ func (s *Scheduler) Run(ctx context.Context) error {
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			pod := newObj.(*v1.Pod)
+			if pod.Spec.NodeName != "" {
+				// Binding observed: the pod is now counted from the lister
+				s.assumed.forget(pod.UID)
+			}
 			s.schedulePod(pod)
 		},
+		DeleteFunc: func(obj interface{}) {
+			if pod, ok := obj.(*v1.Pod); ok {
+				s.assumed.forget(pod.UID)
+			}
+		},
 	})
 
 	// Start informers
@@ -93,6 +529,9 @@ func (s *Scheduler) Run(ctx context.Context) error {
 
 // schedulePod schedules a single pod
 func (s *Scheduler) schedulePod(pod *v1.Pod) {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
 	// Skip if:
 	// - Pod is already scheduled
 	// - Pod is being deleted
@@ -101,123 +540,421 @@ func (s *Scheduler) schedulePod(pod *v1.Pod) {
 		return
 	}
 
-	if pod.Spec.SchedulerName != s.schedulerName {
+	profile, ok := s.profiles[pod.Spec.SchedulerName]
+	if !ok {
 		return
 	}
 
-	log.Printf("📋 Scheduling pod: %s/%s", pod.Namespace, pod.Name)
+	// Skip if a bind for this pod is already in flight
+	if s.assumed.isAssumed(pod.UID) {
+		return
+	}
+
+	// A pod without containers can't run; report it instead of scheduling
+	if len(pod.Spec.Containers) == 0 {
+		log.Printf("⚠ Pod %s/%s has no containers, skipping", pod.Namespace, pod.Name)
+		s.recorder.Event(pod, v1.EventTypeWarning, "FailedScheduling", "pod has no containers")
+		s.metrics.observeAttempt(resultError, time.Now())
+		return
+	}
+
+	// A bug in a filter or score function must not kill the informer
+	// goroutine, which would stop all scheduling
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("❌ Panic while scheduling %s/%s: %v", pod.Namespace, pod.Name, r)
+			s.recorder.Eventf(pod, v1.EventTypeWarning, "FailedScheduling", "internal scheduler error: %v", r)
+		}
+	}()
+
+	log.Printf("📋 Scheduling pod: %s/%s (profile %s)", pod.Namespace, pod.Name, profile.Name)
+	start := time.Now()
 
 	// Get all nodes
 	nodes, err := s.clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		log.Printf("Error listing nodes: %v", err)
+		s.metrics.observeAttempt(resultError, start)
 		return
 	}
 
+	// What each node already runs, computed once for filter and score
+	usage := s.usageByNode()
+
 	// Phase 1: Filter nodes
-	feasibleNodes := s.filterNodes(pod, nodes.Items)
+	filterStart := time.Now()
+	feasibleNodes := s.filterNodes(pod, nodes.Items, profile, usage)
+	s.metrics.observeFilter(filterStart)
 	if len(feasibleNodes) == 0 {
 		log.Printf("⚠ No feasible nodes for pod %s/%s", pod.Namespace, pod.Name)
+		if s.preempt(pod, nodes.Items, profile, usage) {
+			// Retry once the victims are gone
+			s.requeue(pod)
+		}
+		s.metrics.observeAttempt(resultUnschedulable, start)
 		return
 	}
 	log.Printf("  Feasible nodes: %d", len(feasibleNodes))
 
-	// Phase 2: Score nodes
-	nodeScores := s.scoreNodes(pod, feasibleNodes)
-	bestNode := s.selectBestNode(nodeScores)
+	// Phase 2: Score nodes (a rotating sample on large clusters)
+	nodesToScore := s.sampleNodesToScore(feasibleNodes)
+	if len(nodesToScore) < len(feasibleNodes) {
+		log.Printf("  Scoring %d of %d feasible nodes", len(nodesToScore), len(feasibleNodes))
+	}
+	scoreStart := time.Now()
+	nodeScores := s.scoreNodes(pod, nodesToScore, profile, usage)
+	bestNode, err := s.selectBestNode(nodeScores)
+	s.metrics.observeScore(scoreStart)
+	if err != nil {
+		log.Printf("❌ No node to bind %s/%s: %v", pod.Namespace, pod.Name, err)
+		s.recorder.Eventf(pod, v1.EventTypeWarning, "BindFailed", "%v; retrying in %s", err, requeueDelay)
+		s.metrics.observeAttempt(resultError, start)
+		s.requeue(pod)
+		return
+	}
 
-	// Phase 3: Bind pod to node
+	// Phase 3: Reserve resources, then bind pod to node
+	s.assumed.assume(pod, bestNode.Name)
 	err = s.bindPod(pod, bestNode)
 	if err != nil {
+		s.assumed.forget(pod.UID)
 		log.Printf("❌ Error binding pod: %v", err)
+		s.metrics.incBindingFailures()
+		s.metrics.observeAttempt(resultError, start)
 		return
 	}
 
 	log.Printf("✓ Scheduled %s/%s to %s", pod.Namespace, pod.Name, bestNode.Name)
+	s.metrics.observeAttempt(resultScheduled, start)
 }
 
-// filterNodes filters nodes based on hard constraints
-func (s *Scheduler) filterNodes(pod *v1.Pod, nodes []v1.Node) []v1.Node {
+// filterNodes filters nodes based on hard constraints, given each node's
+// usage from usageByNode
+func (s *Scheduler) filterNodes(pod *v1.Pod, nodes []v1.Node, profile Profile, usage map[string]nodeUsage) []v1.Node {
 	var feasible []v1.Node
 
 	for _, node := range nodes {
-		// Check 1: Node is ready
-		if !isNodeReady(node) {
-			continue
+		if s.nodeFits(pod, node, profile, usage[node.Name]) {
+			feasible = append(feasible, node)
 		}
+	}
 
-		// Check 2: Enough CPU
-		if !hasEnoughCPU(node, pod) {
-			continue
+	return feasible
+}
+
+// nodeFits runs the hard constraints for one node, given what is already
+// bound or reserved there
+func (s *Scheduler) nodeFits(pod *v1.Pod, node v1.Node, profile Profile, usage nodeUsage) bool {
+	// Check 1: Node is ready and not under resource pressure
+	if !isNodeReady(node) {
+		return false
+	}
+
+	// Profile filter: GPU nodes only
+	if profile.RequireGPUNodes {
+		if gpus := node.Status.Capacity["nvidia.com/gpu"]; gpus.IsZero() {
+			return false
 		}
+	}
 
-		// Check 3: Enough memory
-		if !hasEnoughMemory(node, pod) {
+	// Check 2: Enough CPU
+	if !hasEnoughCPU(node, pod, usage.requests) {
+		return false
+	}
+
+	// Check 3: Enough memory
+	if !hasEnoughMemory(node, pod, usage.requests) {
+		return false
+	}
+
+	// Check 4: Enough GPU (if requested)
+	if !hasEnoughGPU(node, pod, usage.requests) {
+		return false
+	}
+
+	// Check 4b: Limits within the overcommit ratio (if enabled)
+	if s.limitOvercommitRatio > 0 && !withinLimitOvercommit(node, pod, usage.limits, s.limitOvercommitRatio) {
+		return false
+	}
+
+	// Check 5: Tolerates taints
+	if !toleratesTaints(node, pod) {
+		return false
+	}
+
+	// Check 6: Matches node selector
+	return matchesNodeSelector(node, pod)
+}
+
+// usageByNode returns the requests and limits of the pods bound to each node
+// (per the informer cache) plus any reservations not yet observed there. It
+// walks the pod cache once, so a scheduling cycle costs O(nodes + pods)
+// rather than listing every pod again for each node filtered and scored.
+func (s *Scheduler) usageByNode() map[string]nodeUsage {
+	usage := s.assumed.reservedByNode()
+	if s.podLister == nil {
+		return usage
+	}
+
+	pods, err := s.podLister.List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing pods from cache: %v", err)
+		return usage
+	}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
 			continue
 		}
+		if s.assumed.isAssumed(p.UID) {
+			continue // already counted via the reservation
+		}
+		usage[p.Spec.NodeName] = usage[p.Spec.NodeName].add(podRequests(p), podLimits(p))
+	}
+	return usage
+}
 
-		// Check 4: Enough GPU (if requested)
-		if !hasEnoughGPU(node, pod) {
-			continue
+// preempt makes room for a pod that fits nowhere by deleting lower-priority
+// pods from one node, and reports whether room is being made (the pod should
+// be retried). Pods still loading a model are never victims; see
+// selectVictims.
+func (s *Scheduler) preempt(pod *v1.Pod, nodes []v1.Node, profile Profile, usage map[string]nodeUsage) bool {
+	if s.podLister == nil || podPriority(pod) <= 0 ||
+		(pod.Spec.PreemptionPolicy != nil && *pod.Spec.PreemptionPolicy == v1.PreemptNever) {
+		return false
+	}
+	pods, err := s.podLister.List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing pods from cache: %v", err)
+		return false
+	}
+
+	nodeName, victims, ok := s.selectVictims(pod, nodes, profile, usage, pods)
+	if !ok {
+		return false
+	}
+	for _, victim := range victims {
+		err := s.clientset.CoreV1().Pods(victim.Namespace).Delete(context.TODO(), victim.Name, metav1.DeleteOptions{})
+		if err != nil {
+			log.Printf("❌ Error preempting %s/%s: %v", victim.Namespace, victim.Name, err)
+			return false
 		}
+		log.Printf("  Preempted %s/%s on %s for %s/%s", victim.Namespace, victim.Name, nodeName, pod.Namespace, pod.Name)
+		s.recorder.Eventf(victim, v1.EventTypeNormal, "Preempted", "Preempted by %s/%s on node %s", pod.Namespace, pod.Name, nodeName)
+	}
+	return true
+}
 
-		// Check 5: Tolerates taints
-		if !toleratesTaints(node, pod) {
+// selectVictims picks the node where deleting the fewest lower-priority pods
+// lets pod fit, preferring the lowest priorities within a node. Pods labeled
+// loadingLabel are never picked: the LLMCluster controller sets it while a
+// model loads, and preempting such a pod throws away minutes of loading for
+// a pod that would itself have to start from scratch. Pods already
+// terminating count as gone, so a retry while earlier victims shut down
+// returns their node with no new victims.
+func (s *Scheduler) selectVictims(pod *v1.Pod, nodes []v1.Node, profile Profile, usage map[string]nodeUsage, pods []*v1.Pod) (string, []*v1.Pod, bool) {
+	priority := podPriority(pod)
+	candidates := map[string][]*v1.Pod{}
+	terminating := map[string][]*v1.Pod{}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
 			continue
 		}
-
-		// Check 6: Matches node selector
-		if !matchesNodeSelector(node, pod) {
+		if p.DeletionTimestamp != nil {
+			terminating[p.Spec.NodeName] = append(terminating[p.Spec.NodeName], p)
+			continue
+		}
+		if _, loading := p.Labels[loadingLabel]; loading || podPriority(p) >= priority {
 			continue
 		}
+		candidates[p.Spec.NodeName] = append(candidates[p.Spec.NodeName], p)
+	}
 
-		feasible = append(feasible, node)
+	bestNode, found := "", false
+	var bestVictims []*v1.Pod
+	for _, node := range nodes {
+		base := usage[node.Name].without(terminating[node.Name])
+		onNode := candidates[node.Name]
+		if !s.nodeFits(pod, node, profile, base.without(onNode)) {
+			continue // not enough even with every candidate gone
+		}
+		sort.Slice(onNode, func(i, j int) bool {
+			if podPriority(onNode[i]) != podPriority(onNode[j]) {
+				return podPriority(onNode[i]) < podPriority(onNode[j])
+			}
+			return onNode[i].Name < onNode[j].Name
+		})
+		n := 0
+		for !s.nodeFits(pod, node, profile, base.without(onNode[:n])) {
+			n++
+		}
+		if !found || n < len(bestVictims) || (n == len(bestVictims) && node.Name < bestNode) {
+			bestNode, bestVictims, found = node.Name, onNode[:n], true
+		}
 	}
+	return bestNode, bestVictims, found
+}
 
-	return feasible
+// podPriority returns the priority admission resolved from the pod's
+// priorityClassName, 0 if none
+func podPriority(pod *v1.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
 }
 
-// scoreNodes scores nodes based on preferences
-func (s *Scheduler) scoreNodes(pod *v1.Pod, nodes []v1.Node) map[string]int64 {
-	scores := make(map[string]int64)
+// numNodesToScore returns how many of numFeasible nodes to score, following
+// the default scheduler: all of them below minFeasibleNodesToScore, otherwise
+// percentageOfNodesToScore percent (adaptive when unset), never fewer than
+// minFeasibleNodesToScore.
+func (s *Scheduler) numNodesToScore(numFeasible int) int {
+	if numFeasible < minFeasibleNodesToScore || s.percentageOfNodesToScore >= 100 {
+		return numFeasible
+	}
 
-	for _, node := range nodes {
-		score := int64(0)
+	percentage := int(s.percentageOfNodesToScore)
+	if percentage <= 0 {
+		// 50% at small sizes, shrinking by 1% per 125 nodes
+		percentage = 50 - numFeasible/125
+		if percentage < minFeasibleNodesPercentageToScore {
+			percentage = minFeasibleNodesPercentageToScore
+		}
+	}
+
+	n := numFeasible * percentage / 100
+	if n < minFeasibleNodesToScore {
+		return minFeasibleNodesToScore
+	}
+	return n
+}
+
+// sampleNodesToScore returns the subset of feasible nodes to score. The
+// window starts where the previous one ended so that, across pods, every
+// feasible node is considered rather than always the first few in the list.
+func (s *Scheduler) sampleNodesToScore(feasible []v1.Node) []v1.Node {
+	n := s.numNodesToScore(len(feasible))
+	if n >= len(feasible) {
+		return feasible
+	}
+
+	start := s.nextStartNodeIndex % len(feasible)
+	sample := make([]v1.Node, 0, n)
+	for i := 0; i < n; i++ {
+		sample = append(sample, feasible[(start+i)%len(feasible)])
+	}
+	s.nextStartNodeIndex = (start + n) % len(feasible)
+	return sample
+}
+
+// maxNodeScore is the top of the normalized per-component score range
+const maxNodeScore = 100
+
+// scoreNodes scores nodes based on the profile's strategy and weights. Each
+// component is first normalized to 0–maxNodeScore across the candidate nodes,
+// since raw values have unrelated units (millicores, GB, GPUs, 0/100 for
+// zone) and the weights would otherwise be dominated by the largest unit.
+func (s *Scheduler) scoreNodes(pod *v1.Pod, nodes []v1.Node, profile Profile, usage map[string]nodeUsage) map[string]int64 {
+	binpack := profile.Strategy == strategyBinpack
+
+	// Raw component values, indexed like nodes
+	cpu := make([]int64, len(nodes))
+	memory := make([]int64, len(nodes))
+	gpu := make([]int64, len(nodes))
+	zone := make([]int64, len(nodes))
+	for i, node := range nodes {
+		used := usage[node.Name].requests
 
-		// Score 1: CPU utilization (prefer less utilized)
-		score += scoreCPUUtilization(node, pod) * 10
+		// Score 1: CPU utilization (spread: prefer less utilized)
+		cpu[i] = scoreCPUUtilization(node, pod, used, binpack)
 
-		// Score 2: Memory utilization (prefer less utilized)
-		score += scoreMemoryUtilization(node, pod) * 10
+		// Score 2: Memory utilization (spread: prefer less utilized)
+		memory[i] = scoreMemoryUtilization(node, pod, used, binpack)
 
-		// Score 3: GPU utilization (prefer less utilized)
-		score += scoreGPUUtilization(node, pod) * 20
+		// Score 3: GPU utilization (spread: prefer less utilized)
+		gpu[i] = scoreGPUUtilization(node, pod, used, binpack)
 
 		// Score 4: Zone locality (prefer same zone)
-		score += scoreZoneLocality(node, pod) * 5
+		zone[i] = scoreZoneLocality(node, pod)
+	}
+	normalizeScores(cpu)
+	normalizeScores(memory)
+	normalizeScores(gpu)
+	normalizeScores(zone)
 
-		scores[node.Name] = score
+	scores := make(map[string]int64)
+	for i, node := range nodes {
+		scores[node.Name] = cpu[i]*profile.Weights.CPU +
+			memory[i]*profile.Weights.Memory +
+			gpu[i]*profile.Weights.GPU +
+			zone[i]*profile.Weights.Zone
 	}
 
 	return scores
 }
 
-// selectBestNode selects the node with the highest score
-func (s *Scheduler) selectBestNode(scores map[string]int64) v1.Node {
-	var bestNode v1.Node
-	var bestScore int64 = -1
+// normalizeScores rescales values in place to 0–maxNodeScore relative to the
+// highest, like the default scheduler's DefaultNormalizeScore. Scaling by the
+// maximum rather than the min–max range keeps similar raw values close, so a
+// small difference in one component doesn't outweigh another component.
+func normalizeScores(values []int64) {
+	var highest int64
+	for _, v := range values {
+		if v > highest {
+			highest = v
+		}
+	}
+	for i, v := range values {
+		if highest <= 0 || v < 0 {
+			values[i] = 0
+			continue
+		}
+		values[i] = v * maxNodeScore / highest
+	}
+}
 
-	for nodeName, score := range scores {
-		if score > bestScore {
-			bestScore = score
-			node, err := s.clientset.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
-			if err == nil {
-				bestNode = *node
-			}
+// selectBestNode returns the highest-scoring node that can be fetched,
+// falling back to the next best when a Get fails (e.g. the node was just
+// deleted). Ties are broken by name so the choice is deterministic.
+func (s *Scheduler) selectBestNode(scores map[string]int64) (v1.Node, error) {
+	names := make([]string, 0, len(scores))
+	for nodeName := range scores {
+		names = append(names, nodeName)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if scores[names[i]] != scores[names[j]] {
+			return scores[names[i]] > scores[names[j]]
 		}
+		return names[i] < names[j]
+	})
+
+	var lastErr error
+	for _, nodeName := range names {
+		node, err := s.clientset.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+		if err != nil {
+			log.Printf("  Could not get node %s (score %d): %v", nodeName, scores[nodeName], err)
+			lastErr = err
+			continue
+		}
+		return *node, nil
 	}
 
-	return bestNode
+	return v1.Node{}, fmt.Errorf("none of %d scored nodes could be fetched: %v", len(names), lastErr)
+}
+
+// requeue retries scheduling the pod after requeueDelay, using its latest
+// state from the informer cache
+func (s *Scheduler) requeue(pod *v1.Pod) {
+	namespace, name := pod.Namespace, pod.Name
+	time.AfterFunc(requeueDelay, func() {
+		if s.podLister == nil {
+			return
+		}
+		latest, err := s.podLister.Pods(namespace).Get(name)
+		if err != nil {
+			return // deleted in the meantime
+		}
+		s.schedulePod(latest)
+	})
 }
 
 // bindPod binds a pod to a node
@@ -227,47 +964,128 @@ func (s *Scheduler) bindPod(pod *v1.Pod, node v1.Node) error {
 		Target:     v1.ObjectReference{Kind: "Node", Name: node.Name},
 	}
 
-	_, err := s.clientset.CoreV1().Pods(pod.Namespace).Bind(context.TODO(), binding, metav1.CreateOptions{})
-	return err
+	return s.clientset.CoreV1().Pods(pod.Namespace).Bind(context.TODO(), binding, metav1.CreateOptions{})
 }
 
 // Helper functions
 
+// isNodeReady requires NodeReady=True and no MemoryPressure, DiskPressure or
+// PIDPressure, mirroring the default scheduler; pods bound to a node under
+// pressure are likely to be evicted shortly after starting.
 func isNodeReady(node v1.Node) bool {
+	ready := false
 	for _, condition := range node.Status.Conditions {
-		if condition.Type == v1.NodeReady {
-			return condition.Status == v1.ConditionTrue
+		switch condition.Type {
+		case v1.NodeReady:
+			ready = condition.Status == v1.ConditionTrue
+		case v1.NodeMemoryPressure, v1.NodeDiskPressure, v1.NodePIDPressure:
+			if condition.Status == v1.ConditionTrue {
+				return false
+			}
 		}
 	}
-	return false
+	return ready
 }
 
-func hasEnoughCPU(node v1.Node, pod *v1.Pod) bool {
-	podCPU := pod.Spec.Containers[0].Resources.Requests.Cpu()
-	nodeAllocatableCPU := node.Status.Allocatable[v1.ResourceCPU]
-	return podCPU.Cmp(*nodeAllocatableCPU) <= 0
+func hasEnoughCPU(node v1.Node, pod *v1.Pod, used v1.ResourceList) bool {
+	requests := podRequests(pod)
+	podCPU := requests.Cpu()
+	nodeAvailableCPU := node.Status.Allocatable[v1.ResourceCPU]
+	nodeAvailableCPU.Sub(used[v1.ResourceCPU])
+	return podCPU.Cmp(nodeAvailableCPU) <= 0
 }
 
-func hasEnoughMemory(node v1.Node, pod *v1.Pod) bool {
-	podMem := pod.Spec.Containers[0].Resources.Requests.Memory()
-	nodeAllocatableMem := node.Status.Allocatable[v1.ResourceMemory]
-	return podMem.Cmp(*nodeAllocatableMem) <= 0
+func hasEnoughMemory(node v1.Node, pod *v1.Pod, used v1.ResourceList) bool {
+	requests := podRequests(pod)
+	podMem := requests.Memory()
+	nodeAvailableMem := node.Status.Allocatable[v1.ResourceMemory]
+	nodeAvailableMem.Sub(used[v1.ResourceMemory])
+	return podMem.Cmp(nodeAvailableMem) <= 0
 }
 
-func hasEnoughGPU(node v1.Node, pod *v1.Pod) bool {
-	podGPU := pod.Spec.Containers[0].Resources.Requests["nvidia.com/gpu"]
+func hasEnoughGPU(node v1.Node, pod *v1.Pod, used v1.ResourceList) bool {
+	podGPU := podRequests(pod)["nvidia.com/gpu"]
 	if podGPU.IsZero() {
 		return true // No GPU required
 	}
-	nodeGPU := node.Status.Capacity["nvidia.com/gpu"]
-	return podGPU.Cmp(*nodeGPU) <= 0
+	nodeAvailableGPU := node.Status.Capacity["nvidia.com/gpu"]
+	nodeAvailableGPU.Sub(used["nvidia.com/gpu"])
+	return podGPU.Cmp(nodeAvailableGPU) <= 0
+}
+
+// podRequests sums resource requests across the pod's containers. Init
+// containers run one at a time before them, so each only raises the total to
+// its own request if larger. Missing requests count as zero.
+func podRequests(pod *v1.Pod) v1.ResourceList {
+	total := v1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(total, container.Resources.Requests)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, ok := total[name]; !ok || quantity.Cmp(current) > 0 {
+				total[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	return total
+}
+
+// podLimits sums resource limits across the pod's containers. A container
+// without a limit falls back to its request, since that is the least it uses.
+func podLimits(pod *v1.Pod) v1.ResourceList {
+	total := v1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		limits := v1.ResourceList{}
+		for name, quantity := range container.Resources.Requests {
+			limits[name] = quantity
+		}
+		for name, quantity := range container.Resources.Limits {
+			limits[name] = quantity
+		}
+		addResources(total, limits)
+	}
+	return total
+}
+
+// withinLimitOvercommit checks that CPU and memory limits on the node,
+// including this pod's, stay within allocatable × ratio
+func withinLimitOvercommit(node v1.Node, pod *v1.Pod, usedLimits v1.ResourceList, ratio float64) bool {
+	podLimit := podLimits(pod)
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		allocatable := node.Status.Allocatable[name]
+		total := usedLimits[name]
+		total.Add(podLimit[name])
+		if float64(total.MilliValue()) > float64(allocatable.MilliValue())*ratio {
+			return false
+		}
+	}
+	return true
+}
+
+// addResources adds src into dst
+func addResources(dst, src v1.ResourceList) {
+	for name, quantity := range src {
+		sum := dst[name]
+		sum.Add(quantity)
+		dst[name] = sum
+	}
+}
+
+// subResources subtracts src from dst
+func subResources(dst, src v1.ResourceList) {
+	for name, quantity := range src {
+		diff := dst[name]
+		diff.Sub(quantity)
+		dst[name] = diff
+	}
 }
 
 func toleratesTaints(node v1.Node, pod *v1.Pod) bool {
 	for _, taint := range node.Spec.Taints {
 		tolerated := false
 		for _, toleration := range pod.Spec.Tolerations {
-			if toleration.MatchTaint(&taint) {
+			if toleration.ToleratesTaint(&taint) {
 				tolerated = true
 				break
 			}
@@ -291,38 +1109,69 @@ func matchesNodeSelector(node v1.Node, pod *v1.Pod) bool {
 	return true
 }
 
-func scoreCPUUtilization(node v1.Node, pod *v1.Pod) int64 {
-	// Simplified: use allocatable as proxy for available
+// freeOrUsed returns what remains of allocatable after used (spread), or
+// what is used including this pod's request (binpack)
+func freeOrUsed(node v1.Node, pod *v1.Pod, used v1.ResourceList, name v1.ResourceName, binpack bool) resource.Quantity {
+	if binpack {
+		total := used[name]
+		total.Add(podRequests(pod)[name])
+		return total
+	}
+	free := node.Status.Allocatable[name]
+	free.Sub(used[name])
+	return free
+}
+
+func scoreCPUUtilization(node v1.Node, pod *v1.Pod, used v1.ResourceList, binpack bool) int64 {
+	// Simplified: requests as a proxy for utilization
 	// In production, query actual utilization via metrics API
-	nodeCPU := node.Status.Allocatable[v1.ResourceCPU]
+	nodeCPU := freeOrUsed(node, pod, used, v1.ResourceCPU, binpack)
 	return int64(nodeCPU.MilliValue())
 }
 
-func scoreMemoryUtilization(node v1.Node, pod *v1.Pod) int64 {
-	nodeMem := node.Status.Allocatable[v1.ResourceMemory]
+func scoreMemoryUtilization(node v1.Node, pod *v1.Pod, used v1.ResourceList, binpack bool) int64 {
+	nodeMem := freeOrUsed(node, pod, used, v1.ResourceMemory, binpack)
 	return int64(nodeMem.Value() / (1024 * 1024 * 1024)) // Convert to GB
 }
 
-func scoreGPUUtilization(node v1.Node, pod *v1.Pod) int64 {
+func scoreGPUUtilization(node v1.Node, pod *v1.Pod, used v1.ResourceList, binpack bool) int64 {
 	nodeGPU := node.Status.Allocatable["nvidia.com/gpu"]
 	if nodeGPU.IsZero() {
 		return 0
 	}
-	// Prefer nodes with more available GPUs
-	return nodeGPU.Value()
+	// Spread: prefer nodes with more available GPUs; binpack: fewer
+	gpus := freeOrUsed(node, pod, used, "nvidia.com/gpu", binpack)
+	return gpus.Value()
 }
 
+// scoreZoneLocality sums the weights of the pod's preferred node affinity
+// terms that ask for the node's zone. A zone in nodeSelector or required
+// affinity is already enforced by the filter, leaving every candidate equal
+// here, so only a preference can tell nodes apart.
 func scoreZoneLocality(node v1.Node, pod *v1.Pod) int64 {
-	// If pod specifies zone preference
-	podZone := pod.Spec.NodeSelector["topology.kubernetes.io/zone"]
-	if podZone == "" {
+	nodeZone, ok := node.Labels[v1.LabelTopologyZone]
+	if !ok || pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil {
 		return 0
 	}
-	nodeZone := node.Labels["topology.kubernetes.io/zone"]
-	if nodeZone == podZone {
-		return 100
+	var score int64
+	for _, term := range pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		for _, expr := range term.Preference.MatchExpressions {
+			if expr.Key == v1.LabelTopologyZone && expr.Operator == v1.NodeSelectorOpIn && containsString(expr.Values, nodeZone) {
+				score += int64(term.Weight)
+				break
+			}
+		}
 	}
-	return 0
+	return score
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
 }
 
 func main() {
@@ -354,7 +1203,42 @@ func main() {
 	// Create and run scheduler
 	scheduler := NewScheduler(clientset, schedulerName)
 
+	// Optional: serve several profiles loaded from a ConfigMap (namespace/name)
+	if ref := os.Getenv("PROFILES_CONFIGMAP"); ref != "" {
+		profiles, err := loadProfiles(context.Background(), clientset, ref, scheduler.profiles)
+		if err != nil {
+			log.Fatalf("Error loading scheduler profiles: %v", err)
+		}
+		scheduler.profiles = profiles
+	}
+
+	// Optional: score only a percentage of feasible nodes on large clusters
+	if v := os.Getenv("PERCENTAGE_OF_NODES_TO_SCORE"); v != "" {
+		pct, err := strconv.Atoi(v)
+		if err != nil || pct < 0 || pct > 100 {
+			log.Fatalf("Invalid PERCENTAGE_OF_NODES_TO_SCORE %q: must be 0-100", v)
+		}
+		scheduler.percentageOfNodesToScore = int32(pct)
+	}
+
+	// Optional: bound the sum of limits per node (e.g. 1.5 = 150% of allocatable)
+	if v := os.Getenv("LIMIT_OVERCOMMIT_RATIO"); v != "" {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil || ratio < 0 {
+			log.Fatalf("Invalid LIMIT_OVERCOMMIT_RATIO %q: must be a non-negative number", v)
+		}
+		scheduler.limitOvercommitRatio = ratio
+	}
+
 	ctx := context.Background()
+
+	// Expose /metrics and /healthz (the Deployment probes :10251/healthz)
+	metricsAddr := os.Getenv("METRICS_BIND_ADDRESS")
+	if metricsAddr == "" {
+		metricsAddr = ":10251"
+	}
+	scheduler.serveMetrics(ctx, metricsAddr)
+
 	if err := scheduler.Run(ctx); err != nil {
 		log.Fatalf("Error running scheduler: %v", err)
 	}
@@ -0,0 +1,587 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+// newTestScheduler returns a scheduler without an API client; tests must
+// stop short of listing nodes or binding.
+func newTestScheduler(recorder record.EventRecorder) *Scheduler {
+	return &Scheduler{
+		schedulerName: "custom-scheduler",
+		profiles:      map[string]Profile{"custom-scheduler": defaultProfile("custom-scheduler")},
+		assumed:       newAssumeCache(),
+		metrics:       newSchedulerMetrics(),
+		recorder:      recorder,
+	}
+}
+
+func newTestNode(name, cpu, memory string) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+		},
+	}
+}
+
+func TestSchedulePodWithoutContainers(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	s := newTestScheduler(recorder)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "init-only", UID: "uid-1"},
+		Spec: v1.PodSpec{
+			SchedulerName: "custom-scheduler",
+			InitContainers: []v1.Container{{
+				Name:      "init",
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+			}},
+		},
+	}
+
+	s.schedulePod(pod)
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "FailedScheduling") || !strings.Contains(event, "no containers") {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Error("no event recorded for a pod without containers")
+	}
+}
+
+func TestResourceFitWithoutContainers(t *testing.T) {
+	node := newTestNode("node-a", "2", "4Gi")
+	empty := &v1.Pod{}
+	if !hasEnoughCPU(node, empty, v1.ResourceList{}) || !hasEnoughMemory(node, empty, v1.ResourceList{}) || !hasEnoughGPU(node, empty, v1.ResourceList{}) {
+		t.Error("a pod without containers or requests should fit an empty node")
+	}
+
+	initOnly := &v1.Pod{Spec: v1.PodSpec{InitContainers: []v1.Container{{
+		Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}},
+	}}}}
+	if hasEnoughCPU(node, initOnly, v1.ResourceList{}) {
+		t.Error("an init container's request should count against the node")
+	}
+}
+
+func newTestGPUPod(name string, gpus string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name)},
+		Spec: v1.PodSpec{
+			SchedulerName: "custom-scheduler",
+			Containers: []v1.Container{{
+				Name: "engine",
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+					v1.ResourceCPU:   resource.MustParse("1"),
+					"nvidia.com/gpu": resource.MustParse(gpus),
+				}},
+			}},
+		},
+	}
+}
+
+func TestAssumeCachePreventsDoubleBooking(t *testing.T) {
+	s := newTestScheduler(record.NewFakeRecorder(10))
+	node := newTestNode("gpu-a", "8", "64Gi")
+	node.Status.Allocatable["nvidia.com/gpu"] = resource.MustParse("1")
+	node.Status.Capacity = v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}
+	nodes := []v1.Node{node}
+	profile := s.profiles["custom-scheduler"]
+
+	first, second := newTestGPUPod("first", "1"), newTestGPUPod("second", "1")
+	if feasible := s.filterNodes(first, nodes, profile, s.usageByNode()); len(feasible) != 1 {
+		t.Fatalf("first pod: %d feasible nodes, want 1", len(feasible))
+	}
+	// Bind decided, but the informer hasn't seen the binding yet
+	s.assumed.assume(first, "gpu-a")
+	if feasible := s.filterNodes(second, nodes, profile, s.usageByNode()); len(feasible) != 0 {
+		t.Error("second pod fits the node whose only GPU is reserved")
+	}
+
+	// Once the informer reports the bound pod, it is counted from the
+	// lister instead, exactly once
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	bound := first.DeepCopy()
+	bound.Spec.NodeName = "gpu-a"
+	if err := indexer.Add(bound); err != nil {
+		t.Fatal(err)
+	}
+	s.podLister = corelisters.NewPodLister(indexer)
+	if used := s.usageByNode()["gpu-a"].requests; !used.Name("nvidia.com/gpu", resource.DecimalSI).Equal(resource.MustParse("1")) {
+		t.Errorf("GPUs used while assumed and observed = %v, want 1", used)
+	}
+	s.assumed.forget(first.UID)
+	if feasible := s.filterNodes(second, nodes, profile, s.usageByNode()); len(feasible) != 0 {
+		t.Error("second pod fits after the first pod's binding was observed")
+	}
+
+	// A failed bind releases the reservation
+	s = newTestScheduler(record.NewFakeRecorder(10))
+	s.assumed.assume(first, "gpu-a")
+	s.assumed.forget(first.UID)
+	if feasible := s.filterNodes(second, nodes, profile, s.usageByNode()); len(feasible) != 1 {
+		t.Error("reservation not released after a failed bind")
+	}
+}
+
+func TestNodePressureFiltered(t *testing.T) {
+	s := newTestScheduler(record.NewFakeRecorder(10))
+	pod := newTestGPUPod("pod", "0")
+
+	tests := []struct {
+		name      string
+		condition v1.NodeCondition
+		feasible  bool
+	}{
+		{name: "healthy", condition: v1.NodeCondition{Type: v1.NodeMemoryPressure, Status: v1.ConditionFalse}, feasible: true},
+		{name: "memory pressure", condition: v1.NodeCondition{Type: v1.NodeMemoryPressure, Status: v1.ConditionTrue}},
+		{name: "disk pressure", condition: v1.NodeCondition{Type: v1.NodeDiskPressure, Status: v1.ConditionTrue}},
+		{name: "PID pressure", condition: v1.NodeCondition{Type: v1.NodePIDPressure, Status: v1.ConditionTrue}},
+		{name: "not ready", condition: v1.NodeCondition{Type: v1.NodeReady, Status: v1.ConditionFalse}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := newTestNode("node-a", "8", "64Gi")
+			node.Status.Conditions = append(node.Status.Conditions, tt.condition)
+			if got := isNodeReady(node); got != tt.feasible {
+				t.Errorf("isNodeReady = %v, want %v", got, tt.feasible)
+			}
+			feasible := s.filterNodes(pod, []v1.Node{node}, s.profiles["custom-scheduler"], s.usageByNode())
+			if (len(feasible) == 1) != tt.feasible {
+				t.Errorf("%d feasible nodes, want feasible=%v", len(feasible), tt.feasible)
+			}
+		})
+	}
+}
+
+// newTestAPIServer serves the node list and pod bindings schedulePod needs,
+// failing bindings when bindStatus isn't 201.
+func newTestAPIServer(t *testing.T, nodes []v1.Node, bindStatus int) *kubernetes.Clientset {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/nodes":
+			_ = json.NewEncoder(w).Encode(&v1.NodeList{Items: nodes})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v1/nodes/"):
+			for i := range nodes {
+				if r.URL.Path == "/api/v1/nodes/"+nodes[i].Name {
+					_ = json.NewEncoder(w).Encode(&nodes[i])
+					return
+				}
+			}
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/binding"):
+			w.WriteHeader(bindStatus)
+			if bindStatus == http.StatusCreated {
+				_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Success"}`))
+			} else {
+				_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Conflict","code":409}`))
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clientset
+}
+
+func TestScheduleMetrics(t *testing.T) {
+	nodes := []v1.Node{newTestNode("node-a", "8", "64Gi")}
+
+	s := newTestScheduler(record.NewFakeRecorder(10))
+	s.clientset = newTestAPIServer(t, nodes, http.StatusCreated)
+	s.schedulePod(newTestGPUPod("ok", "0"))
+
+	s.clientset = newTestAPIServer(t, nodes, http.StatusConflict)
+	s.schedulePod(newTestGPUPod("conflict", "0"))
+
+	tooBig := newTestGPUPod("too-big", "0")
+	tooBig.Spec.Containers[0].Resources.Requests[v1.ResourceCPU] = resource.MustParse("64")
+	s.schedulePod(tooBig)
+
+	rendered := s.metrics.render()
+	for _, want := range []string{
+		`custom_scheduler_schedule_attempts_total{result="scheduled"} 1`,
+		`custom_scheduler_schedule_attempts_total{result="error"} 1`,
+		`custom_scheduler_schedule_attempts_total{result="unschedulable"} 1`,
+		"custom_scheduler_pods_scheduled_total 1\n",
+		"custom_scheduler_binding_failures_total 1\n",
+		`custom_scheduler_scheduling_duration_seconds_count{result="scheduled"} 1`,
+		"custom_scheduler_filter_duration_seconds_count 3\n",
+		"custom_scheduler_score_duration_seconds_count 2\n",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("metrics lack %q:\n%s", want, rendered)
+		}
+	}
+}
+
+func TestNormalizeScores(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int64
+		want   []int64
+	}{
+		{name: "scaled to the highest", values: []int64{16000, 15500, 8000}, want: []int64{100, 96, 50}},
+		{name: "all zero", values: []int64{0, 0}, want: []int64{0, 0}},
+		{name: "zone", values: []int64{100, 0}, want: []int64{100, 0}},
+		{name: "empty", values: []int64{}, want: []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := append([]int64(nil), tt.values...)
+			normalizeScores(values)
+			for i := range tt.want {
+				if values[i] != tt.want[i] {
+					t.Errorf("normalizeScores(%v) = %v, want %v", tt.values, values, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestZoneLocalityInfluencesWinner(t *testing.T) {
+	s := newTestScheduler(record.NewFakeRecorder(10))
+	profile := s.profiles["custom-scheduler"]
+	pod := newTestGPUPod("pod", "0")
+	pod.Spec.Affinity = &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{{
+			Weight: 50,
+			Preference: v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{
+				Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"zone-a"},
+			}}},
+		}},
+	}}
+	zonedNode := func(name, cpu, zone string) v1.Node {
+		node := newTestNode(name, cpu, "64Gi")
+		node.Labels = map[string]string{v1.LabelTopologyZone: zone}
+		return node
+	}
+	winner := func(nodes []v1.Node) (string, map[string]int64) {
+		// A preference filters nothing out: both zones stay candidates
+		usage := s.usageByNode()
+		feasible := s.filterNodes(pod, nodes, profile, usage)
+		if len(feasible) != len(nodes) {
+			t.Fatalf("%d of %d nodes feasible, want all", len(feasible), len(nodes))
+		}
+		scores := s.scoreNodes(pod, feasible, profile, usage)
+		best := ""
+		for name, score := range scores {
+			if best == "" || score > scores[best] || (score == scores[best] && name < best) {
+				best = name
+			}
+		}
+		return best, scores
+	}
+
+	// node-b has slightly more free CPU, node-a is in the preferred zone
+	if best, scores := winner([]v1.Node{zonedNode("node-a", "15500m", "zone-a"), zonedNode("node-b", "16", "zone-b")}); best != "node-a" {
+		t.Errorf("winner %s, scores %v; want the zone preference to outweigh a 3%% CPU difference", best, scores)
+	}
+
+	// A large CPU gap still wins over the zone
+	if best, scores := winner([]v1.Node{zonedNode("node-a", "15500m", "zone-a"), zonedNode("node-b", "64", "zone-b")}); best != "node-b" {
+		t.Errorf("winner %s, scores %v; want a 4x CPU difference to outweigh the zone", best, scores)
+	}
+
+	// Without a preference the zone plays no part
+	pod.Spec.Affinity = nil
+	if best, scores := winner([]v1.Node{zonedNode("node-a", "15500m", "zone-a"), zonedNode("node-b", "16", "zone-b")}); best != "node-b" {
+		t.Errorf("winner %s, scores %v; want the CPU difference to decide", best, scores)
+	}
+}
+
+// countingPodLister counts List calls on the pod cache
+type countingPodLister struct {
+	corelisters.PodLister
+	lists int
+}
+
+func (l *countingPodLister) List(selector labels.Selector) ([]*v1.Pod, error) {
+	l.lists++
+	return l.PodLister.List(selector)
+}
+
+func TestScheduleCycleListsPodsOnce(t *testing.T) {
+	nodes := make([]v1.Node, 300)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for i := range nodes {
+		nodes[i] = newTestNode(fmt.Sprintf("node-%03d", i), "8", "64Gi")
+		bound := newTestGPUPod(fmt.Sprintf("bound-%03d", i), "0")
+		bound.Spec.NodeName = nodes[i].Name
+		if err := indexer.Add(bound); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lister := &countingPodLister{PodLister: corelisters.NewPodLister(indexer)}
+
+	s := newTestScheduler(record.NewFakeRecorder(10))
+	s.clientset = newTestAPIServer(t, nodes, http.StatusCreated)
+	s.podLister = lister
+	s.percentageOfNodesToScore = 10
+	s.schedulePod(newTestGPUPod("pod", "0"))
+
+	if lister.lists != 1 {
+		t.Errorf("pod cache listed %d times for one cycle over %d nodes, want 1", lister.lists, len(nodes))
+	}
+	if !s.assumed.isAssumed("pod") {
+		t.Error("pod was not scheduled")
+	}
+}
+
+func TestSampleNodesToScore(t *testing.T) {
+	feasible := make([]v1.Node, 300)
+	for i := range feasible {
+		feasible[i] = newTestNode(fmt.Sprintf("node-%03d", i), "8", "64Gi")
+	}
+	s := newTestScheduler(record.NewFakeRecorder(10))
+
+	if got := len(s.sampleNodesToScore(feasible[:50])); got != 50 {
+		t.Errorf("scored %d of 50 feasible nodes, want all", got)
+	}
+
+	// 10% of 300 is below the floor of minFeasibleNodesToScore
+	s.percentageOfNodesToScore = 10
+	seen := map[string]bool{}
+	for cycle := 0; cycle < 3; cycle++ {
+		sample := s.sampleNodesToScore(feasible)
+		if len(sample) != minFeasibleNodesToScore {
+			t.Fatalf("cycle %d scored %d of %d nodes, want %d", cycle, len(sample), len(feasible), minFeasibleNodesToScore)
+		}
+		for _, node := range sample {
+			seen[node.Name] = true
+		}
+	}
+	if len(seen) != len(feasible) {
+		t.Errorf("3 rotating windows covered %d of %d nodes", len(seen), len(feasible))
+	}
+
+	s.percentageOfNodesToScore = 100
+	if got := len(s.sampleNodesToScore(feasible)); got != len(feasible) {
+		t.Errorf("percentageOfNodesToScore=100 scored %d of %d", got, len(feasible))
+	}
+}
+
+func TestPreemptionSkipsLoadingPods(t *testing.T) {
+	s := newTestScheduler(record.NewFakeRecorder(10))
+	profile := s.profiles["custom-scheduler"]
+	gpuNode := func(name string) v1.Node {
+		node := newTestNode(name, "16", "64Gi")
+		node.Status.Capacity = v1.ResourceList{"nvidia.com/gpu": resource.MustParse("8")}
+		return node
+	}
+	boundPod := func(name, nodeName string, priority int32, loading bool) *v1.Pod {
+		pod := newTestGPUPod(name, "8")
+		pod.Spec.NodeName = nodeName
+		pod.Spec.Priority = &priority
+		if loading {
+			pod.Labels = map[string]string{loadingLabel: "true"}
+		}
+		return pod
+	}
+	usageOf := func(pods []*v1.Pod) map[string]nodeUsage {
+		usage := map[string]nodeUsage{}
+		for _, p := range pods {
+			usage[p.Spec.NodeName] = usage[p.Spec.NodeName].add(podRequests(p), podLimits(p))
+		}
+		return usage
+	}
+	preemptor := newTestGPUPod("serving", "8")
+	high := int32(1000)
+	preemptor.Spec.Priority = &high
+
+	// gpu-a runs the lowest-priority pod, but it is still loading its model
+	nodes := []v1.Node{gpuNode("gpu-a"), gpuNode("gpu-b")}
+	pods := []*v1.Pod{boundPod("loading", "gpu-a", 1, true), boundPod("batch", "gpu-b", 10, false)}
+	usage := usageOf(pods)
+	if feasible := s.filterNodes(preemptor, nodes, profile, usage); len(feasible) != 0 {
+		t.Fatalf("%d feasible nodes before preemption, want 0", len(feasible))
+	}
+	nodeName, victims, ok := s.selectVictims(preemptor, nodes, profile, usage, pods)
+	if !ok || nodeName != "gpu-b" || len(victims) != 1 || victims[0].Name != "batch" {
+		t.Fatalf("selectVictims = %q %v %v, want the batch pod on gpu-b", nodeName, victims, ok)
+	}
+
+	// With only loading pods to pick from there is no victim at all
+	nodes, pods = nodes[:1], pods[:1]
+	if nodeName, victims, ok := s.selectVictims(preemptor, nodes, profile, usageOf(pods), pods); ok {
+		t.Errorf("selectVictims picked %v on %s; the only candidate is loading", victims, nodeName)
+	}
+
+	// Once Ready (label removed) the same pod is an ordinary victim
+	pods = []*v1.Pod{boundPod("loading", "gpu-a", 1, false)}
+	if _, victims, ok := s.selectVictims(preemptor, nodes, profile, usageOf(pods), pods); !ok || len(victims) != 1 {
+		t.Errorf("selectVictims = %v %v after loading finished, want the pod", victims, ok)
+	}
+
+	// Equal or higher priority pods are never victims either
+	pods = []*v1.Pod{boundPod("peer", "gpu-a", high, false)}
+	if _, victims, ok := s.selectVictims(preemptor, nodes, profile, usageOf(pods), pods); ok {
+		t.Errorf("selectVictims picked %v of equal priority", victims)
+	}
+}
+
+func TestParseProfilesMergesDefaults(t *testing.T) {
+	defaults := map[string]Profile{"custom-scheduler": defaultProfile("custom-scheduler")}
+
+	// A partial ConfigMap adds a profile and keeps the default
+	profiles, err := parseProfiles(`[{"name": "gpu-binpack", "strategy": "binpack", "weights": {"gpu": 50}}]`, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("profiles = %v, want the default plus gpu-binpack", sortedKeys(profiles))
+	}
+	if got := profiles["custom-scheduler"]; got != defaultProfile("custom-scheduler") {
+		t.Errorf("default profile = %+v, want it unchanged", got)
+	}
+	if got := profiles["gpu-binpack"]; got.Strategy != strategyBinpack || got.Weights.GPU != 50 {
+		t.Errorf("gpu-binpack = %+v", got)
+	}
+
+	// Listing the default name overrides it
+	profiles, err = parseProfiles(`[{"name": "custom-scheduler", "weights": {"gpu": 1}}]`, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := profiles["custom-scheduler"]; len(profiles) != 1 || got.Strategy != strategySpread || got.Weights != (ScoreWeights{GPU: 1}) {
+		t.Errorf("profiles = %+v, want only the overridden default", profiles)
+	}
+	if defaults["custom-scheduler"] != defaultProfile("custom-scheduler") {
+		t.Error("parseProfiles modified the defaults")
+	}
+
+	for _, data := range []string{`[]`, `[{"strategy": "spread"}]`, `[{"name": "a"}, {"name": "a"}]`, `[{"name": "a", "strategy": "pack"}]`} {
+		if _, err := parseProfiles(data, defaults); err == nil {
+			t.Errorf("parseProfiles(%s) succeeded", data)
+		}
+	}
+}
+
+func TestProfileSelectedBySchedulerName(t *testing.T) {
+	s := newTestScheduler(record.NewFakeRecorder(10))
+	profiles, err := parseProfiles(`[{"name": "gpu-binpack", "strategy": "binpack", "weights": {"gpu": 50}},
+		{"name": "gpu-spread", "weights": {"gpu": 50}}]`, s.profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.profiles = profiles
+
+	// gpu-a already runs 6 of its 8 GPUs, gpu-b is empty
+	nodes := make([]v1.Node, 2)
+	for i, name := range []string{"gpu-a", "gpu-b"} {
+		nodes[i] = newTestNode(name, "64", "512Gi")
+		nodes[i].Status.Allocatable["nvidia.com/gpu"] = resource.MustParse("8")
+		nodes[i].Status.Capacity = v1.ResourceList{"nvidia.com/gpu": resource.MustParse("8")}
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	running := newTestGPUPod("running", "6")
+	running.Spec.NodeName = "gpu-a"
+	if err := indexer.Add(running); err != nil {
+		t.Fatal(err)
+	}
+	s.podLister = corelisters.NewPodLister(indexer)
+	s.clientset = newTestAPIServer(t, nodes, http.StatusCreated)
+
+	binpacked, spread := newTestGPUPod("binpacked", "2"), newTestGPUPod("spread", "2")
+	binpacked.Spec.SchedulerName, spread.Spec.SchedulerName = "gpu-binpack", "gpu-spread"
+	for pod, want := range map[*v1.Pod]string{binpacked: "gpu-a", spread: "gpu-b"} {
+		s.schedulePod(pod)
+		s.assumed.mu.Lock()
+		got := s.assumed.pods[pod.UID].nodeName
+		s.assumed.mu.Unlock()
+		if got != want {
+			t.Errorf("%s pod bound to %q, want %s", pod.Spec.SchedulerName, got, want)
+		}
+		s.assumed.forget(pod.UID)
+	}
+}
+
+func TestLimitOvercommitRejectsNodeThatFitsOnRequests(t *testing.T) {
+	s := newTestScheduler(record.NewFakeRecorder(10))
+	profile := s.profiles["custom-scheduler"]
+	node := newTestNode("node-a", "8", "64Gi")
+
+	// Requests 1 CPU, but may burst to 7
+	bursty := func(name string) *v1.Pod {
+		pod := newTestGPUPod(name, "0")
+		pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{v1.ResourceCPU: resource.MustParse("7")}
+		return pod
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	running := bursty("running")
+	running.Spec.NodeName = "node-a"
+	if err := indexer.Add(running); err != nil {
+		t.Fatal(err)
+	}
+	s.podLister = corelisters.NewPodLister(indexer)
+	pod := bursty("pod")
+
+	// 2 of 8 CPUs requested: fits; limits 14 of 8
+	if feasible := s.filterNodes(pod, []v1.Node{node}, profile, s.usageByNode()); len(feasible) != 1 {
+		t.Fatal("pod does not fit on requests with the overcommit check off")
+	}
+	s.limitOvercommitRatio = 1.5
+	if feasible := s.filterNodes(pod, []v1.Node{node}, profile, s.usageByNode()); len(feasible) != 0 {
+		t.Error("14 CPUs of limits accepted on 8 allocatable at ratio 1.5")
+	}
+	if !withinLimitOvercommit(node, pod, v1.ResourceList{v1.ResourceCPU: resource.MustParse("7")}, 2) {
+		t.Error("14 CPUs of limits rejected on 8 allocatable at ratio 2")
+	}
+
+	// Without limits a container counts its requests
+	plain := newTestGPUPod("plain", "0")
+	if !withinLimitOvercommit(node, plain, v1.ResourceList{v1.ResourceCPU: resource.MustParse("7")}, 1) {
+		t.Error("8 CPUs of request-derived limits rejected on 8 allocatable at ratio 1")
+	}
+	if got := podLimits(plain); !got.Cpu().Equal(resource.MustParse("1")) {
+		t.Errorf("podLimits without limits = %v, want the 1 CPU request", got)
+	}
+}
+
+func TestSelectBestNodeFallsBack(t *testing.T) {
+	s := newTestScheduler(record.NewFakeRecorder(10))
+	s.clientset = newTestAPIServer(t, []v1.Node{newTestNode("node-b", "8", "64Gi"), newTestNode("node-c", "8", "64Gi")}, http.StatusCreated)
+
+	// node-a scored highest but has been deleted since the node list
+	node, err := s.selectBestNode(map[string]int64{"node-a": 300, "node-b": 200, "node-c": 200})
+	if err != nil {
+		t.Fatalf("selectBestNode: %v", err)
+	}
+	if node.Name != "node-b" {
+		t.Errorf("selected %s, want node-b (next best, first by name)", node.Name)
+	}
+
+	_, err = s.selectBestNode(map[string]int64{"node-x": 300, "node-y": 100})
+	if err == nil || !strings.Contains(err.Error(), "none of 2 scored nodes") {
+		t.Errorf("selectBestNode with no fetchable node: err = %v", err)
+	}
+}
@@ -6,6 +6,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // LLMClusterSpec defines the desired state of LLMCluster
@@ -13,6 +14,16 @@ type LLMClusterSpec struct {
 	// Model is the model identifier (e.g., meta-llama/Meta-Llama-3-70B)
 	Model string `json:"model"`
 
+	// ModelRevision pins the Hugging Face revision (commit hash, tag or
+	// branch) loaded for Model, so every pod gets identical weights
+	// +optional
+	ModelRevision string `json:"modelRevision,omitempty"`
+
+	// ServedModelName is the model name clients request on the
+	// OpenAI-compatible API (defaults to Model)
+	// +optional
+	ServedModelName string `json:"servedModelName,omitempty"`
+
 	// ModelSize is the size category (8B, 13B, 70B, 405B)
 	// +optional
 	ModelSize string `json:"modelSize,omitempty"`
@@ -23,7 +34,8 @@ type LLMClusterSpec struct {
 	// GPUsPerPod is the number of GPUs per pod
 	GPUsPerPod int `json:"gpusPerPod"`
 
-	// TensorParallelSize is the total TP size (replicas × gpusPerPod)
+	// TensorParallelSize is the total TP size; it must equal replicas ×
+	// gpusPerPod, which is used when it is unset
 	// +optional
 	TensorParallelSize int `json:"tensorParallelSize,omitempty"`
 
@@ -31,7 +43,8 @@ type LLMClusterSpec struct {
 	// +optional
 	Image string `json:"image,omitempty"`
 
-	// InferenceEngine is the type of inference engine
+	// InferenceEngine is the type of inference engine: vllm (default), tgi
+	// (text-generation-inference) or sglang. Image must provide the engine.
 	// +optional
 	InferenceEngine string `json:"inferenceEngine,omitempty"`
 
@@ -43,6 +56,10 @@ type LLMClusterSpec struct {
 	// +optional
 	Resources ResourceRequirements `json:"resources,omitempty"`
 
+	// Probes tunes the inference container's health probes
+	// +optional
+	Probes ProbeConfig `json:"probes,omitempty"`
+
 	// Router defines router/load balancer configuration
 	// +optional
 	Router RouterConfig `json:"router,omitempty"`
@@ -71,6 +88,10 @@ type LLMClusterSpec struct {
 	// +optional
 	Scheduling SchedulingConfig `json:"scheduling,omitempty"`
 
+	// Rollout controls how spec changes roll out to the model pods
+	// +optional
+	Rollout RolloutConfig `json:"rollout,omitempty"`
+
 	// HighAvailability defines HA settings
 	// +optional
 	HighAvailability HighAvailabilityConfig `json:"highAvailability,omitempty"`
@@ -82,6 +103,12 @@ type LLMClusterSpec struct {
 	// Security defines security settings
 	// +optional
 	Security SecurityConfig `json:"security,omitempty"`
+
+	// ReconcilePolicy is apply (default) or dryRun: compute the child
+	// objects and report what would change in status.pendingChanges
+	// without writing them
+	// +optional
+	ReconcilePolicy string `json:"reconcilePolicy,omitempty"`
 }
 
 // LLMClusterStatus defines the observed state of LLMCluster
@@ -106,6 +133,22 @@ type LLMClusterStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// ModelRevision is the model revision the pods are configured to load
+	// +optional
+	ModelRevision string `json:"modelRevision,omitempty"`
+
+	// EffectiveImage and EffectiveArgs are the image and full engine
+	// command line (entrypoint, rendered args, TLS flags) the pods run
+	// +optional
+	EffectiveImage string `json:"effectiveImage,omitempty"`
+	// +optional
+	EffectiveArgs []string `json:"effectiveArgs,omitempty"`
+
+	// PendingChanges lists the child object changes the last dryRun
+	// reconcile held back, e.g. "update Deployment x: spec.replicas"
+	// +optional
+	PendingChanges []string `json:"pendingChanges,omitempty"`
+
 	// RouterURL is the access URL for the service
 	// +optional
 	RouterURL string `json:"routerURL,omitempty"`
@@ -117,6 +160,11 @@ type LLMClusterStatus struct {
 	// Metrics contains cluster metrics
 	// +optional
 	Metrics ClusterMetrics `json:"metrics,omitempty"`
+
+	// EstimatedHourlyCost is Metrics.TotalGPUs (live replicas × gpusPerPod) ×
+	// the GPU-hour rate for the cluster's GPU type, from the operator's cost ConfigMap
+	// +optional
+	EstimatedHourlyCost string `json:"estimatedHourlyCost,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -129,6 +177,7 @@ type LLMClusterStatus struct {
 // +kubebuilder:printcolumn:name="GPUs",type=integer,JSONPath=`.spec.tensorParallelSize`
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Cost/h",type=string,JSONPath=`.status.estimatedHourlyCost`,priority=1
 
 // LLMCluster is the Schema for the llmclusters API
 type LLMCluster struct {
@@ -201,6 +250,69 @@ type InferenceArgs struct {
 	// GPUMemoryUtilization is the GPU memory utilization fraction (0.0-1.0)
 	// +optional
 	GPUMemoryUtilization float64 `json:"gpuMemoryUtilization,omitempty"`
+
+	// NumAttentionHeads is the model's attention head count, which the
+	// tensor-parallel size must divide. Defaults by ModelSize (Llama
+	// layouts); set it for other architectures.
+	// +optional
+	NumAttentionHeads int `json:"numAttentionHeads,omitempty"`
+
+	// PrefixCaching enables the engine's automatic prefix caching
+	// +optional
+	PrefixCaching PrefixCachingConfig `json:"prefixCaching,omitempty"`
+}
+
+// PrefixCachingConfig defines prefix (KV) cache reuse across requests
+type PrefixCachingConfig struct {
+	// Enabled turns on the engine's prefix-cache flags
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// SharedClaimName is an existing ReadWriteMany PVC mounted in every pod
+	// so replicas share offloaded prefix KV blocks (vLLM with LMCache)
+	// +optional
+	SharedClaimName string `json:"sharedClaimName,omitempty"`
+
+	// SharedCacheSizeGB caps the shared cache each pod writes (default 50)
+	// +optional
+	SharedCacheSizeGB int `json:"sharedCacheSizeGB,omitempty"`
+}
+
+// ProbeConfig defines inference container probe timings. All probes hit the
+// engine's /health endpoint.
+type ProbeConfig struct {
+	// StartupTimeoutSeconds is how long model download and load may take
+	// before the container is restarted (defaults by ModelSize, 10-60 min)
+	// +optional
+	StartupTimeoutSeconds int `json:"startupTimeoutSeconds,omitempty"`
+
+	// ReadinessPeriodSeconds is the readiness probe interval (default 10)
+	// +optional
+	ReadinessPeriodSeconds int `json:"readinessPeriodSeconds,omitempty"`
+
+	// LivenessPeriodSeconds is the liveness probe interval (default 10)
+	// +optional
+	LivenessPeriodSeconds int `json:"livenessPeriodSeconds,omitempty"`
+
+	// LivenessFailureThreshold is how many failed liveness probes in a row
+	// restart the container (default: enough to outlast MaxRequestSeconds,
+	// at least 3). An engine busy with a long generation can answer /health
+	// late without being hung, and restarting it under load cascades.
+	// +optional
+	LivenessFailureThreshold int `json:"livenessFailureThreshold,omitempty"`
+
+	// MaxRequestSeconds is the longest a request is expected to run, about
+	// TTFT + max output tokens x TPOT (4096 tokens at 50ms TPOT is ~205s).
+	// It sizes the default liveness failure threshold (default 300)
+	// +optional
+	MaxRequestSeconds int `json:"maxRequestSeconds,omitempty"`
+
+	// ReadinessAggregation decides when the cluster counts as ready:
+	// PerReplica (default) needs every pod ready; Rank0 needs only pod-0's
+	// HTTP readiness plus every rank Running, for tensor-parallel engines
+	// that serve from rank 0 only
+	// +optional
+	ReadinessAggregation string `json:"readinessAggregation,omitempty"`
 }
 
 // ResourceRequirements defines resource requirements
@@ -231,6 +343,62 @@ type RouterConfig struct {
 	// Type is the router implementation (nginx, envoy, custom)
 	// +optional
 	Type string `json:"type,omitempty"`
+
+	// Backends are the LLMCluster instances the router balances across
+	// +optional
+	Backends []RouterBackend `json:"backends,omitempty"`
+
+	// AutoReplicas sizes the router from the number of backends
+	// +optional
+	AutoReplicas RouterAutoReplicas `json:"autoReplicas,omitempty"`
+
+	// Weights is the percentage of traffic (0-100) per backend name, e.g.
+	// 5 for a canary; unlisted backends split the rest equally
+	// +optional
+	Weights map[string]int32 `json:"weights,omitempty"`
+
+	// WeightsConfigMap names a ConfigMap in the cluster's namespace whose
+	// entries (backend name: percentage) override Weights, so traffic can
+	// be shifted without editing the LLMCluster
+	// +optional
+	WeightsConfigMap string `json:"weightsConfigMap,omitempty"`
+}
+
+// RouterBackend defines a backend LLMCluster instance
+type RouterBackend struct {
+	// Name is the backend display name
+	Name string `json:"name"`
+
+	// Service is the backend Service name
+	Service string `json:"service"`
+
+	// Port is the backend Service port
+	// +optional
+	Port int `json:"port,omitempty"`
+
+	// Draining stops new requests to the backend while letting in-flight
+	// (streaming) ones finish, ahead of its removal
+	// +optional
+	Draining bool `json:"draining,omitempty"`
+}
+
+// RouterAutoReplicas sizes router replicas proportionally to backend count
+type RouterAutoReplicas struct {
+	// Enabled indicates whether router replicas track backend count
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// BackendsPerReplica is the number of backends served per router replica
+	// +optional
+	BackendsPerReplica int `json:"backendsPerReplica,omitempty"`
+
+	// MinReplicas is the lower bound on router replicas
+	// +optional
+	MinReplicas int `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper bound on router replicas (0 = unbounded)
+	// +optional
+	MaxReplicas int `json:"maxReplicas,omitempty"`
 }
 
 // QueueConfig defines request queue configuration
@@ -270,9 +438,30 @@ type AutoscalingConfig struct {
 	// +optional
 	TargetCPUUtilizationPercentage int `json:"targetCPUUtilizationPercentage,omitempty"`
 
-	// CustomMetric defines custom metric autoscaling
+	// CustomMetric scales on a Pods or External metric (e.g. GPU utilization
+	// or queue depth) instead of CPU utilization
 	// +optional
 	CustomMetric CustomMetric `json:"customMetric,omitempty"`
+
+	// Behavior defines HPA scale-up/scale-down stabilization
+	// +optional
+	Behavior AutoscalingBehavior `json:"behavior,omitempty"`
+}
+
+// AutoscalingBehavior defines HPA behavior during model load
+type AutoscalingBehavior struct {
+	// ScaleUpStabilizationWindowSeconds is the HPA scale-up stabilization window
+	// +optional
+	ScaleUpStabilizationWindowSeconds int `json:"scaleUpStabilizationWindowSeconds,omitempty"`
+
+	// ScaleDownStabilizationWindowSeconds is the HPA scale-down stabilization window
+	// +optional
+	ScaleDownStabilizationWindowSeconds int `json:"scaleDownStabilizationWindowSeconds,omitempty"`
+
+	// ReadyPodsMetric is a per-pod metric only reported by Ready pods.
+	// When set, it replaces CPU utilization so loading pods don't skew the average.
+	// +optional
+	ReadyPodsMetric CustomMetric `json:"readyPodsMetric,omitempty"`
 }
 
 // CustomMetric defines a custom metric for autoscaling
@@ -281,6 +470,15 @@ type CustomMetric struct {
 	// +optional
 	Name string `json:"name,omitempty"`
 
+	// Type is Pods (default, averaged over the pods' own metric) or External
+	// (a metric not tied to the pods, e.g. a queue); customMetric only
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Selector narrows an External metric by label
+	// +optional
+	Selector map[string]string `json:"selector,omitempty"`
+
 	// Target defines the metric target
 	// +optional
 	Target MetricTarget `json:"target,omitempty"`
@@ -291,21 +489,33 @@ type MetricTarget struct {
 	// AverageValue is the target average value
 	// +optional
 	AverageValue string `json:"averageValue,omitempty"`
+
+	// Value is the target total value of an External metric (e.g. keep the
+	// whole queue under 50); it takes precedence over AverageValue
+	// +optional
+	Value string `json:"value,omitempty"`
 }
 
 // CoordinationConfig defines distributed coordination settings
 type CoordinationConfig struct {
-	// Enabled indicates whether coordination is enabled
+	// Enabled sets the torch.distributed env (MASTER_ADDR, NODE_RANK/RANK
+	// from the pod ordinal, WORLD_SIZE) for tensor parallelism across pods
 	// +optional
 	Enabled bool `json:"enabled,omitempty"`
 
-	// LeaderElection indicates whether leader election is enabled
+	// LeaderElection makes pod-0 the leader: other ranks wait until its
+	// address resolves before starting the engine
 	// +optional
 	LeaderElection bool `json:"leaderElection,omitempty"`
 
 	// PodManagementPolicy is the StatefulSet pod management policy
 	// +optional
 	PodManagementPolicy string `json:"podManagementPolicy,omitempty"`
+
+	// NCCL is distributed backend env (NCCL_*, GLOO_*, TORCH_NCCL_*) merged over
+	// the operator defaults for cloud networking
+	// +optional
+	NCCL map[string]string `json:"nccl,omitempty"`
 }
 
 // MonitoringConfig defines observability settings
@@ -325,6 +535,33 @@ type MonitoringConfig struct {
 	// DCGMExporter indicates whether DCGM exporter is enabled
 	// +optional
 	DCGMExporter bool `json:"dcgmExporter,omitempty"`
+
+	// MetricsPort is the engine metrics port (defaults to the inference port)
+	// +optional
+	MetricsPort int `json:"metricsPort,omitempty"`
+
+	// LogShipping adds a sidecar that tails and ships engine logs
+	// +optional
+	LogShipping LogShippingConfig `json:"logShipping,omitempty"`
+}
+
+// LogShippingConfig defines the engine log shipping sidecar
+type LogShippingConfig struct {
+	// Enabled indicates whether the log shipping sidecar is added
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Image is the fluent-bit compatible sidecar image
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Output is the fluent-bit output plugin (default stdout)
+	// +optional
+	Output string `json:"output,omitempty"`
+
+	// OutputProperties are passed to the output plugin as -p key=value
+	// +optional
+	OutputProperties map[string]string `json:"outputProperties,omitempty"`
 }
 
 // StorageConfig defines storage configuration
@@ -333,6 +570,16 @@ type StorageConfig struct {
 	// +optional
 	ShmSize string `json:"shmSize,omitempty"`
 
+	// ShmSource backs /dev/shm: emptyDir (default, a memory-backed volume
+	// counted against pod memory) or hostPath
+	// +optional
+	ShmSource string `json:"shmSource,omitempty"`
+
+	// ShmHostPath is the host directory mounted at /dev/shm with the
+	// hostPath source; it must lie under an operator-allowed prefix
+	// +optional
+	ShmHostPath string `json:"shmHostPath,omitempty"`
+
 	// ModelCache defines model cache PVC configuration
 	// +optional
 	ModelCache ModelCache `json:"modelCache,omitempty"`
@@ -359,13 +606,40 @@ type SchedulingConfig struct {
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 
-	// PodAntiAffinity defines pod anti-affinity policy
+	// PodAntiAffinity spreads replicas across nodes: required (hard),
+	// preferred (soft, the default) or none
 	// +optional
 	PodAntiAffinity string `json:"podAntiAffinity,omitempty"`
 
-	// TopologySpreadConstraints defines topology spread constraints
+	// TopologySpreadConstraints are added to the pod template, e.g. to
+	// spread replicas across zones. A constraint without a labelSelector
+	// selects this cluster's pods.
 	// +optional
-	TopologySpreadConstraints []interface{} `json:"topologySpreadConstraints,omitempty"`
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// SchedulerName sets the pods' spec.schedulerName. Unset, multi-GPU or
+	// multi-replica clusters go to the operator's --gpu-scheduler-name (if
+	// configured) and the rest to the default scheduler.
+	// +optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+}
+
+// RolloutConfig maps to the StatefulSet update strategy
+type RolloutConfig struct {
+	// Strategy is RollingUpdate (default) or OnDelete
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+
+	// Partition only updates pods with an ordinal >= Partition, leaving the
+	// rest on the old revision for canary rollouts (default 0, all pods)
+	// +optional
+	Partition int `json:"partition,omitempty"`
+
+	// MaxUnavailable is how many pods may be down during a rolling update
+	// (default 1; values above 1 need the MaxUnavailableStatefulSet feature
+	// gate)
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
 // HighAvailabilityConfig defines HA settings
@@ -377,6 +651,17 @@ type HighAvailabilityConfig struct {
 	// TerminationGracePeriodSeconds is the grace period for termination
 	// +optional
 	TerminationGracePeriodSeconds int `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// CrashLoopRestartThreshold is the restart count at which a not-ready
+	// inference container sets the CrashLooping condition (default 3)
+	// +optional
+	CrashLoopRestartThreshold int `json:"crashLoopRestartThreshold,omitempty"`
+
+	// ProtectLoadingPods shields pods that are still loading the model (not
+	// yet Ready) from preemption and eviction with a maxUnavailable 0 PDB,
+	// so a long model load isn't thrown away for lower-value work
+	// +optional
+	ProtectLoadingPods bool `json:"protectLoadingPods,omitempty"`
 }
 
 // PDBConfig defines PodDisruptionBudget configuration
@@ -388,6 +673,11 @@ type PDBConfig struct {
 	// MinAvailable is the minimum available pods
 	// +optional
 	MinAvailable int `json:"minAvailable,omitempty"`
+
+	// PreemptionHeadroom is the number of pods the PDB leaves evictable for
+	// scheduler preemption; the generated minAvailable is reduced by it
+	// +optional
+	PreemptionHeadroom int `json:"preemptionHeadroom,omitempty"`
 }
 
 // NetworkConfig defines network configuration
@@ -400,9 +690,68 @@ type NetworkConfig struct {
 	// +optional
 	Port int `json:"port,omitempty"`
 
+	// ContainerPort is the port the engine listens on (default 8000 for
+	// every engine); the container port, Services, probes and router
+	// upstreams all follow it
+	// +optional
+	ContainerPort int `json:"containerPort,omitempty"`
+
 	// NetworkPolicy indicates whether network policy is enabled
 	// +optional
 	NetworkPolicy bool `json:"networkPolicy,omitempty"`
+
+	// Gateway defines a Gateway API HTTPRoute for external access
+	// +optional
+	Gateway GatewayConfig `json:"gateway,omitempty"`
+
+	// TLS configures the inference engine to serve HTTPS
+	// +optional
+	TLS TLSConfig `json:"tls,omitempty"`
+
+	// ProbeScheme is the probe scheme (HTTP, HTTPS); defaults to HTTPS when TLS is enabled
+	// +optional
+	ProbeScheme string `json:"probeScheme,omitempty"`
+}
+
+// TLSConfig defines inference engine TLS
+type TLSConfig struct {
+	// Enabled indicates whether the engine serves HTTPS
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// SecretName is a kubernetes.io/tls Secret with tls.crt and tls.key
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// GatewayConfig defines Gateway API HTTPRoute configuration
+type GatewayConfig struct {
+	// ParentRef is the Gateway the HTTPRoute attaches to
+	// +optional
+	ParentRef GatewayParentRef `json:"parentRef,omitempty"`
+
+	// Hostname is the hostname matched by the HTTPRoute
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// Path is the path prefix matched by the HTTPRoute (default: /)
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// GatewayParentRef identifies a Gateway
+type GatewayParentRef struct {
+	// Name is the Gateway name
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace is the Gateway namespace (defaults to the LLMCluster namespace)
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the Gateway listener name
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
 }
 
 // SecurityConfig defines security settings
@@ -5,6 +5,7 @@ module github.com/example/llmcluster-operator
 go 1.21
 
 require (
+	github.com/prometheus/client_golang v1.16.0
 	k8s.io/api v0.28.3
 	k8s.io/apimachinery v0.28.3
 	k8s.io/client-go v0.28.3
@@ -18,58 +18,460 @@
 // +kubebuilder:rbac:groups=serving.ai,resources=llmclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=serving.ai,resources=llmclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=statefulsets;deployments,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",resources=services;configmaps;events;pods,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services;configmaps;events;pods;persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=podmonitors,verbs=get;list;watch;create;update;patch;delete
 
 package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	// CRD Types - in a real project, these would be in api/v1alpha1/
 	servingv1alpha1 "github.com/example/llmcluster-operator/api/v1alpha1"
 )
 
+const (
+	// defaultInferencePort is the port the inference engine listens on
+	defaultInferencePort = 8000
+
+	// gpuTypeLabel is the node label (in Spec.Scheduling.NodeSelector) naming
+	// the GPU type for cost rates; the GPU operator's product label is the
+	// fallback
+	gpuTypeLabel       = "gpu.type"
+	gpuProductLabel    = "nvidia.com/gpu.product"
+	defaultCostRateKey = "default"
+
+	// defaultCrashLoopRestartThreshold is the restart count at which a
+	// not-ready inference container counts as crash looping
+	defaultCrashLoopRestartThreshold = 3
+
+	// masterPort is the torch.distributed rendezvous port on pod-0
+	masterPort = 5000
+
+	// startupProbePeriodSeconds is the startup probe interval; its failure
+	// threshold is the model load timeout divided by it
+	startupProbePeriodSeconds    = 10
+	defaultStartupTimeoutSeconds = 20 * 60
+
+	// The liveness probe tolerates MaxRequestSeconds of failures by default
+	// so a long generation doesn't get a busy engine restarted
+	defaultLivenessPeriodSeconds = 10
+	defaultMaxRequestSeconds     = 300
+	minLivenessFailureThreshold  = 3
+
+	// Readiness aggregation modes (Spec.Probes.ReadinessAggregation); empty
+	// means per-replica
+	readinessPerReplica = "PerReplica"
+	readinessRank0      = "Rank0"
+
+	// Pod anti-affinity policies (Spec.Scheduling.PodAntiAffinity) for
+	// spreading replicas across nodes; empty means preferred
+	antiAffinityRequired  = "required"
+	antiAffinityPreferred = "preferred"
+	antiAffinityNone      = "none"
+
+	// Inference engines (Spec.InferenceEngine); empty means vLLM
+	engineVLLM   = "vllm"
+	engineTGI    = "tgi"
+	engineSGLang = "sglang"
+
+	// prefixCacheMountPath is where the shared prefix cache PVC is mounted;
+	// prefixCacheConnector offloads vLLM KV blocks to it through LMCache
+	prefixCacheMountPath     = "/var/cache/prefix-cache"
+	prefixCacheConnector     = `{"kv_connector":"LMCacheConnectorV1","kv_role":"kv_both"}`
+	defaultSharedCacheSizeGB = 50
+
+	// gpuResourceName is the extended resource requested per GPU
+	gpuResourceName corev1.ResourceName = "nvidia.com/gpu"
+
+	// Router types (Spec.Router.Type). The operator renders the upstream
+	// config for nginx and envoy; other types bring their own image.
+	routerTypeNginx         = "nginx"
+	routerTypeEnvoy         = "envoy"
+	routerTypePrefillDecode = "prefill-decode"
+	routerTypeCustom        = "custom"
+
+	// Spec.ReconcilePolicy values
+	reconcilePolicyApply  = "apply"
+	reconcilePolicyDryRun = "dryRun"
+
+	// Default router images when Spec.Router.Image is empty
+	defaultRouterImage      = "nginx:alpine"
+	defaultEnvoyRouterImage = "envoyproxy/envoy:v1.29-latest"
+
+	// routerContainerPort is the port the router container listens on
+	// (unprivileged, so the envoy image can bind it as its non-root user)
+	routerContainerPort = 8080
+
+	// Router AutoReplicas defaults
+	defaultBackendsPerRouterReplica = 4
+	defaultMinRouterReplicas        = 2
+
+	// tlsMountPath is where the engine TLS Secret is mounted
+	tlsMountPath = "/etc/llmcluster/tls"
+
+	// configMountPath is where the <name>-config ConfigMap is mounted;
+	// engineArgsKey holds the rendered engine args, one per line
+	configMountPath = "/etc/llmcluster/config"
+	engineArgsKey   = "engine-args"
+
+	// annotationConfigChecksum on the pod template rolls the pods when the
+	// rendered engine config changes
+	annotationConfigChecksum = "serving.ai/config-checksum"
+
+	// annotationRestartedAt is what `kubectl rollout restart` sets on the
+	// pod template; it is carried over so the restart isn't undone
+	annotationRestartedAt = "kubectl.kubernetes.io/restartedAt"
+
+	// prometheusPodLabel selects Prometheus scrapers (any namespace) allowed
+	// through the NetworkPolicy on the metrics port
+	prometheusPodLabel = "app.kubernetes.io/name"
+	prometheusPodValue = "prometheus"
+
+	// annotationAdopt on an LLMCluster allows taking ownership of a
+	// pre-existing, unowned StatefulSet with the same name
+	annotationAdopt = "serving.ai/adopt"
+
+	// cleanupFinalizer blocks LLMCluster deletion until state outside the
+	// owner-reference tree (router backends, model cache) is released
+	cleanupFinalizer = "serving.ai/cleanup"
+
+	// Shared memory and model cache defaults
+	defaultShmSize        = "16Gi"
+	defaultModelCacheSize = "100Gi"
+
+	// Spec.Storage.ShmSource values
+	shmSourceEmptyDir = "emptyDir"
+	shmSourceHostPath = "hostPath"
+
+	// modelCacheMountPath is the HuggingFace cache used for model downloads
+	modelCacheMountPath = "/root/.cache/huggingface"
+
+	// Engine log shipping: the engine output is tee'd to engineLogFile on a
+	// shared emptyDir that the sidecar tails
+	engineLogDir             = "/var/log/llmcluster"
+	engineLogFile            = engineLogDir + "/engine.log"
+	defaultLogShippingImage  = "fluent/fluent-bit:2.2"
+	defaultLogShippingOutput = "stdout"
+
+	// labelModelCache marks model cache PVCs (StatefulSet volumeClaimTemplates
+	// carry their labels) so cleanup can find every replica's claim
+	labelModelCache = "llmcluster.serving.ai/model-cache"
+
+	// labelLoading marks model pods that are not Ready yet; the loading PDB
+	// selects them when HighAvailability.ProtectLoadingPods is set
+	labelLoading = "serving.ai/loading"
+)
+
+// Optional third-party kinds are handled as unstructured so the operator
+// does not depend on the Gateway API or Prometheus Operator modules.
+var (
+	httpRouteGVK = schema.GroupVersionKind{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1",
+		Kind:    "HTTPRoute",
+	}
+	gatewayGVK = schema.GroupVersionKind{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1",
+		Kind:    "Gateway",
+	}
+	podMonitorGVK = schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "PodMonitor",
+	}
+)
+
+// defaultNCCLEnv are collective-comms settings that work on typical cloud
+// networks (no InfiniBand, pod traffic on eth0). Spec.Coordination.NCCL
+// overrides them per key.
+var defaultNCCLEnv = map[string]string{
+	"NCCL_DEBUG":         "WARN",
+	"NCCL_IB_DISABLE":    "1",
+	"NCCL_SOCKET_IFNAME": "eth0",
+}
+
+// ncclEnvKeyPattern restricts Spec.Coordination.NCCL to distributed backend env
+var ncclEnvKeyPattern = regexp.MustCompile(`^(NCCL|GLOO|TORCH_NCCL)_[A-Z0-9_]+$`)
+
+// modelRevisionPattern accepts a commit hash or a git tag/branch name
+var modelRevisionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]{0,127}$`)
+
+// Reconcile metrics, registered with the controller-runtime registry served
+// on the manager's metrics endpoint
+var (
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "llmcluster_reconcile_total",
+		Help: "LLMCluster reconciles by result (success or error).",
+	}, []string{"result"})
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "llmcluster_reconcile_errors_total",
+		Help: "Failed child resource reconciles by resource.",
+	}, []string{"resource"})
+	reconcileDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "llmcluster_reconcile_duration_seconds",
+		Help:    "LLMCluster reconcile duration.",
+		Buckets: prometheus.DefBuckets,
+	})
+	readyReplicasGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "llmcluster_ready_replicas",
+		Help: "Ready model pods per LLMCluster.",
+	}, []string{"namespace", "name"})
+)
+
 // LLMClusterReconciler reconciles a LLMCluster object
 type LLMClusterReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+
+	// CostConfigMap holds GPU-hour rates keyed by GPU type (plus "default")
+	// used for status.estimatedHourlyCost; unset disables cost estimates
+	CostConfigMap client.ObjectKey
+
+	// MinGPUsByModelSize overrides minGPUsByModelSize entries
+	MinGPUsByModelSize map[string]int
+
+	// DrainCordonedNodes evicts inference pods from cordoned nodes so the
+	// StatefulSet recreates them elsewhere
+	DrainCordonedNodes bool
+
+	// GPUSchedulerName is the scheduler for clusters spanning several GPUs
+	// when Spec.Scheduling.SchedulerName is unset; empty keeps them on the
+	// default scheduler
+	GPUSchedulerName string
+
+	// MaxConcurrentReconciles caps LLMClusters reconciled in parallel
+	// (default 1)
+	MaxConcurrentReconciles int
+
+	// BackoffBase and BackoffMax bound the per-LLMCluster exponential
+	// backoff after a failed reconcile; zero keeps the controller-runtime
+	// default rate limiter
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+
+	// ShmHostPathPrefixes are the host directories under which
+	// Spec.Storage.ShmHostPath may lie; empty disallows the hostPath source
+	ShmHostPathPrefixes []string
+
+	// pendingChanges is set on the per-reconcile copy running an
+	// LLMCluster with reconcilePolicy dryRun
+	pendingChanges *pendingChanges
+}
+
+// dryRunClient sends every write with DryRunAll, so the API server validates
+// and defaults it without persisting anything, and logs what would change:
+// creates and deletes by name, updates as a JSON merge patch against the
+// live object, patches as sent. Status writes go through so status shows the planned result.
+// With pending set it also collects a summary of each change.
+type dryRunClient struct {
+	client.Client
+	live    client.Client
+	pending *pendingChanges
+}
+
+// pendingChanges collects dry-run change summaries for status.pendingChanges
+type pendingChanges struct {
+	mu      sync.Mutex
+	changes []string
+}
+
+func (p *pendingChanges) add(change string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.changes = append(p.changes, change)
+}
+
+func (p *pendingChanges) list() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.changes...)
+}
+
+func newDryRunClient(live client.Client) client.Client {
+	return dryRunClient{Client: client.NewDryRunClient(live), live: live}
+}
+
+func (c dryRunClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.logWrite(ctx, "would create", obj)
+	c.record("create", obj, nil)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c dryRunClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	live := obj.DeepCopyObject().(client.Object)
+	if err := c.live.Get(ctx, client.ObjectKeyFromObject(obj), live); err == nil {
+		if diff, err := client.MergeFrom(live).Data(obj); err == nil {
+			c.logWrite(ctx, "would update", obj, "diff", string(diff))
+			c.record("update", obj, diff)
+		}
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// Patch logs and records the patch itself, e.g. the loading label a pod
+// would gain or lose.
+func (c dryRunClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if data, err := patch.Data(obj); err == nil {
+		c.logWrite(ctx, "would patch", obj, "patch", string(data))
+		c.record("patch", obj, data)
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c dryRunClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.logWrite(ctx, "would delete", obj)
+	c.record("delete", obj, nil)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// record adds "<action> <Kind> <name>[: field, ...]" to pending, naming the
+// fields an update's or patch's merge patch changes. Updates that change
+// nothing (the reconcilers update unconditionally) aren't recorded.
+func (c dryRunClient) record(action string, obj client.Object, diff []byte) {
+	if c.pending == nil {
+		return
+	}
+	kind := "unknown"
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+	change := fmt.Sprintf("%s %s %s", action, kind, obj.GetName())
+	if diff != nil {
+		var patch map[string]interface{}
+		if err := json.Unmarshal(diff, &patch); err != nil {
+			return
+		}
+		// Every update carries resourceVersion; it isn't a change
+		if metadata, ok := patch["metadata"].(map[string]interface{}); ok {
+			delete(metadata, "resourceVersion")
+			if len(metadata) == 0 {
+				delete(patch, "metadata")
+			}
+		}
+		fields := patchFields("", patch, 3)
+		if len(fields) == 0 {
+			return
+		}
+		change += ": " + strings.Join(fields, ", ")
+	}
+	c.pending.add(change)
+}
+
+// patchFields returns the dotted paths a merge patch sets, cut off at depth
+// levels so a rewritten pod template reads as spec.template.spec
+func patchFields(prefix string, patch map[string]interface{}, depth int) []string {
+	var fields []string
+	for key, value := range patch {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && depth > 1 && len(nested) > 0 {
+			fields = append(fields, patchFields(path, nested, depth-1)...)
+			continue
+		}
+		fields = append(fields, path)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (c dryRunClient) Status() client.SubResourceWriter {
+	return c.live.Status()
+}
+
+func (c dryRunClient) logWrite(ctx context.Context, action string, obj client.Object, keysAndValues ...interface{}) {
+	kind := "unknown"
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+	keysAndValues = append([]interface{}{"kind", kind, "name", obj.GetName()}, keysAndValues...)
+	ctrl.LoggerFrom(ctx).Info("dry-run: "+action, keysAndValues...)
 }
 
 // RBAC markers (for controller-gen)
 // +kubebuilder:rbac:groups=serving.ai,resources=llmclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=serving.ai,resources=llmclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=serving.ai,resources=llmclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 
-// Reconcile is the main reconciliation loop
+// Reconcile runs reconcile and records its metrics
 func (r *LLMClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	result, err := r.reconcile(ctx, req)
+	reconcileDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		reconcileTotal.WithLabelValues("error").Inc()
+	} else {
+		reconcileTotal.WithLabelValues("success").Inc()
+	}
+	return result, err
+}
+
+// reconcile is the main reconciliation loop. Failures return the error
+// with an empty Result: the controller requeues them through its rate
+// limiter, backing off exponentially while the error persists.
+func (r *LLMClusterReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 
 	// ============================================
@@ -82,6 +484,7 @@ func (r *LLMClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		if errors.IsNotFound(err) {
 			// Object deleted, stop reconciling
 			log.Info("LLMCluster deleted, nothing to do")
+			readyReplicasGauge.DeleteLabelValues(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object
@@ -89,10 +492,55 @@ func (r *LLMClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
+	// A dryRun cluster is reconciled by a copy whose writes are dry runs
+	// collected for status.pendingChanges. Deletion cleanup still applies,
+	// so the finalizer is added for real first; the copy re-reads the
+	// cluster and finds it in place.
+	if llmCluster.Spec.ReconcilePolicy == reconcilePolicyDryRun && r.pendingChanges == nil && llmCluster.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&llmCluster, cleanupFinalizer) {
+			controllerutil.AddFinalizer(&llmCluster, cleanupFinalizer)
+			if err := r.Update(ctx, &llmCluster); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		pending := &pendingChanges{}
+		dryRun := *r
+		dryRun.Client = dryRunClient{Client: client.NewDryRunClient(r.Client), live: r.Client, pending: pending}
+		dryRun.pendingChanges = pending
+		return dryRun.reconcile(ctx, req)
+	}
+
+	// Run cleanup on deletion, and add the finalizer on first observe
+	if !llmCluster.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&llmCluster, cleanupFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.finalize(ctx, &llmCluster); err != nil {
+			log.Error(err, "LLMCluster cleanup failed")
+			return ctrl.Result{}, err
+		}
+		controllerutil.RemoveFinalizer(&llmCluster, cleanupFinalizer)
+		if err := r.Update(ctx, &llmCluster); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("LLMCluster cleanup complete")
+		return ctrl.Result{}, nil
+	}
+	if !controllerutil.ContainsFinalizer(&llmCluster, cleanupFinalizer) {
+		controllerutil.AddFinalizer(&llmCluster, cleanupFinalizer)
+		if err := r.Update(ctx, &llmCluster); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	// ============================================
 	// 2. Validate the spec
 	// ============================================
-	if err := r.validateSpec(&llmCluster); err != nil {
+	err := r.validateSpec(&llmCluster)
+	if err == nil {
+		err = r.validatePrefixCacheClaim(ctx, &llmCluster)
+	}
+	if err != nil {
 		log.Error(err, "LLMCluster spec validation failed")
 		r.Recorder.Event(&llmCluster, corev1.EventTypeWarning, "ValidationFailed", err.Error())
 		return ctrl.Result{}, err
@@ -101,8 +549,10 @@ func (r *LLMClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// ============================================
 	// 3. Update status to "Creating"
 	// ============================================
-	if llmCluster.Status.Phase != "Creating" && llmCluster.Status.Phase != "Running" {
-		llmCluster.Status.Phase = "Creating"
+	// Only a new cluster starts in Creating; later phases are recomputed in
+	// step 5 so a requeue does not bounce them back through Creating
+	if llmCluster.Status.Phase == "" {
+		r.setPhase(&llmCluster, "Creating")
 		if err := r.Status().Update(ctx, &llmCluster); err != nil {
 			log.Error(err, "unable to update LLMCluster status")
 			return ctrl.Result{}, err
@@ -117,14 +567,28 @@ func (r *LLMClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	statefulSet, err := r.reconcileStatefulSet(ctx, &llmCluster)
 	if err != nil {
 		log.Error(err, "unable to reconcile StatefulSet")
-		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		r.markDegraded(ctx, &llmCluster, "StatefulSet", err)
+		return ctrl.Result{}, err
+	}
+
+	// Move pods off nodes cordoned for maintenance
+	if r.DrainCordonedNodes {
+		if err := r.drainCordonedNodes(ctx, &llmCluster); err != nil {
+			log.Error(err, "unable to drain pods from cordoned nodes")
+		}
+	}
+
+	// Surface pods the anti-affinity policy keeps Pending or co-locates
+	if err := r.checkAntiAffinity(ctx, &llmCluster); err != nil {
+		log.Error(err, "unable to check pod anti-affinity")
 	}
 
 	// 4b. Reconcile Router Deployment
 	if llmCluster.Spec.Router.Enabled {
 		if err := r.reconcileRouterDeployment(ctx, &llmCluster); err != nil {
 			log.Error(err, "unable to reconcile Router Deployment")
-			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			r.markDegraded(ctx, &llmCluster, "Router Deployment", err)
+			return ctrl.Result{}, err
 		}
 	}
 
@@ -132,43 +596,90 @@ func (r *LLMClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if llmCluster.Spec.Queue.Enabled {
 		if err := r.reconcileQueueDeployment(ctx, &llmCluster); err != nil {
 			log.Error(err, "unable to reconcile Queue Deployment")
-			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			r.markDegraded(ctx, &llmCluster, "Queue Deployment", err)
+			return ctrl.Result{}, err
 		}
 	}
 
 	// 4d. Reconcile Services
 	if err := r.reconcileServices(ctx, &llmCluster); err != nil {
 		log.Error(err, "unable to reconcile Services")
-		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		r.markDegraded(ctx, &llmCluster, "Services", err)
+		return ctrl.Result{}, err
 	}
 
 	// 4e. Reconcile ConfigMaps
 	if err := r.reconcileConfigMaps(ctx, &llmCluster); err != nil {
 		log.Error(err, "unable to reconcile ConfigMaps")
-		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		r.markDegraded(ctx, &llmCluster, "ConfigMaps", err)
+		return ctrl.Result{}, err
 	}
 
-	// 4f. Reconcile HPA (if autoscaling enabled)
+	// 4f. Reconcile HPA (if autoscaling enabled, else remove it)
 	if llmCluster.Spec.Autoscaling.Enabled {
 		if err := r.reconcileHPA(ctx, &llmCluster); err != nil {
 			log.Error(err, "unable to reconcile HPA")
-			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			r.markDegraded(ctx, &llmCluster, "HPA", err)
+			return ctrl.Result{}, err
 		}
+	} else if err := r.deleteOwned(ctx, &llmCluster, &autoscalingv2.HorizontalPodAutoscaler{}, fmt.Sprintf("%s-hpa", llmCluster.Name), "HPA"); err != nil {
+		log.Error(err, "unable to delete HPA")
+		r.markDegraded(ctx, &llmCluster, "HPA", err)
+		return ctrl.Result{}, err
 	}
 
-	// 4g. Reconcile PDB (if HA enabled)
+	// 4g. Reconcile PDB (if HA enabled, else remove it)
 	if llmCluster.Spec.HighAvailability.PodDisruptionBudget.Enabled {
 		if err := r.reconcilePDB(ctx, &llmCluster); err != nil {
 			log.Error(err, "unable to reconcile PDB")
-			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			r.markDegraded(ctx, &llmCluster, "PDB", err)
+			return ctrl.Result{}, err
+		}
+	} else if err := r.deleteOwned(ctx, &llmCluster, &policyv1.PodDisruptionBudget{}, fmt.Sprintf("%s-pdb", llmCluster.Name), "PDB"); err != nil {
+		log.Error(err, "unable to delete PDB")
+		r.markDegraded(ctx, &llmCluster, "PDB", err)
+		return ctrl.Result{}, err
+	}
+	if llmCluster.Spec.HighAvailability.ProtectLoadingPods {
+		if err := r.reconcileLoadingProtection(ctx, &llmCluster); err != nil {
+			log.Error(err, "unable to protect loading pods")
+			r.markDegraded(ctx, &llmCluster, "Loading PDB", err)
+			return ctrl.Result{}, err
 		}
+	} else if err := r.deleteOwned(ctx, &llmCluster, &policyv1.PodDisruptionBudget{}, fmt.Sprintf("%s-loading-pdb", llmCluster.Name), "loading PDB"); err != nil {
+		log.Error(err, "unable to delete loading PDB")
+		r.markDegraded(ctx, &llmCluster, "Loading PDB", err)
+		return ctrl.Result{}, err
 	}
 
-	// 4h. Reconcile NetworkPolicy (if enabled)
+	// 4h. Reconcile NetworkPolicy (if enabled, else remove it)
 	if llmCluster.Spec.Network.NetworkPolicy {
 		if err := r.reconcileNetworkPolicy(ctx, &llmCluster); err != nil {
 			log.Error(err, "unable to reconcile NetworkPolicy")
-			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			r.markDegraded(ctx, &llmCluster, "NetworkPolicy", err)
+			return ctrl.Result{}, err
+		}
+	} else if err := r.deleteOwned(ctx, &llmCluster, &networkingv1.NetworkPolicy{}, llmCluster.Name, "NetworkPolicy"); err != nil {
+		log.Error(err, "unable to delete NetworkPolicy")
+		r.markDegraded(ctx, &llmCluster, "NetworkPolicy", err)
+		return ctrl.Result{}, err
+	}
+
+	// 4i. Reconcile PodMonitor (if Prometheus scraping enabled)
+	if llmCluster.Spec.Monitoring.Enabled && llmCluster.Spec.Monitoring.Prometheus {
+		if err := r.reconcilePodMonitor(ctx, &llmCluster); err != nil {
+			log.Error(err, "unable to reconcile PodMonitor")
+			r.markDegraded(ctx, &llmCluster, "PodMonitor", err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	// 4j. Reconcile HTTPRoute (if a Gateway is configured)
+	if llmCluster.Spec.Network.Gateway.ParentRef.Name != "" {
+		if err := r.reconcileHTTPRoute(ctx, &llmCluster); err != nil {
+			log.Error(err, "unable to reconcile HTTPRoute")
+			r.markDegraded(ctx, &llmCluster, "HTTPRoute", err)
+			return ctrl.Result{}, err
 		}
 	}
 
@@ -176,35 +687,98 @@ func (r *LLMClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// 5. Update status
 	// ============================================
 	readyReplicas := statefulSet.Status.ReadyReplicas
-	llmCluster.Status.Replicas = int32(llmCluster.Spec.Replicas)
+	replicas := int32(llmCluster.Spec.Replicas)
+	if llmCluster.Spec.Autoscaling.Enabled && statefulSet.Spec.Replicas != nil {
+		// The HPA owns the replica count, down to zero when minReplicas is 0
+		replicas = *statefulSet.Spec.Replicas
+	}
+	r.recordScaleEvents(&llmCluster, replicas, readyReplicas)
+	llmCluster.Status.Replicas = replicas
 	llmCluster.Status.ReadyReplicas = readyReplicas
+	readyReplicasGauge.WithLabelValues(llmCluster.Namespace, llmCluster.Name).Set(float64(readyReplicas))
 	llmCluster.Status.ObservedGeneration = llmCluster.Generation
-	llmCluster.Status.Metrics.TotalGPUs = llmCluster.Spec.Replicas * llmCluster.Spec.GPUsPerPod
+	llmCluster.Status.ModelRevision = llmCluster.Spec.ModelRevision
+	llmCluster.Status.EffectiveImage = llmCluster.Spec.Image
+	llmCluster.Status.EffectiveArgs = effectiveArgs(&llmCluster)
+	llmCluster.Status.PendingChanges = nil
+	if r.pendingChanges != nil {
+		llmCluster.Status.PendingChanges = r.pendingChanges.list()
+	}
+	llmCluster.Status.Metrics.TotalGPUs = int(replicas) * llmCluster.Spec.GPUsPerPod
+	if cost, err := r.estimateHourlyCost(ctx, &llmCluster, llmCluster.Status.Metrics.TotalGPUs); err != nil {
+		log.Error(err, "unable to estimate cost")
+	} else {
+		llmCluster.Status.EstimatedHourlyCost = cost
+	}
 
 	// Determine phase
-	if readyReplicas == int32(llmCluster.Spec.Replicas) {
-		llmCluster.Status.Phase = "Running"
-		llmCluster.Status.Conditions = []servingv1alpha1.Condition{
-			{
-				Type:               "Ready",
-				Status:             "True",
-				Reason:             "AllPodsReady",
-				Message:            fmt.Sprintf("All %d replicas are ready", readyReplicas),
-				LastTransitionTime: metav1.Now(),
-			},
-		}
+	ready, reason, message, err := r.clusterReady(ctx, &llmCluster, replicas, readyReplicas)
+	if err != nil {
+		log.Error(err, "unable to list pods for readiness")
+	}
+	phase := "Progressing"
+	if replicas == 0 {
+		phase = "ScaledToZero"
+	}
+	if ready {
+		phase = "Running"
+		setCondition(&llmCluster.Status.Conditions, servingv1alpha1.Condition{
+			Type:    "Ready",
+			Status:  "True",
+			Reason:  reason,
+			Message: message,
+		})
 	} else {
-		llmCluster.Status.Phase = "Progressing"
-		llmCluster.Status.Conditions = []servingv1alpha1.Condition{
-			{
-				Type:               "Ready",
-				Status:             "False",
-				Reason:             "PodsNotReady",
-				Message:            fmt.Sprintf("%d/%d pods ready", readyReplicas, llmCluster.Spec.Replicas),
-				LastTransitionTime: metav1.Now(),
-			},
+		setCondition(&llmCluster.Status.Conditions, servingv1alpha1.Condition{
+			Type:    "Ready",
+			Status:  "False",
+			Reason:  reason,
+			Message: message,
+		})
+	}
+
+	// A crash-looping engine is failing, not slow to load
+	crashLooping := servingv1alpha1.Condition{
+		Type:   "CrashLooping",
+		Status: "False",
+		Reason: "NoCrashLoop",
+	}
+	if message, err := r.detectCrashLoop(ctx, &llmCluster); err != nil {
+		log.Error(err, "unable to inspect pod container statuses")
+	} else if message != "" {
+		phase = "Failed"
+		crashLooping.Status = "True"
+		crashLooping.Reason = "ContainerCrashLooping"
+		crashLooping.Message = message
+	}
+	setCondition(&llmCluster.Status.Conditions, crashLooping)
+	r.setPhase(&llmCluster, phase)
+
+	// Pods Pending on GPU capacity wait for the cluster-autoscaler
+	waitingForNodes := servingv1alpha1.Condition{
+		Type:   "WaitingForNodes",
+		Status: "False",
+		Reason: "NoPodsPendingOnGPUs",
+	}
+	if pending, nodes, err := r.gpuCapacityShortfall(ctx, &llmCluster); err != nil {
+		log.Error(err, "unable to check pending pods")
+	} else if pending > 0 {
+		waitingForNodes.Status = "True"
+		waitingForNodes.Reason = "InsufficientGPUs"
+		waitingForNodes.Message = fmt.Sprintf("%d pods pending on insufficient %s; about %d more GPU nodes needed",
+			pending, gpuResourceName, nodes)
+		if !conditionTrue(llmCluster.Status.Conditions, "WaitingForNodes") {
+			r.Recorder.Event(&llmCluster, corev1.EventTypeWarning, "WaitingForNodes", waitingForNodes.Message)
 		}
 	}
+	setCondition(&llmCluster.Status.Conditions, waitingForNodes)
+
+	// Every child resource reconciled, so clear any earlier failure
+	setCondition(&llmCluster.Status.Conditions, servingv1alpha1.Condition{
+		Type:   "Degraded",
+		Status: "False",
+		Reason: "ReconcileSucceeded",
+	})
 
 	if err := r.Status().Update(ctx, &llmCluster); err != nil {
 		log.Error(err, "unable to update LLMCluster status")
@@ -214,16 +788,69 @@ func (r *LLMClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// ============================================
 	// 6. Requeue for next reconciliation
 	// ============================================
-	// Requeue more frequently if not ready
-	if readyReplicas < int32(llmCluster.Spec.Replicas) {
+	// Requeue more frequently if not ready (an idle cluster has nothing to wait for)
+	if !ready && replicas > 0 {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
 	}
 
 	return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
 }
 
+// minGPUsByModelSize is the fewest 80GB GPUs that hold each ModelSize's fp16
+// weights plus KV cache headroom, rounded up to a valid tensor-parallel size.
+// LLMClusterReconciler.MinGPUsByModelSize overrides entries.
+var minGPUsByModelSize = map[string]int{
+	"8B":   1,
+	"13B":  1,
+	"70B":  2,
+	"405B": 16,
+}
+
+// parseMinGPUsByModelSize parses --model-size-min-gpus, a comma-separated
+// list of size=gpus entries
+func parseMinGPUsByModelSize(value string) (map[string]int, error) {
+	minGPUs := map[string]int{}
+	for _, entry := range strings.Split(value, ",") {
+		size, count, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		gpus, err := strconv.Atoi(count)
+		if !ok || err != nil || gpus <= 0 {
+			return nil, fmt.Errorf("invalid --model-size-min-gpus entry %q", entry)
+		}
+		minGPUs[size] = gpus
+	}
+	return minGPUs, nil
+}
+
+// attentionHeadsByModelSize is the attention head count of the Llama model
+// at each ModelSize; InferenceArgs.NumAttentionHeads overrides it
+var attentionHeadsByModelSize = map[string]int{
+	"8B":   32,
+	"13B":  40,
+	"70B":  64,
+	"405B": 128,
+}
+
 // validateSpec validates the LLMCluster spec
 func (r *LLMClusterReconciler) validateSpec(llmCluster *servingv1alpha1.LLMCluster) error {
+	if llmCluster.Spec.Replicas <= 0 {
+		return fmt.Errorf("replicas must be > 0, got %d", llmCluster.Spec.Replicas)
+	}
+	if llmCluster.Spec.GPUsPerPod <= 0 {
+		return fmt.Errorf("gpusPerPod must be > 0, got %d", llmCluster.Spec.GPUsPerPod)
+	}
+
+	// Validate total GPUs against the model size
+	if size := llmCluster.Spec.ModelSize; size != "" {
+		minGPUs, ok := r.MinGPUsByModelSize[size]
+		if !ok {
+			minGPUs = minGPUsByModelSize[size]
+		}
+		if total := llmCluster.Spec.Replicas * llmCluster.Spec.GPUsPerPod; total < minGPUs {
+			return fmt.Errorf("modelSize %s needs at least %d GPUs (80GB, fp16), got replicas × gpusPerPod = %d × %d = %d",
+				size, minGPUs, llmCluster.Spec.Replicas, llmCluster.Spec.GPUsPerPod, total)
+		}
+	}
+
 	// Validate tensor parallel size
 	expectedTPSize := llmCluster.Spec.Replicas * llmCluster.Spec.GPUsPerPod
 	if llmCluster.Spec.TensorParallelSize != 0 && llmCluster.Spec.TensorParallelSize != expectedTPSize {
@@ -231,112 +858,526 @@ func (r *LLMClusterReconciler) validateSpec(llmCluster *servingv1alpha1.LLMClust
 			expectedTPSize, llmCluster.Spec.TensorParallelSize)
 	}
 
-	return nil
-}
+	// Engines shard attention heads evenly across tensor-parallel ranks
+	heads := llmCluster.Spec.InferenceArgs.NumAttentionHeads
+	if heads == 0 {
+		heads = attentionHeadsByModelSize[llmCluster.Spec.ModelSize]
+	}
+	if heads > 0 && heads%expectedTPSize != 0 {
+		return fmt.Errorf("tensor-parallel size %d (replicas × gpusPerPod) must divide the model's %d attention heads; set inferenceArgs.numAttentionHeads if the default for modelSize %q is wrong",
+			expectedTPSize, heads, llmCluster.Spec.ModelSize)
+	}
 
-// reconcileStatefulSet creates or updates the StatefulSet for model pods
-func (r *LLMClusterReconciler) reconcileStatefulSet(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) (*appsv1.StatefulSet, error) {
-	log := ctrl.LoggerFrom(ctx)
+	// Validate model revision
+	if revision := llmCluster.Spec.ModelRevision; revision != "" &&
+		(!modelRevisionPattern.MatchString(revision) || strings.Contains(revision, "..") || strings.HasSuffix(revision, "/")) {
+		return fmt.Errorf("modelRevision must be a commit hash, tag or branch name, got %q", revision)
+	}
 
-	// Define the StatefulSet
-	desiredStatefulSet := &appsv1.StatefulSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      llmCluster.Name,
-			Namespace: llmCluster.Namespace,
-			Labels: map[string]string{
-				"app":                        llmCluster.Name,
-				"llmcluster.serving.ai/owned": "true",
-			},
-		},
-		Spec: appsv1.StatefulSetSpec{
-			ServiceName:         fmt.Sprintf("%s-backend", llmCluster.Name),
-			Replicas:            func() *int32 { i := int32(llmCluster.Spec.Replicas); return &i }(),
-			PodManagementPolicy: appsv1.PodManagementPolicyType(llmCluster.Spec.Coordination.PodManagementPolicy),
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app": llmCluster.Name,
-				},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": llmCluster.Name,
-					},
-				},
-				Spec: corev1.PodSpec{
-					Affinity: &corev1.Affinity{
-						PodAntiAffinity: &corev1.PodAntiAffinity{
-							RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
-								{
-									LabelSelector: &metav1.LabelSelector{
-										MatchLabels: map[string]string{"app": llmCluster.Name},
-									},
-									TopologyKey: "kubernetes.io/hostname",
-								},
-							},
-						},
-					},
-					Containers: []corev1.Container{
-						{
-							Name:    "inference",
-							Image:   llmCluster.Spec.Image,
-							Command: []string{"python", "-m", "vllm.entrypoints.openai.api_server"},
-							Args: []string{
-								fmt.Sprintf("--model=%s", llmCluster.Spec.Model),
-								fmt.Sprintf("--tensor-parallel-size=%d", llmCluster.Spec.TensorParallelSize),
-								"--host=0.0.0.0",
-								"--port=8000",
-							},
-							Env: []corev1.EnvVar{
-								{
-									Name: "POD_NAME",
-									ValueFrom: &corev1.EnvVarSource{
-										FieldRef: &corev1.ObjectFieldSelector{
-											FieldPath: "metadata.name",
-										},
-									},
-								},
-								{
-									Name:  "MASTER_ADDR",
-									Value: fmt.Sprintf("%s-0.%s-backend.%s.svc.cluster.local", llmCluster.Name, llmCluster.Name, llmCluster.Namespace),
-								},
-								{
-									Name:  "MASTER_PORT",
-									Value: "5000",
-								},
-							},
-							Ports: []corev1.ContainerPort{
-								{Name: "http", ContainerPort: 8000},
-							},
-							Resources: corev1.ResourceRequirements{
-								Requests: corev1.ResourceList{
-									corev1.ResourceName("nvidia.com/gpu"): *resource.NewQuantity(int64(llmCluster.Spec.GPUsPerPod), resource.DecimalSI),
-								},
-							},
-							VolumeMounts: []corev1.VolumeMount{
-								{Name: "shm", MountPath: "/dev/shm"},
-							},
-						},
-					},
-					Volumes: []corev1.Volume{
-						{
-							Name: "shm",
-							VolumeSource: corev1.VolumeSource{
-								EmptyDir: &corev1.EmptyDirVolumeSource{
-									Medium:    corev1.StorageMediumMemory,
-									SizeLimit: resource.NewQuantity(16*1024*1024*1024, resource.BinarySI), // 16Gi
-								},
-							},
-						},
-					},
-				},
-			},
-		},
+	// Validate inference engine
+	switch llmCluster.Spec.InferenceEngine {
+	case "", engineVLLM, engineSGLang:
+	case engineTGI:
+		if llmCluster.Spec.Network.TLS.Enabled {
+			return fmt.Errorf("network.tls is not supported by inferenceEngine %q", engineTGI)
+		}
+	default:
+		return fmt.Errorf("inferenceEngine must be one of vllm, tgi, sglang, got %q", llmCluster.Spec.InferenceEngine)
 	}
 
-	// Apply node selector if specified
-	if llmCluster.Spec.Scheduling.NodeSelector != nil {
-		desiredStatefulSet.Spec.Template.Spec.NodeSelector = llmCluster.Spec.Scheduling.NodeSelector
+	// Validate prefix caching
+	if cfg := llmCluster.Spec.InferenceArgs.PrefixCaching; cfg.SharedClaimName != "" {
+		if !cfg.Enabled {
+			return fmt.Errorf("inferenceArgs.prefixCaching.sharedClaimName requires prefixCaching.enabled")
+		}
+		if engine := llmCluster.Spec.InferenceEngine; engine != "" && engine != engineVLLM {
+			return fmt.Errorf("inferenceArgs.prefixCaching.sharedClaimName is only supported by vllm, got %q", engine)
+		}
+	}
+
+	// Validate pod anti-affinity policy
+	switch antiAffinityPolicy(llmCluster) {
+	case antiAffinityRequired, antiAffinityPreferred, antiAffinityNone:
+	default:
+		return fmt.Errorf("scheduling.podAntiAffinity must be required, preferred or none, got %q",
+			llmCluster.Spec.Scheduling.PodAntiAffinity)
+	}
+
+	// Validate rollout strategy
+	rollout := llmCluster.Spec.Rollout
+	switch appsv1.StatefulSetUpdateStrategyType(rollout.Strategy) {
+	case "", appsv1.RollingUpdateStatefulSetStrategyType:
+		if rollout.Partition < 0 {
+			return fmt.Errorf("rollout.partition must be >= 0, got %d", rollout.Partition)
+		}
+		if rollout.MaxUnavailable != nil {
+			if _, err := intstr.GetScaledValueFromIntOrPercent(rollout.MaxUnavailable, llmCluster.Spec.Replicas, true); err != nil {
+				return fmt.Errorf("rollout.maxUnavailable: %w", err)
+			}
+		}
+	case appsv1.OnDeleteStatefulSetStrategyType:
+		if rollout.Partition != 0 || rollout.MaxUnavailable != nil {
+			return fmt.Errorf("rollout.partition and rollout.maxUnavailable require strategy %s",
+				appsv1.RollingUpdateStatefulSetStrategyType)
+		}
+	default:
+		return fmt.Errorf("rollout.strategy must be RollingUpdate or OnDelete, got %q", rollout.Strategy)
+	}
+
+	// Validate autoscaling: scale-to-zero needs an External activation metric
+	if autoscaling := llmCluster.Spec.Autoscaling; autoscaling.Enabled {
+		if autoscaling.MinReplicas < 0 || autoscaling.MaxReplicas < 1 || autoscaling.MinReplicas > autoscaling.MaxReplicas {
+			return fmt.Errorf("autoscaling requires 0 <= minReplicas <= maxReplicas and maxReplicas >= 1, got %d/%d",
+				autoscaling.MinReplicas, autoscaling.MaxReplicas)
+		}
+		if autoscaling.MinReplicas == 0 &&
+			(autoscaling.CustomMetric.Name == "" || autoscaling.CustomMetric.Type != string(autoscalingv2.ExternalMetricSourceType)) {
+			return fmt.Errorf("autoscaling.minReplicas 0 requires an External customMetric to scale up from zero")
+		}
+		if llmCluster.Spec.Replicas < autoscaling.MinReplicas || llmCluster.Spec.Replicas > autoscaling.MaxReplicas {
+			return fmt.Errorf("replicas %d is outside autoscaling bounds [%d, %d]; the HPA would resize it immediately",
+				llmCluster.Spec.Replicas, autoscaling.MinReplicas, autoscaling.MaxReplicas)
+		}
+	}
+
+	// Validate the PDB: a minAvailable above the replica count can never be
+	// satisfied and would block every voluntary eviction, node drains included
+	if pdb := llmCluster.Spec.HighAvailability.PodDisruptionBudget; pdb.Enabled {
+		if pdb.MinAvailable < 0 || pdb.PreemptionHeadroom < 0 {
+			return fmt.Errorf("highAvailability.podDisruptionBudget minAvailable and preemptionHeadroom must be >= 0, got %d/%d",
+				pdb.MinAvailable, pdb.PreemptionHeadroom)
+		}
+		if pdb.MinAvailable > llmCluster.Spec.Replicas {
+			return fmt.Errorf("highAvailability.podDisruptionBudget.minAvailable %d exceeds replicas %d",
+				pdb.MinAvailable, llmCluster.Spec.Replicas)
+		}
+		if autoscaling := llmCluster.Spec.Autoscaling; autoscaling.Enabled && pdb.MinAvailable > autoscaling.MinReplicas {
+			return fmt.Errorf("highAvailability.podDisruptionBudget.minAvailable %d exceeds autoscaling.minReplicas %d; once scaled in, no pod could be evicted",
+				pdb.MinAvailable, autoscaling.MinReplicas)
+		}
+	}
+
+	// Validate readiness aggregation
+	switch llmCluster.Spec.Probes.ReadinessAggregation {
+	case "", readinessPerReplica, readinessRank0:
+	default:
+		return fmt.Errorf("probes.readinessAggregation must be %s or %s, got %q",
+			readinessPerReplica, readinessRank0, llmCluster.Spec.Probes.ReadinessAggregation)
+	}
+
+	switch llmCluster.Spec.ReconcilePolicy {
+	case "", reconcilePolicyApply, reconcilePolicyDryRun:
+	default:
+		return fmt.Errorf("reconcilePolicy must be %s or %s, got %q", reconcilePolicyApply, reconcilePolicyDryRun, llmCluster.Spec.ReconcilePolicy)
+	}
+
+	// Validate router type
+	if llmCluster.Spec.Router.Enabled {
+		switch llmCluster.Spec.Router.Type {
+		case "", routerTypeNginx, routerTypeEnvoy:
+		case routerTypePrefillDecode, routerTypeCustom:
+			if llmCluster.Spec.Router.Image == "" {
+				return fmt.Errorf("router.image is required for router type %q", llmCluster.Spec.Router.Type)
+			}
+		default:
+			return fmt.Errorf("router.type must be one of nginx, envoy, prefill-decode, custom, got %q", llmCluster.Spec.Router.Type)
+		}
+		if err := validateRouterWeights(llmCluster.Spec.Router.Weights); err != nil {
+			return fmt.Errorf("router.weights: %v", err)
+		}
+	}
+
+	// Validate probe scheme and TLS
+	switch llmCluster.Spec.Network.ProbeScheme {
+	case "", string(corev1.URISchemeHTTP), string(corev1.URISchemeHTTPS):
+	default:
+		return fmt.Errorf("network.probeScheme must be HTTP or HTTPS, got %q", llmCluster.Spec.Network.ProbeScheme)
+	}
+	if llmCluster.Spec.Network.TLS.Enabled && llmCluster.Spec.Network.TLS.SecretName == "" {
+		return fmt.Errorf("network.tls.secretName is required when TLS is enabled")
+	}
+
+	// The engine port must not collide with the torch.distributed rendezvous
+	if port := inferencePort(&llmCluster.Spec); port < 1 || port > 65535 || port == masterPort {
+		return fmt.Errorf("network.containerPort %d is out of range or collides with the rendezvous port %d", port, masterPort)
+	}
+
+	// Validate the HuggingFace token reference
+	if token := llmCluster.Spec.Security.HuggingfaceToken; token.SecretName != "" && token.SecretKey == "" {
+		return fmt.Errorf("security.huggingfaceToken.secretKey is required when secretName is set")
+	}
+
+	// Validate storage sizes (parsed with MustParse when building pods)
+	if size := llmCluster.Spec.Storage.ShmSize; size != "" {
+		if _, err := resource.ParseQuantity(size); err != nil {
+			return fmt.Errorf("storage.shmSize %q is not a valid quantity: %v", size, err)
+		}
+	}
+	switch source := llmCluster.Spec.Storage.ShmSource; source {
+	case "", shmSourceEmptyDir:
+	case shmSourceHostPath:
+		if err := r.validateShmHostPath(llmCluster.Spec.Storage.ShmHostPath); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("storage.shmSource %q must be %s or %s", source, shmSourceEmptyDir, shmSourceHostPath)
+	}
+	if size := llmCluster.Spec.Storage.ModelCache.Size; size != "" {
+		if _, err := resource.ParseQuantity(size); err != nil {
+			return fmt.Errorf("storage.modelCache.size %q is not a valid quantity: %v", size, err)
+		}
+	}
+
+	// Validate GPU resources against gpusPerPod
+	gpuFields := []struct {
+		name string
+		list corev1.ResourceList
+	}{
+		{"requests", llmCluster.Spec.Resources.Requests},
+		{"limits", llmCluster.Spec.Resources.Limits},
+	}
+	for _, field := range gpuFields {
+		if q, ok := field.list[gpuResourceName]; ok && q.Value() != int64(llmCluster.Spec.GPUsPerPod) {
+			return fmt.Errorf("resources.%s[%s] (%s) conflicts with gpusPerPod (%d); omit it or set them equal",
+				field.name, gpuResourceName, q.String(), llmCluster.Spec.GPUsPerPod)
+		}
+	}
+
+	// Validate distributed backend env keys
+	for key := range llmCluster.Spec.Coordination.NCCL {
+		if !ncclEnvKeyPattern.MatchString(key) {
+			return fmt.Errorf("coordination.nccl key %q must match %s", key, ncclEnvKeyPattern.String())
+		}
+	}
+
+	return nil
+}
+
+// validatePrefixCacheClaim checks that the shared prefix cache PVC exists and
+// can be mounted by every replica, i.e. is ReadWriteMany
+func (r *LLMClusterReconciler) validatePrefixCacheClaim(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	claimName := llmCluster.Spec.InferenceArgs.PrefixCaching.SharedClaimName
+	if claimName == "" {
+		return nil
+	}
+
+	var claim corev1.PersistentVolumeClaim
+	if err := r.Get(ctx, client.ObjectKey{Namespace: llmCluster.Namespace, Name: claimName}, &claim); err != nil {
+		return fmt.Errorf("inferenceArgs.prefixCaching.sharedClaimName %q: %w", claimName, err)
+	}
+	for _, mode := range claim.Spec.AccessModes {
+		if mode == corev1.ReadWriteMany {
+			return nil
+		}
+	}
+	return fmt.Errorf("inferenceArgs.prefixCaching.sharedClaimName %q must be ReadWriteMany to be shared by %d replicas, has %v",
+		claimName, llmCluster.Spec.Replicas, claim.Spec.AccessModes)
+}
+
+// finalize releases what owner-reference GC does not: entries for this
+// instance in other LLMClusters' router backends, and the model cache PVC.
+// Each step tolerates already-removed state so a retried delete succeeds.
+func (r *LLMClusterReconciler) finalize(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	// 1. Detach from routers that list this instance as a backend
+	var clusters servingv1alpha1.LLMClusterList
+	if err := r.List(ctx, &clusters, client.InNamespace(llmCluster.Namespace)); err != nil {
+		return err
+	}
+	for i := range clusters.Items {
+		router := &clusters.Items[i]
+		if router.Name == llmCluster.Name || !router.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		var kept []servingv1alpha1.RouterBackend
+		for _, backend := range router.Spec.Router.Backends {
+			if backend.Service != llmCluster.Name && backend.Service != frontendServiceName(llmCluster) {
+				kept = append(kept, backend)
+			}
+		}
+		if len(kept) == len(router.Spec.Router.Backends) {
+			continue
+		}
+
+		router.Spec.Router.Backends = kept
+		if err := r.Update(ctx, router); err != nil {
+			return fmt.Errorf("detach from router %s: %w", router.Name, err)
+		}
+		log.Info("Removed backend from router", "router", router.Name)
+		r.Recorder.Eventf(router, corev1.EventTypeNormal, "BackendRemoved",
+			"Removed backend %s (LLMCluster deleted)", llmCluster.Name)
+	}
+
+	// 2. Release the model cache PVCs (StatefulSets retain their claims)
+	if llmCluster.Spec.Storage.ModelCache.Enabled {
+		err := r.DeleteAllOf(ctx, &corev1.PersistentVolumeClaim{},
+			client.InNamespace(llmCluster.Namespace),
+			client.MatchingLabels{labelModelCache: llmCluster.Name})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete model cache PVCs: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// updateStrategy returns the StatefulSet update strategy from Spec.Rollout,
+// RollingUpdate with partition 0 by default
+func updateStrategy(llmCluster *servingv1alpha1.LLMCluster) appsv1.StatefulSetUpdateStrategy {
+	rollout := llmCluster.Spec.Rollout
+	if appsv1.StatefulSetUpdateStrategyType(rollout.Strategy) == appsv1.OnDeleteStatefulSetStrategyType {
+		return appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	}
+
+	partition := int32(rollout.Partition)
+	return appsv1.StatefulSetUpdateStrategy{
+		Type: appsv1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{
+			Partition:      &partition,
+			MaxUnavailable: rollout.MaxUnavailable,
+		},
+	}
+}
+
+// modelCacheClaimTemplate returns the model cache volumeClaimTemplate
+func modelCacheClaimTemplate(llmCluster *servingv1alpha1.LLMCluster) corev1.PersistentVolumeClaim {
+	cache := llmCluster.Spec.Storage.ModelCache
+	size := cache.Size
+	if size == "" {
+		size = defaultModelCacheSize
+	}
+
+	claim := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "model-cache",
+			Labels: map[string]string{
+				"app":           llmCluster.Name,
+				labelModelCache: llmCluster.Name,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(size),
+				},
+			},
+		},
+	}
+	if cache.StorageClass != "" {
+		claim.Spec.StorageClassName = &cache.StorageClass
+	}
+	return claim
+}
+
+// validateShmHostPath checks a hostPath /dev/shm against the operator's
+// allowlist. The path must be clean and absolute so ".." can't climb out of
+// an allowed prefix.
+func (r *LLMClusterReconciler) validateShmHostPath(hostPath string) error {
+	if hostPath == "" {
+		return fmt.Errorf("storage.shmHostPath is required with shmSource %s", shmSourceHostPath)
+	}
+	if !path.IsAbs(hostPath) || path.Clean(hostPath) != hostPath {
+		return fmt.Errorf("storage.shmHostPath %q must be a clean absolute path", hostPath)
+	}
+	for _, prefix := range r.ShmHostPathPrefixes {
+		if hostPath == prefix || strings.HasPrefix(hostPath, strings.TrimSuffix(prefix, "/")+"/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("storage.shmHostPath %q is not under an allowed prefix %v", hostPath, r.ShmHostPathPrefixes)
+}
+
+// shmVolumeSource returns the /dev/shm volume: a memory emptyDir limited to
+// shmSize, or the validated host directory
+func shmVolumeSource(llmCluster *servingv1alpha1.LLMCluster) corev1.VolumeSource {
+	if llmCluster.Spec.Storage.ShmSource == shmSourceHostPath {
+		hostPathType := corev1.HostPathDirectoryOrCreate
+		return corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: llmCluster.Spec.Storage.ShmHostPath,
+				Type: &hostPathType,
+			},
+		}
+	}
+	size := shmSize(llmCluster)
+	return corev1.VolumeSource{
+		EmptyDir: &corev1.EmptyDirVolumeSource{
+			Medium:    corev1.StorageMediumMemory,
+			SizeLimit: &size,
+		},
+	}
+}
+
+// shmSize returns the /dev/shm size limit from Spec.Storage.ShmSize
+func shmSize(llmCluster *servingv1alpha1.LLMCluster) resource.Quantity {
+	size := llmCluster.Spec.Storage.ShmSize
+	if size == "" {
+		size = defaultShmSize
+	}
+	return resource.MustParse(size)
+}
+
+// reconcileStatefulSet creates or updates the StatefulSet for model pods
+func (r *LLMClusterReconciler) reconcileStatefulSet(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) (*appsv1.StatefulSet, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Define the StatefulSet
+	desiredStatefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      llmCluster.Name,
+			Namespace: llmCluster.Namespace,
+			Labels: map[string]string{
+				"app":                        llmCluster.Name,
+				"llmcluster.serving.ai/owned": "true",
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			ServiceName:         fmt.Sprintf("%s-backend", llmCluster.Name),
+			Replicas:            func() *int32 { i := int32(llmCluster.Spec.Replicas); return &i }(),
+			PodManagementPolicy: appsv1.PodManagementPolicyType(llmCluster.Spec.Coordination.PodManagementPolicy),
+			UpdateStrategy:      updateStrategy(llmCluster),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": llmCluster.Name,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": llmCluster.Name,
+					},
+					Annotations: map[string]string{
+						annotationConfigChecksum: configChecksum(renderEngineArgs(llmCluster)),
+					},
+				},
+				Spec: corev1.PodSpec{
+					Affinity: podAntiAffinity(llmCluster),
+					Containers: []corev1.Container{
+						{
+							Name:  "inference",
+							Image: llmCluster.Spec.Image,
+							// Engine args come from the <name>-config ConfigMap;
+							// the remaining args are appended via "$@"
+							Command: []string{"sh", "-c", engineScript(llmCluster), "--"},
+							// Surface the tail of the log (e.g. a failed model
+							// load) in the pod's termination message
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+							Args:                     tlsArgs(llmCluster),
+							Env: append([]corev1.EnvVar{
+								{
+									Name: "POD_NAME",
+									ValueFrom: &corev1.EnvVarSource{
+										FieldRef: &corev1.ObjectFieldSelector{
+											FieldPath: "metadata.name",
+										},
+									},
+								},
+							}, append(append(append(coordinationEnv(llmCluster), ncclEnv(llmCluster)...), hfTokenEnv(llmCluster)...), prefixCacheEnv(llmCluster)...)...),
+							Ports: containerPorts(llmCluster),
+							// Readiness/liveness only start once the model has
+							// loaded, which the startup probe allows time for
+							StartupProbe:   healthProbe(llmCluster, startupProbePeriodSeconds, startupFailureThreshold(llmCluster)),
+							ReadinessProbe: healthProbe(llmCluster, readinessPeriodSeconds(llmCluster), 3),
+							LivenessProbe:  livenessProbe(llmCluster),
+							Resources:      inferenceResources(llmCluster),
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "shm", MountPath: "/dev/shm"},
+								{Name: "config", MountPath: configMountPath, ReadOnly: true},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name:         "shm",
+							VolumeSource: shmVolumeSource(llmCluster),
+						},
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: configMapName(llmCluster)},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Mount the engine TLS certificate
+	if llmCluster.Spec.Network.TLS.Enabled {
+		podSpec := &desiredStatefulSet.Spec.Template.Spec
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: llmCluster.Spec.Network.TLS.SecretName},
+			},
+		})
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name: "tls", MountPath: tlsMountPath, ReadOnly: true,
+		})
+	}
+
+	// Ship engine logs from a sidecar
+	if llmCluster.Spec.Monitoring.LogShipping.Enabled {
+		podSpec := &desiredStatefulSet.Spec.Template.Spec
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name:         "engine-logs",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name: "engine-logs", MountPath: engineLogDir,
+		})
+		podSpec.Containers = append(podSpec.Containers, logShippingContainer(llmCluster))
+	}
+
+	// Run pods under a custom service account if given
+	if sa := llmCluster.Spec.Security.ServiceAccountName; sa != "" {
+		desiredStatefulSet.Spec.Template.Spec.ServiceAccountName = sa
+	}
+
+	// Persist model downloads across pod restarts, one PVC per replica
+	if llmCluster.Spec.Storage.ModelCache.Enabled {
+		desiredStatefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{modelCacheClaimTemplate(llmCluster)}
+		podSpec := &desiredStatefulSet.Spec.Template.Spec
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name: "model-cache", MountPath: modelCacheMountPath,
+		})
+	}
+
+	// Share offloaded prefix KV blocks across replicas through one RWX claim
+	if claim := llmCluster.Spec.InferenceArgs.PrefixCaching.SharedClaimName; llmCluster.Spec.InferenceArgs.PrefixCaching.Enabled && claim != "" {
+		podSpec := &desiredStatefulSet.Spec.Template.Spec
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "prefix-cache",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+			},
+		})
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name: "prefix-cache", MountPath: prefixCacheMountPath,
+		})
+	}
+
+	// Apply node selector if specified
+	if llmCluster.Spec.Scheduling.NodeSelector != nil {
+		desiredStatefulSet.Spec.Template.Spec.NodeSelector = llmCluster.Spec.Scheduling.NodeSelector
+	}
+
+	// Spread replicas across topology domains, alongside the anti-affinity
+	if constraints := topologySpreadConstraints(llmCluster); len(constraints) > 0 {
+		desiredStatefulSet.Spec.Template.Spec.TopologySpreadConstraints = constraints
+	}
+
+	desiredStatefulSet.Spec.Template.Spec.SchedulerName = r.schedulerName(llmCluster)
+
+	// New pods start out protected while they load the model
+	if llmCluster.Spec.HighAvailability.ProtectLoadingPods {
+		desiredStatefulSet.Spec.Template.Labels[labelLoading] = "true"
 	}
 
 	// Set owner reference
@@ -344,127 +1385,2447 @@ func (r *LLMClusterReconciler) reconcileStatefulSet(ctx context.Context, llmClus
 		return nil, err
 	}
 
-	// Create or update
-	var actualStatefulSet appsv1.StatefulSet
-	err := r.Get(ctx, client.ObjectKeyFromObject(desiredStatefulSet), &actualStatefulSet)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			log.Info("Creating StatefulSet", "name", desiredStatefulSet.Name)
-			if err := r.Create(ctx, desiredStatefulSet); err != nil {
-				return nil, err
-			}
-			r.Recorder.Event(llmCluster, corev1.EventTypeNormal, "Created", "Created StatefulSet")
-			return desiredStatefulSet, nil
+	// Create or update
+	var actualStatefulSet appsv1.StatefulSet
+	err := r.Get(ctx, client.ObjectKeyFromObject(desiredStatefulSet), &actualStatefulSet)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Creating StatefulSet", "name", desiredStatefulSet.Name)
+			if err := r.Create(ctx, desiredStatefulSet); err != nil {
+				return nil, err
+			}
+			r.recordWrite(llmCluster, "Created", "create", "StatefulSet")
+			return desiredStatefulSet, nil
+		}
+		return nil, err
+	}
+
+	// Adopt a pre-existing StatefulSet (e.g. hand-rolled before migration)
+	adopted := false
+	if !metav1.IsControlledBy(&actualStatefulSet, llmCluster) {
+		adopted = true
+		if err := r.adoptStatefulSet(llmCluster, desiredStatefulSet, &actualStatefulSet); err != nil {
+			r.Recorder.Event(llmCluster, corev1.EventTypeWarning, "AdoptFailed", err.Error())
+			return nil, err
+		}
+		log.Info("Adopting StatefulSet", "name", actualStatefulSet.Name)
+		r.recordWrite(llmCluster, "Adopted", "adopt", "existing StatefulSet")
+	}
+
+	// volumeClaimTemplates are immutable: keep the existing ones, and refuse
+	// to toggle the model cache on a live StatefulSet since the pod template
+	// and claims would disagree
+	if len(actualStatefulSet.Spec.VolumeClaimTemplates) != len(desiredStatefulSet.Spec.VolumeClaimTemplates) {
+		err := fmt.Errorf("storage.modelCache.enabled cannot change on an existing StatefulSet; delete StatefulSet %s (pods restart) to apply it", actualStatefulSet.Name)
+		r.Recorder.Event(llmCluster, corev1.EventTypeWarning, "ImmutableField", err.Error())
+		return nil, err
+	}
+	desiredStatefulSet.Spec.VolumeClaimTemplates = actualStatefulSet.Spec.VolumeClaimTemplates
+
+	// With autoscaling the HPA sets replicas (possibly zero); don't undo it
+	if llmCluster.Spec.Autoscaling.Enabled {
+		desiredStatefulSet.Spec.Replicas = actualStatefulSet.Spec.Replicas
+	}
+
+	if restartedAt, ok := actualStatefulSet.Spec.Template.Annotations[annotationRestartedAt]; ok {
+		if desiredStatefulSet.Spec.Template.Annotations == nil {
+			desiredStatefulSet.Spec.Template.Annotations = map[string]string{}
+		}
+		desiredStatefulSet.Spec.Template.Annotations[annotationRestartedAt] = restartedAt
+	}
+
+	// Update if needed
+	if !adopted && !statefulSetChanged(desiredStatefulSet, &actualStatefulSet) {
+		return &actualStatefulSet, nil
+	}
+	log.Info("Updating StatefulSet", "name", actualStatefulSet.Name)
+	actualStatefulSet.Spec = desiredStatefulSet.Spec
+	if err := r.Update(ctx, &actualStatefulSet); err != nil {
+		return nil, err
+	}
+
+	return &actualStatefulSet, nil
+}
+
+// statefulSetChanged reports whether the fields the operator sets differ from
+// the live StatefulSet. Only values present in desired are compared, so the
+// API server's defaults (probe timeouts, port protocols, fieldRef versions,
+// volume modes) never cause a write or a rollout; fields the operator can
+// clear (service account, template labels and annotations, resources, probes,
+// ports) are checked explicitly since a nil desired value matches anything.
+func statefulSetChanged(desired, actual *appsv1.StatefulSet) bool {
+	// Zero integers aren't "unset" to DeepDerivative, so fill in the probe
+	// fields the API server defaults on both sides
+	desired, actual = desired.DeepCopy(), actual.DeepCopy()
+	for _, sts := range []*appsv1.StatefulSet{desired, actual} {
+		for i := range sts.Spec.Template.Spec.Containers {
+			container := &sts.Spec.Template.Spec.Containers[i]
+			for _, probe := range []*corev1.Probe{container.StartupProbe, container.ReadinessProbe, container.LivenessProbe} {
+				defaultProbe(probe)
+			}
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(desired.Spec.Replicas, actual.Spec.Replicas) ||
+		!equality.Semantic.DeepDerivative(desired.Spec.UpdateStrategy, actual.Spec.UpdateStrategy) {
+		return true
+	}
+
+	desiredPod, actualPod := &desired.Spec.Template.Spec, &actual.Spec.Template.Spec
+	if len(desiredPod.Containers) != len(actualPod.Containers) ||
+		len(desiredPod.Volumes) != len(actualPod.Volumes) ||
+		desiredPod.ServiceAccountName != actualPod.ServiceAccountName ||
+		!equality.Semantic.DeepEqual(desired.Spec.Template.Labels, actual.Spec.Template.Labels) ||
+		!equality.Semantic.DeepEqual(desired.Spec.Template.Annotations, actual.Spec.Template.Annotations) ||
+		!equality.Semantic.DeepEqual(desiredPod.Affinity, actualPod.Affinity) ||
+		!equality.Semantic.DeepEqual(desiredPod.NodeSelector, actualPod.NodeSelector) ||
+		!equality.Semantic.DeepEqual(desiredPod.TopologySpreadConstraints, actualPod.TopologySpreadConstraints) {
+		return true
+	}
+	for i := range desiredPod.Containers {
+		desiredContainer, actualContainer := &desiredPod.Containers[i], &actualPod.Containers[i]
+		if len(desiredContainer.Env) != len(actualContainer.Env) ||
+			len(desiredContainer.VolumeMounts) != len(actualContainer.VolumeMounts) ||
+			len(desiredContainer.Ports) != len(actualContainer.Ports) ||
+			len(desiredContainer.Resources.Limits) != len(actualContainer.Resources.Limits) ||
+			len(desiredContainer.Resources.Requests) != len(actualContainer.Resources.Requests) ||
+			(desiredContainer.StartupProbe == nil) != (actualContainer.StartupProbe == nil) ||
+			(desiredContainer.ReadinessProbe == nil) != (actualContainer.ReadinessProbe == nil) ||
+			(desiredContainer.LivenessProbe == nil) != (actualContainer.LivenessProbe == nil) ||
+			!equality.Semantic.DeepEqual(desiredContainer.Args, actualContainer.Args) {
+			return true
+		}
+	}
+
+	return !equality.Semantic.DeepDerivative(desired.Spec.Template, actual.Spec.Template)
+}
+
+// defaultProbe sets the probe timings the API server defaults when unset
+func defaultProbe(probe *corev1.Probe) {
+	if probe == nil {
+		return
+	}
+	if probe.TimeoutSeconds == 0 {
+		probe.TimeoutSeconds = 1
+	}
+	if probe.PeriodSeconds == 0 {
+		probe.PeriodSeconds = 10
+	}
+	if probe.SuccessThreshold == 0 {
+		probe.SuccessThreshold = 1
+	}
+	if probe.FailureThreshold == 0 {
+		probe.FailureThreshold = 3
+	}
+}
+
+// adoptStatefulSet takes ownership of an existing StatefulSet that has no
+// controller, provided adoption is requested and the immutable fields match
+func (r *LLMClusterReconciler) adoptStatefulSet(llmCluster *servingv1alpha1.LLMCluster, desired, actual *appsv1.StatefulSet) error {
+	if owner := metav1.GetControllerOf(actual); owner != nil {
+		return fmt.Errorf("StatefulSet %s is controlled by %s %s", actual.Name, owner.Kind, owner.Name)
+	}
+	if llmCluster.Annotations[annotationAdopt] != "true" {
+		return fmt.Errorf("StatefulSet %s already exists; set annotation %s=true to adopt it", actual.Name, annotationAdopt)
+	}
+	if !equality.Semantic.DeepEqual(actual.Spec.Selector, desired.Spec.Selector) {
+		return fmt.Errorf("cannot adopt StatefulSet %s: selector does not match app=%s", actual.Name, llmCluster.Name)
+	}
+	if actual.Spec.ServiceName != desired.Spec.ServiceName {
+		return fmt.Errorf("cannot adopt StatefulSet %s: serviceName %q, expected %q",
+			actual.Name, actual.Spec.ServiceName, desired.Spec.ServiceName)
+	}
+
+	if actual.Labels == nil {
+		actual.Labels = map[string]string{}
+	}
+	for k, v := range desired.Labels {
+		actual.Labels[k] = v
+	}
+	return ctrl.SetControllerReference(llmCluster, actual, r.Scheme)
+}
+
+// reconcileRouterDeployment creates or updates the router Deployment
+func (r *LLMClusterReconciler) reconcileRouterDeployment(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	routerName := fmt.Sprintf("%s-router", llmCluster.Name)
+	labels := map[string]string{"app": routerName}
+	routerType := llmCluster.Spec.Router.Type
+	if routerType == "" {
+		routerType = routerTypeNginx
+	}
+
+	image := llmCluster.Spec.Router.Image
+	if image == "" {
+		image = defaultRouterImage
+		if routerType == routerTypeEnvoy {
+			image = defaultEnvoyRouterImage
+		}
+	}
+
+	desiredDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      routerName,
+			Namespace: llmCluster.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: func() *int32 { i := routerReplicas(llmCluster); return &i }(),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "router",
+							Image: image,
+							Ports: []corev1.ContainerPort{
+								{Name: "http", ContainerPort: routerContainerPort},
+							},
+							// Proxied to the backends, so the router is only
+							// Ready while it can reach a healthy engine
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: "/health",
+										Port: intstr.FromString("http"),
+									},
+								},
+								PeriodSeconds: 10,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// nginx and envoy get an operator-rendered upstream config; other router
+	// types receive the backend list through the environment
+	podSpec := &desiredDeployment.Spec.Template.Spec
+	backends := routerUpstreams(llmCluster)
+	draining := routerDrainingUpstreams(llmCluster)
+	weightTable, err := r.routerWeightTable(ctx, llmCluster)
+	if err != nil {
+		return err
+	}
+	weights, err := routerWeights(llmCluster, weightTable)
+	if err != nil {
+		return err
+	}
+	var routerConfig map[string]string
+	switch routerType {
+	case routerTypeNginx:
+		routerConfig = map[string]string{"nginx.conf": renderNginxConfig(llmCluster, backends, draining, weights)}
+		podSpec.Containers[0].VolumeMounts = []corev1.VolumeMount{
+			{Name: "router-config", MountPath: "/etc/nginx/nginx.conf", SubPath: "nginx.conf", ReadOnly: true},
+		}
+	case routerTypeEnvoy:
+		routerConfig = map[string]string{"envoy.yaml": renderEnvoyConfig(llmCluster, backends, draining, weights)}
+		podSpec.Containers[0].Args = []string{"-c", "/etc/envoy/envoy.yaml"}
+		podSpec.Containers[0].VolumeMounts = []corev1.VolumeMount{
+			{Name: "router-config", MountPath: "/etc/envoy", ReadOnly: true},
+		}
+	default:
+		podSpec.Containers[0].Env = []corev1.EnvVar{
+			{Name: "BACKENDS", Value: strings.Join(backends, ",")},
+			{Name: "DRAINING_BACKENDS", Value: strings.Join(draining, ",")},
+			{Name: "BACKEND_WEIGHTS", Value: formatWeights(backends, weights)},
+			{Name: "PORT", Value: strconv.Itoa(routerContainerPort)},
+		}
+	}
+	if routerConfig != nil {
+		configName := fmt.Sprintf("%s-config", routerName)
+		if err := r.reconcileConfigMap(ctx, llmCluster, configName, routerConfig); err != nil {
+			return err
+		}
+		podSpec.Volumes = []corev1.Volume{{
+			Name: "router-config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: configName},
+				},
+			},
+		}}
+		// subPath mounts never see ConfigMap updates, so roll the pods instead
+		desiredDeployment.Spec.Template.Annotations = map[string]string{
+			annotationConfigChecksum: configChecksum(routerConfig[firstKey(routerConfig)]),
+		}
+	}
+
+	if err := ctrl.SetControllerReference(llmCluster, desiredDeployment, r.Scheme); err != nil {
+		return err
+	}
+
+	// Create or update
+	var actualDeployment appsv1.Deployment
+	err = r.Get(ctx, client.ObjectKeyFromObject(desiredDeployment), &actualDeployment)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Creating router Deployment", "name", desiredDeployment.Name)
+			if err := r.Create(ctx, desiredDeployment); err != nil {
+				return err
+			}
+			r.recordWrite(llmCluster, "Created", "create", "router Deployment")
+			return nil
+		}
+		return err
+	}
+
+	actualDeployment.Spec = desiredDeployment.Spec
+	return r.Update(ctx, &actualDeployment)
+}
+
+// routerUpstreams returns the router's backend addresses (host:port) taking
+// new requests: the configured Spec.Router.Backends that aren't draining, or
+// else each engine pod of this cluster via its stable StatefulSet DNS name
+func routerUpstreams(llmCluster *servingv1alpha1.LLMCluster) []string {
+	var upstreams []string
+	if len(llmCluster.Spec.Router.Backends) > 0 {
+		for _, backend := range llmCluster.Spec.Router.Backends {
+			if !backend.Draining {
+				upstreams = append(upstreams, backendAddress(backend))
+			}
+		}
+		return upstreams
+	}
+
+	for i := 0; i < llmCluster.Spec.Replicas; i++ {
+		upstreams = append(upstreams, fmt.Sprintf("%s-%d.%s-backend.%s.svc.cluster.local:%d",
+			llmCluster.Name, i, llmCluster.Name, llmCluster.Namespace, inferencePort(&llmCluster.Spec)))
+	}
+	return upstreams
+}
+
+// routerDrainingUpstreams returns the addresses of draining backends. They
+// stay in the router config marked unavailable rather than being dropped, so
+// connections already routed to them aren't cut.
+func routerDrainingUpstreams(llmCluster *servingv1alpha1.LLMCluster) []string {
+	var upstreams []string
+	for _, backend := range llmCluster.Spec.Router.Backends {
+		if backend.Draining {
+			upstreams = append(upstreams, backendAddress(backend))
+		}
+	}
+	return upstreams
+}
+
+// validateRouterWeights checks a weight table: percentages 0-100 summing to
+// at most 100
+func validateRouterWeights(table map[string]int32) error {
+	var sum int32
+	for name, weight := range table {
+		if weight < 0 || weight > 100 {
+			return fmt.Errorf("weight %d for backend %q is outside 0-100", weight, name)
+		}
+		sum += weight
+	}
+	if sum > 100 {
+		return fmt.Errorf("weights sum to %d%%, more than 100%%", sum)
+	}
+	return nil
+}
+
+// routerWeightTable returns Spec.Router.Weights overridden by the entries of
+// Spec.Router.WeightsConfigMap
+func (r *LLMClusterReconciler) routerWeightTable(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) (map[string]int32, error) {
+	table := map[string]int32{}
+	for name, weight := range llmCluster.Spec.Router.Weights {
+		table[name] = weight
+	}
+	name := llmCluster.Spec.Router.WeightsConfigMap
+	if name == "" {
+		return table, nil
+	}
+	var cm corev1.ConfigMap
+	if err := r.Get(ctx, client.ObjectKey{Namespace: llmCluster.Namespace, Name: name}, &cm); err != nil {
+		return nil, fmt.Errorf("get router weights ConfigMap %s: %w", name, err)
+	}
+	for backend, value := range cm.Data {
+		weight, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("router weights ConfigMap %s: backend %q: %q is not an integer", name, backend, value)
+		}
+		table[backend] = int32(weight)
+	}
+	if err := validateRouterWeights(table); err != nil {
+		return nil, fmt.Errorf("router weights ConfigMap %s: %v", name, err)
+	}
+	return table, nil
+}
+
+// routerWeights turns the weight table into a relative weight per active
+// upstream address, or nil when there is nothing to weight (empty table or
+// per-pod upstreams). Backends in the table get their percentage and the
+// rest split what remains equally; when every backend is listed the
+// percentages are scaled up to 100. Entries for absent backends (e.g. scaled
+// away) are ignored. Weights are reduced by their common divisor.
+func routerWeights(llmCluster *servingv1alpha1.LLMCluster, table map[string]int32) (map[string]int, error) {
+	if len(table) == 0 || len(llmCluster.Spec.Router.Backends) == 0 {
+		return nil, nil
+	}
+
+	// In basis points (1/100 of a percent) so equal splits stay exact enough
+	var listed, unlisted []servingv1alpha1.RouterBackend
+	listedTotal := 0
+	for _, backend := range llmCluster.Spec.Router.Backends {
+		if backend.Draining {
+			continue
+		}
+		if weight, ok := table[backend.Name]; ok {
+			listed = append(listed, backend)
+			listedTotal += int(weight) * 100
+		} else {
+			unlisted = append(unlisted, backend)
+		}
+	}
+	if len(listed) == 0 {
+		return nil, nil
+	}
+
+	weights := make(map[string]int, len(listed)+len(unlisted))
+	if len(unlisted) == 0 {
+		if listedTotal == 0 {
+			return nil, fmt.Errorf("router weights are 0 for every backend")
+		}
+		for _, backend := range listed {
+			weights[backendAddress(backend)] = int(table[backend.Name]) * 100 * 10000 / listedTotal
+		}
+	} else {
+		for _, backend := range listed {
+			weights[backendAddress(backend)] = int(table[backend.Name]) * 100
+		}
+		for _, backend := range unlisted {
+			weights[backendAddress(backend)] = (10000 - listedTotal) / len(unlisted)
+		}
+	}
+
+	divisor := 0
+	for _, weight := range weights {
+		divisor = gcd(divisor, weight)
+	}
+	if divisor > 1 {
+		for address := range weights {
+			weights[address] /= divisor
+		}
+	}
+	return weights, nil
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// formatWeights renders weights as address=weight pairs for custom routers
+func formatWeights(upstreams []string, weights map[string]int) string {
+	if weights == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(upstreams))
+	for _, upstream := range upstreams {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", upstream, weights[upstream]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func backendAddress(backend servingv1alpha1.RouterBackend) string {
+	port := backend.Port
+	if port == 0 {
+		port = defaultInferencePort
+	}
+	return fmt.Sprintf("%s:%d", backend.Service, port)
+}
+
+// renderNginxConfig renders nginx.conf balancing across the upstreams with
+// least_conn (long-running generations make round-robin uneven). Draining
+// upstreams, and ones weighted 0, are marked down: no new requests, while a
+// reload lets the old workers finish the ones in flight.
+func renderNginxConfig(llmCluster *servingv1alpha1.LLMCluster, upstreams, draining []string, weights map[string]int) string {
+	var b strings.Builder
+	b.WriteString("worker_processes auto;\n")
+	b.WriteString("events { worker_connections 4096; }\n")
+	b.WriteString("http {\n")
+	b.WriteString("  upstream backends {\n")
+	b.WriteString("    least_conn;\n")
+	for _, upstream := range upstreams {
+		switch weight, weighted := weights[upstream]; {
+		case !weighted:
+			fmt.Fprintf(&b, "    server %s max_fails=3 fail_timeout=10s;\n", upstream)
+		case weight == 0:
+			fmt.Fprintf(&b, "    server %s down;\n", upstream)
+		default:
+			fmt.Fprintf(&b, "    server %s weight=%d max_fails=3 fail_timeout=10s;\n", upstream, weight)
+		}
+	}
+	for _, upstream := range draining {
+		fmt.Fprintf(&b, "    server %s down;\n", upstream)
+	}
+	b.WriteString("    keepalive 64;\n")
+	b.WriteString("  }\n")
+	b.WriteString("  server {\n")
+	fmt.Fprintf(&b, "    listen %d;\n", routerContainerPort)
+	b.WriteString("    location / {\n")
+	fmt.Fprintf(&b, "      proxy_pass %s://backends;\n", strings.ToLower(inferencePortName(llmCluster)))
+	b.WriteString("      proxy_http_version 1.1;\n")
+	b.WriteString("      proxy_set_header Connection \"\";\n")
+	b.WriteString("      proxy_buffering off;\n")
+	b.WriteString("      proxy_read_timeout 600s;\n")
+	b.WriteString("    }\n")
+	b.WriteString("  }\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// renderEnvoyConfig renders a static envoy bootstrap with one LEAST_REQUEST
+// cluster over the upstreams; draining ones get health_status DRAINING, which
+// takes them out of load balancing without closing existing streams. Weights
+// become load_balancing_weight; envoy has no weight 0, so those upstreams are
+// rendered draining too.
+func renderEnvoyConfig(llmCluster *servingv1alpha1.LLMCluster, upstreams, draining []string, weights map[string]int) string {
+	var b strings.Builder
+	b.WriteString("static_resources:\n")
+	b.WriteString("  listeners:\n")
+	b.WriteString("  - name: http\n")
+	fmt.Fprintf(&b, "    address: {socket_address: {address: 0.0.0.0, port_value: %d}}\n", routerContainerPort)
+	b.WriteString("    filter_chains:\n")
+	b.WriteString("    - filters:\n")
+	b.WriteString("      - name: envoy.filters.network.http_connection_manager\n")
+	b.WriteString("        typed_config:\n")
+	b.WriteString("          \"@type\": type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager\n")
+	b.WriteString("          stat_prefix: ingress\n")
+	b.WriteString("          http_filters:\n")
+	b.WriteString("          - name: envoy.filters.http.router\n")
+	b.WriteString("            typed_config:\n")
+	b.WriteString("              \"@type\": type.googleapis.com/envoy.extensions.filters.http.router.v3.Router\n")
+	b.WriteString("          route_config:\n")
+	b.WriteString("            virtual_hosts:\n")
+	b.WriteString("            - name: backends\n")
+	b.WriteString("              domains: [\"*\"]\n")
+	b.WriteString("              routes:\n")
+	b.WriteString("              - match: {prefix: \"/\"}\n")
+	b.WriteString("                route: {cluster: backends, timeout: 600s}\n")
+	b.WriteString("  clusters:\n")
+	b.WriteString("  - name: backends\n")
+	b.WriteString("    type: STRICT_DNS\n")
+	b.WriteString("    lb_policy: LEAST_REQUEST\n")
+	b.WriteString("    load_assignment:\n")
+	b.WriteString("      cluster_name: backends\n")
+	b.WriteString("      endpoints:\n")
+	b.WriteString("      - lb_endpoints:\n")
+	for _, upstream := range upstreams {
+		weight, weighted := weights[upstream]
+		if weighted && weight == 0 {
+			draining = append(draining, upstream)
+			continue
+		}
+		host, port, _ := strings.Cut(upstream, ":")
+		fmt.Fprintf(&b, "        - endpoint: {address: {socket_address: {address: %s, port_value: %s}}}\n", host, port)
+		if weighted {
+			fmt.Fprintf(&b, "          load_balancing_weight: %d\n", weight)
+		}
+	}
+	for _, upstream := range draining {
+		host, port, _ := strings.Cut(upstream, ":")
+		fmt.Fprintf(&b, "        - endpoint: {address: {socket_address: {address: %s, port_value: %s}}}\n", host, port)
+		b.WriteString("          health_status: DRAINING\n")
+	}
+	if llmCluster.Spec.Network.TLS.Enabled {
+		b.WriteString("    transport_socket:\n")
+		b.WriteString("      name: envoy.transport_sockets.tls\n")
+		b.WriteString("      typed_config:\n")
+		b.WriteString("        \"@type\": type.googleapis.com/envoy.extensions.transport_sockets.tls.v3.UpstreamTlsContext\n")
+	}
+	return b.String()
+}
+
+// firstKey returns the lexically first key of m
+func firstKey(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
+// routerReplicas returns the router Deployment replica count. With AutoReplicas
+// enabled it is one replica per BackendsPerReplica upstreams taking new
+// requests (see routerUpstreams), bounded by MinReplicas/MaxReplicas;
+// otherwise the static Replicas value.
+func routerReplicas(llmCluster *servingv1alpha1.LLMCluster) int32 {
+	router := llmCluster.Spec.Router
+	if !router.AutoReplicas.Enabled {
+		if router.Replicas > 0 {
+			return int32(router.Replicas)
+		}
+		return 1
+	}
+
+	perReplica := router.AutoReplicas.BackendsPerReplica
+	if perReplica <= 0 {
+		perReplica = defaultBackendsPerRouterReplica
+	}
+	minReplicas := router.AutoReplicas.MinReplicas
+	if minReplicas <= 0 {
+		minReplicas = defaultMinRouterReplicas
+	}
+
+	replicas := (len(routerUpstreams(llmCluster)) + perReplica - 1) / perReplica
+	if replicas < minReplicas {
+		replicas = minReplicas
+	}
+	if max := router.AutoReplicas.MaxReplicas; max > 0 && replicas > max {
+		replicas = max
+	}
+	return int32(replicas)
+}
+
+// reconcileQueueDeployment creates or updates the queue Deployment
+func (r *LLMClusterReconciler) reconcileQueueDeployment(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	// TODO: Implement queue Deployment creation
+	return nil
+}
+
+// reconcileServices creates or updates Services:
+// - <name>-backend: headless, per-pod DNS for TP rendezvous and per-pod scraping
+// - <name>: client-facing Service load-balancing across pods
+// - <name>-router: router Service (if router enabled)
+func (r *LLMClusterReconciler) reconcileServices(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	selector := map[string]string{"app": llmCluster.Name}
+
+	backendPorts := []corev1.ServicePort{
+		{Name: inferencePortName(llmCluster), Port: int32(inferencePort(&llmCluster.Spec)), TargetPort: intstr.FromString(inferencePortName(llmCluster))},
+	}
+	if port := metricsPort(llmCluster); port != inferencePort(&llmCluster.Spec) {
+		backendPorts = append(backendPorts, corev1.ServicePort{
+			Name: "metrics", Port: int32(port), TargetPort: intstr.FromString("metrics"),
+		})
+	}
+
+	headlessService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-backend", llmCluster.Name),
+			Namespace: llmCluster.Namespace,
+			Labels:    map[string]string{"app": llmCluster.Name},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:                corev1.ClusterIPNone,
+			PublishNotReadyAddresses: true,
+			Selector:                 selector,
+			Ports:                    backendPorts,
+		},
+	}
+
+	serviceType := corev1.ServiceTypeClusterIP
+	if llmCluster.Spec.Network.ServiceType != "" {
+		serviceType = corev1.ServiceType(llmCluster.Spec.Network.ServiceType)
+	}
+
+	clientService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      llmCluster.Name,
+			Namespace: llmCluster.Namespace,
+			Labels:    map[string]string{"app": llmCluster.Name},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     serviceType,
+			Selector: selector,
+			Ports: []corev1.ServicePort{
+				{Name: inferencePortName(llmCluster), Port: int32(servicePort(llmCluster)), TargetPort: intstr.FromString(inferencePortName(llmCluster))},
+			},
+		},
+	}
+
+	services := []*corev1.Service{headlessService, clientService}
+	if llmCluster.Spec.Router.Enabled {
+		routerName := fmt.Sprintf("%s-router", llmCluster.Name)
+		services = append(services, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      routerName,
+				Namespace: llmCluster.Namespace,
+				Labels:    map[string]string{"app": routerName},
+			},
+			Spec: corev1.ServiceSpec{
+				Type:     serviceType,
+				Selector: map[string]string{"app": routerName},
+				Ports: []corev1.ServicePort{
+					{Name: "http", Port: int32(servicePort(llmCluster)), TargetPort: intstr.FromString("http")},
+				},
+			},
+		})
+	}
+
+	for _, desiredService := range services {
+		if err := r.reconcileService(ctx, llmCluster, desiredService); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileService creates or updates a single Service, preserving the
+// immutable ClusterIP allocated by the API server
+func (r *LLMClusterReconciler) reconcileService(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster, desiredService *corev1.Service) error {
+	if err := ctrl.SetControllerReference(llmCluster, desiredService, r.Scheme); err != nil {
+		return err
+	}
+
+	var actualService corev1.Service
+	err := r.Get(ctx, client.ObjectKeyFromObject(desiredService), &actualService)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err := r.Create(ctx, desiredService); err != nil {
+				return err
+			}
+			r.recordWrite(llmCluster, "Created", "create", "Service "+desiredService.Name)
+			return nil
+		}
+		return err
+	}
+
+	actualService.Labels = desiredService.Labels
+	actualService.Spec.Type = desiredService.Spec.Type
+	actualService.Spec.Selector = desiredService.Spec.Selector
+	actualService.Spec.Ports = desiredService.Spec.Ports
+	actualService.Spec.PublishNotReadyAddresses = desiredService.Spec.PublishNotReadyAddresses
+	return r.Update(ctx, &actualService)
+}
+
+// reconcileConfigMaps creates or updates ConfigMaps
+func (r *LLMClusterReconciler) reconcileConfigMaps(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	return r.reconcileConfigMap(ctx, llmCluster, configMapName(llmCluster), map[string]string{
+		engineArgsKey: renderEngineArgs(llmCluster),
+	})
+}
+
+// reconcileConfigMap creates or updates an owned ConfigMap with the given data
+func (r *LLMClusterReconciler) reconcileConfigMap(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster, name string, data map[string]string) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	desiredConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: llmCluster.Namespace,
+			Labels: map[string]string{
+				"app":                         llmCluster.Name,
+				"llmcluster.serving.ai/owned": "true",
+			},
+		},
+		Data: data,
+	}
+
+	if err := ctrl.SetControllerReference(llmCluster, desiredConfigMap, r.Scheme); err != nil {
+		return err
+	}
+
+	var actualConfigMap corev1.ConfigMap
+	err := r.Get(ctx, client.ObjectKeyFromObject(desiredConfigMap), &actualConfigMap)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Creating ConfigMap", "name", desiredConfigMap.Name)
+			if err := r.Create(ctx, desiredConfigMap); err != nil {
+				return err
+			}
+			r.recordWrite(llmCluster, "Created", "create", "ConfigMap")
+			return nil
+		}
+		return err
+	}
+
+	actualConfigMap.Labels = desiredConfigMap.Labels
+	actualConfigMap.Data = desiredConfigMap.Data
+	return r.Update(ctx, &actualConfigMap)
+}
+
+// reconcileHPA creates or updates HorizontalPodAutoscaler
+func (r *LLMClusterReconciler) reconcileHPA(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	metrics, err := buildHPAMetrics(llmCluster.Spec.Autoscaling)
+	if err != nil {
+		return err
+	}
+
+	desiredHPA := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-hpa", llmCluster.Name),
+			Namespace: llmCluster.Namespace,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "StatefulSet",
+				Name:       llmCluster.Name,
+			},
+			// minReplicas 0 lets the HPA idle the cluster at zero pods. It
+			// needs the HPAScaleToZero feature gate, and validateSpec requires
+			// an External customMetric (e.g. queue length) since there are
+			// no pod metrics at zero: the HPA wakes the cluster once it is > 0
+			MinReplicas: func() *int32 { i := int32(llmCluster.Spec.Autoscaling.MinReplicas); return &i }(),
+			MaxReplicas: int32(llmCluster.Spec.Autoscaling.MaxReplicas),
+			Metrics:     metrics,
+			Behavior:    buildHPABehavior(llmCluster.Spec.Autoscaling.Behavior),
+		},
+	}
+
+	if err := ctrl.SetControllerReference(llmCluster, desiredHPA, r.Scheme); err != nil {
+		return err
+	}
+
+	// Create or update
+	var actualHPA autoscalingv2.HorizontalPodAutoscaler
+	err = r.Get(ctx, client.ObjectKeyFromObject(desiredHPA), &actualHPA)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err := r.Create(ctx, desiredHPA); err != nil {
+				return err
+			}
+			r.recordWrite(llmCluster, "Created", "create", "HPA")
+			return nil
+		}
+		return err
+	}
+
+	actualHPA.Spec = desiredHPA.Spec
+	return r.Update(ctx, &actualHPA)
+}
+
+// buildHPAMetrics returns the HPA metric list. Pods still loading the model
+// report near-zero CPU, so a ReadyPodsMetric (only emitted by Ready pods)
+// and CustomMetric replace CPU utilization when configured; with both, the
+// HPA follows whichever asks for more replicas.
+func buildHPAMetrics(autoscaling servingv1alpha1.AutoscalingConfig) ([]autoscalingv2.MetricSpec, error) {
+	var metrics []autoscalingv2.MetricSpec
+
+	if readyMetric := autoscaling.Behavior.ReadyPodsMetric; readyMetric.Name != "" {
+		metric, err := customMetricSpec(readyMetric, autoscalingv2.PodsMetricSourceType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid readyPodsMetric: %w", err)
+		}
+		metrics = append(metrics, metric)
+	}
+
+	if customMetric := autoscaling.CustomMetric; customMetric.Name != "" {
+		sourceType := autoscalingv2.MetricSourceType(customMetric.Type)
+		if sourceType == "" {
+			sourceType = autoscalingv2.PodsMetricSourceType
+		}
+		metric, err := customMetricSpec(customMetric, sourceType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid customMetric: %w", err)
+		}
+		metrics = append(metrics, metric)
+	}
+
+	if len(metrics) > 0 {
+		return metrics, nil
+	}
+	return []autoscalingv2.MetricSpec{
+		{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: func() *int32 { i := int32(autoscaling.TargetCPUUtilizationPercentage); return &i }(),
+				},
+			},
+		},
+	}, nil
+}
+
+// customMetricSpec returns a Pods or External metric with an average value
+// target
+func customMetricSpec(metric servingv1alpha1.CustomMetric, sourceType autoscalingv2.MetricSourceType) (autoscalingv2.MetricSpec, error) {
+	var target autoscalingv2.MetricTarget
+	if metric.Target.Value != "" {
+		// A total target only makes sense for a metric not split across pods,
+		// e.g. a Prometheus-adapter external queue length
+		if sourceType != autoscalingv2.ExternalMetricSourceType {
+			return autoscalingv2.MetricSpec{}, fmt.Errorf("target.value needs type External, got %q", sourceType)
+		}
+		value, err := resource.ParseQuantity(metric.Target.Value)
+		if err != nil {
+			return autoscalingv2.MetricSpec{}, fmt.Errorf("target %q: %w", metric.Target.Value, err)
+		}
+		target = autoscalingv2.MetricTarget{Type: autoscalingv2.ValueMetricType, Value: &value}
+	} else {
+		averageValue, err := resource.ParseQuantity(metric.Target.AverageValue)
+		if err != nil {
+			return autoscalingv2.MetricSpec{}, fmt.Errorf("target %q: %w", metric.Target.AverageValue, err)
+		}
+		target = autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &averageValue}
+	}
+	identifier := autoscalingv2.MetricIdentifier{Name: metric.Name}
+	if len(metric.Selector) > 0 {
+		identifier.Selector = &metav1.LabelSelector{MatchLabels: metric.Selector}
+	}
+
+	switch sourceType {
+	case autoscalingv2.PodsMetricSourceType:
+		return autoscalingv2.MetricSpec{
+			Type: sourceType,
+			Pods: &autoscalingv2.PodsMetricSource{Metric: identifier, Target: target},
+		}, nil
+	case autoscalingv2.ExternalMetricSourceType:
+		return autoscalingv2.MetricSpec{
+			Type:     sourceType,
+			External: &autoscalingv2.ExternalMetricSource{Metric: identifier, Target: target},
+		}, nil
+	}
+	return autoscalingv2.MetricSpec{}, fmt.Errorf("type must be Pods or External, got %q", sourceType)
+}
+
+// buildHPABehavior returns the HPA stabilization behavior, or nil to keep
+// the Kubernetes defaults when no window is configured
+func buildHPABehavior(behavior servingv1alpha1.AutoscalingBehavior) *autoscalingv2.HorizontalPodAutoscalerBehavior {
+	if behavior.ScaleUpStabilizationWindowSeconds == 0 && behavior.ScaleDownStabilizationWindowSeconds == 0 {
+		return nil
+	}
+
+	hpaBehavior := &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+	if behavior.ScaleUpStabilizationWindowSeconds > 0 {
+		hpaBehavior.ScaleUp = &autoscalingv2.HPAScalingRules{
+			StabilizationWindowSeconds: func() *int32 { i := int32(behavior.ScaleUpStabilizationWindowSeconds); return &i }(),
+		}
+	}
+	if behavior.ScaleDownStabilizationWindowSeconds > 0 {
+		hpaBehavior.ScaleDown = &autoscalingv2.HPAScalingRules{
+			StabilizationWindowSeconds: func() *int32 { i := int32(behavior.ScaleDownStabilizationWindowSeconds); return &i }(),
+		}
+	}
+	return hpaBehavior
+}
+
+// reconcilePDB creates or updates PodDisruptionBudget
+func (r *LLMClusterReconciler) reconcilePDB(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	pdbConfig := llmCluster.Spec.HighAvailability.PodDisruptionBudget
+
+	// validateSpec keeps minAvailable within replicas
+	minAvailable := intstr.FromInt(pdbMinAvailable(pdbConfig))
+
+	desiredPDB := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-pdb", llmCluster.Name),
+			Namespace: llmCluster.Namespace,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": llmCluster.Name},
+			},
+		},
+	}
+	if llmCluster.Spec.HighAvailability.ProtectLoadingPods {
+		// Loading pods belong to the loading PDB; the eviction API refuses
+		// pods matched by more than one PDB
+		desiredPDB.Spec.Selector.MatchExpressions = []metav1.LabelSelectorRequirement{
+			{Key: labelLoading, Operator: metav1.LabelSelectorOpDoesNotExist},
+		}
+	}
+
+	if err := ctrl.SetControllerReference(llmCluster, desiredPDB, r.Scheme); err != nil {
+		return err
+	}
+
+	// Create or update
+	var actualPDB policyv1.PodDisruptionBudget
+	err := r.Get(ctx, client.ObjectKeyFromObject(desiredPDB), &actualPDB)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err := r.Create(ctx, desiredPDB); err != nil {
+				return err
+			}
+			r.recordWrite(llmCluster, "Created", "create", "PDB")
+			return nil
+		}
+		return err
+	}
+
+	actualPDB.Spec = desiredPDB.Spec
+	return r.Update(ctx, &actualPDB)
+}
+
+// reconcileLoadingProtection keeps the loading label in step with pod
+// readiness and maintains the <name>-loading-pdb that selects labelled pods
+// with maxUnavailable 0.
+//
+// The default scheduler prefers preemption victims whose removal doesn't
+// violate a PDB, so a pod minutes into a model load is only preempted as a
+// last resort; the custom scheduler (examples/08) never preempts a pod with
+// the label at all. Pod priority is immutable once the pod exists, so a high
+// "loading" priority that drops at Ready isn't possible; the label is the
+// part that changes. New pods carry the label from the template and lose it
+// here once Ready; a pod that drops out of Ready (a restart reloading the
+// model) gets it back.
+func (r *LLMClusterReconciler) reconcileLoadingProtection(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(llmCluster.Namespace),
+		client.MatchingLabels{"app": llmCluster.Name}); err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		_, loading := pod.Labels[labelLoading]
+		if loading != podReady(pod) {
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		if loading {
+			delete(pod.Labels, labelLoading)
+		} else {
+			if pod.Labels == nil {
+				pod.Labels = map[string]string{}
+			}
+			pod.Labels[labelLoading] = "true"
+		}
+		if err := r.Patch(ctx, pod, patch); err != nil {
+			if errors.IsNotFound(err) {
+				// Deleted since the list; the rest still need their label
+				continue
+			}
+			return err
+		}
+	}
+
+	maxUnavailable := intstr.FromInt(0)
+	desiredPDB := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-loading-pdb", llmCluster.Name),
+			Namespace: llmCluster.Namespace,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": llmCluster.Name, labelLoading: "true"},
+			},
+		},
+	}
+
+	if err := ctrl.SetControllerReference(llmCluster, desiredPDB, r.Scheme); err != nil {
+		return err
+	}
+
+	// Create or update
+	var actualPDB policyv1.PodDisruptionBudget
+	err := r.Get(ctx, client.ObjectKeyFromObject(desiredPDB), &actualPDB)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err := r.Create(ctx, desiredPDB); err != nil {
+				return err
+			}
+			r.recordWrite(llmCluster, "Created", "create", "loading PDB")
+			return nil
+		}
+		return err
+	}
+
+	actualPDB.Spec = desiredPDB.Spec
+	return r.Update(ctx, &actualPDB)
+}
+
+// pdbMinAvailable returns the PDB minAvailable after reserving preemption
+// headroom. A PDB protecting every pod would also block the scheduler from
+// preempting any of them for higher-priority work (the scheduler honors PDBs
+// on a best-effort basis and prefers victims that don't violate them), so
+// PreemptionHeadroom pods are left evictable.
+func pdbMinAvailable(pdb servingv1alpha1.PDBConfig) int {
+	minAvailable := pdb.MinAvailable - pdb.PreemptionHeadroom
+	if minAvailable < 0 {
+		return 0
+	}
+	return minAvailable
+}
+
+// reconcileNetworkPolicy creates or updates NetworkPolicy
+//
+// Ingress to the inference pods is limited to: the router pods (or any
+// client when there is no router), Prometheus on the metrics port, and the
+// other pods of the same cluster for tensor-parallel traffic. Egress allows
+// DNS, the same intra-cluster traffic, and HTTPS for the model download;
+// NetworkPolicy cannot match hostnames, so HTTPS is open to any address.
+func (r *LLMClusterReconciler) reconcileNetworkPolicy(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	protocolTCP := corev1.ProtocolTCP
+	protocolUDP := corev1.ProtocolUDP
+	port := func(p intstr.IntOrString, protocol *corev1.Protocol) networkingv1.NetworkPolicyPort {
+		return networkingv1.NetworkPolicyPort{Protocol: protocol, Port: &p}
+	}
+
+	selfPeer := networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": llmCluster.Name}},
+	}
+
+	// Inference port: only the router when it fronts the backends
+	inferenceRule := networkingv1.NetworkPolicyIngressRule{
+		Ports: []networkingv1.NetworkPolicyPort{port(intstr.FromString(inferencePortName(llmCluster)), &protocolTCP)},
+	}
+	if llmCluster.Spec.Router.Enabled {
+		inferenceRule.From = []networkingv1.NetworkPolicyPeer{{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": fmt.Sprintf("%s-router", llmCluster.Name)},
+			},
+		}}
+	}
+
+	desiredPolicy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      llmCluster.Name,
+			Namespace: llmCluster.Namespace,
+			Labels: map[string]string{
+				"app":                         llmCluster.Name,
+				"llmcluster.serving.ai/owned": "true",
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": llmCluster.Name},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				inferenceRule,
+				{
+					// Prometheus scrape
+					From: []networkingv1.NetworkPolicyPeer{{
+						NamespaceSelector: &metav1.LabelSelector{},
+						PodSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{prometheusPodLabel: prometheusPodValue},
+						},
+					}},
+					Ports: []networkingv1.NetworkPolicyPort{port(intstr.FromString(metricsPortName(llmCluster)), &protocolTCP)},
+				},
+				{
+					// Tensor-parallel / NCCL traffic between replicas
+					From: []networkingv1.NetworkPolicyPeer{selfPeer},
+				},
+			},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{
+					// DNS
+					Ports: []networkingv1.NetworkPolicyPort{
+						port(intstr.FromInt(53), &protocolUDP),
+						port(intstr.FromInt(53), &protocolTCP),
+					},
+				},
+				{
+					// Tensor-parallel / NCCL traffic between replicas
+					To: []networkingv1.NetworkPolicyPeer{selfPeer},
+				},
+				{
+					// Model download (huggingface.co and its CDN)
+					Ports: []networkingv1.NetworkPolicyPort{port(intstr.FromInt(443), &protocolTCP)},
+				},
+			},
+		},
+	}
+
+	if err := ctrl.SetControllerReference(llmCluster, desiredPolicy, r.Scheme); err != nil {
+		return err
+	}
+
+	// Create or update
+	var actualPolicy networkingv1.NetworkPolicy
+	err := r.Get(ctx, client.ObjectKeyFromObject(desiredPolicy), &actualPolicy)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Creating NetworkPolicy", "name", desiredPolicy.Name)
+			if err := r.Create(ctx, desiredPolicy); err != nil {
+				return err
+			}
+			r.recordWrite(llmCluster, "Created", "create", "NetworkPolicy")
+			return nil
+		}
+		return err
+	}
+
+	if equality.Semantic.DeepEqual(actualPolicy.Spec, desiredPolicy.Spec) &&
+		equality.Semantic.DeepEqual(actualPolicy.Labels, desiredPolicy.Labels) {
+		return nil
+	}
+	actualPolicy.Labels = desiredPolicy.Labels
+	actualPolicy.Spec = desiredPolicy.Spec
+	return r.Update(ctx, &actualPolicy)
+}
+
+// reconcileHTTPRoute creates or updates a Gateway API HTTPRoute pointing at the
+// router (or backend) Service. It is skipped when the Gateway API CRDs are absent.
+func (r *LLMClusterReconciler) reconcileHTTPRoute(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	log := ctrl.LoggerFrom(ctx)
+	gateway := llmCluster.Spec.Network.Gateway
+
+	installed, err := r.kindInstalled(httpRouteGVK)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		log.Info("Gateway API not installed, skipping HTTPRoute", "name", llmCluster.Name)
+		return nil
+	}
+
+	path := gateway.Path
+	if path == "" {
+		path = "/"
+	}
+
+	parentRef := map[string]interface{}{"name": gateway.ParentRef.Name}
+	if gateway.ParentRef.Namespace != "" {
+		parentRef["namespace"] = gateway.ParentRef.Namespace
+	}
+	if gateway.ParentRef.SectionName != "" {
+		parentRef["sectionName"] = gateway.ParentRef.SectionName
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{"type": "PathPrefix", "value": path},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": frontendServiceName(llmCluster),
+						"port": int64(servicePort(llmCluster)),
+					},
+				},
+			},
+		},
+	}
+	if gateway.Hostname != "" {
+		spec["hostnames"] = []interface{}{gateway.Hostname}
+	}
+
+	desiredRoute := &unstructured.Unstructured{}
+	desiredRoute.SetGroupVersionKind(httpRouteGVK)
+	desiredRoute.SetName(llmCluster.Name)
+	desiredRoute.SetNamespace(llmCluster.Namespace)
+	desiredRoute.SetLabels(map[string]string{"app": llmCluster.Name})
+	desiredRoute.Object["spec"] = spec
+
+	if err := r.reconcileUnstructured(ctx, llmCluster, desiredRoute); err != nil {
+		return err
+	}
+
+	if gateway.Hostname != "" {
+		scheme, err := r.routerURLScheme(ctx, llmCluster)
+		if err != nil {
+			return err
+		}
+		llmCluster.Status.RouterURL = fmt.Sprintf("%s://%s%s", scheme, gateway.Hostname, path)
+	}
+	return nil
+}
+
+// routerURLScheme returns "https" when the engine serves TLS or a Gateway
+// listener the HTTPRoute attaches to (the SectionName one, if set) is HTTPS,
+// otherwise "http". A missing Gateway counts as plain HTTP.
+func (r *LLMClusterReconciler) routerURLScheme(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) (string, error) {
+	if llmCluster.Spec.Network.TLS.Enabled {
+		return "https", nil
+	}
+
+	ref := llmCluster.Spec.Network.Gateway.ParentRef
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = llmCluster.Namespace
+	}
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(gatewayGVK)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, gateway); err != nil {
+		if errors.IsNotFound(err) {
+			return "http", nil
+		}
+		return "", err
+	}
+
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	for _, item := range listeners {
+		listener, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ref.SectionName != "" && listener["name"] != ref.SectionName {
+			continue
+		}
+		if listener["protocol"] == "HTTPS" {
+			return "https", nil
+		}
+	}
+	return "http", nil
+}
+
+// reconcilePodMonitor creates or updates a Prometheus Operator PodMonitor so each
+// replica is scraped individually. It is skipped when the CRD is absent.
+func (r *LLMClusterReconciler) reconcilePodMonitor(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	installed, err := r.kindInstalled(podMonitorGVK)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		log.Info("Prometheus Operator not installed, skipping PodMonitor", "name", llmCluster.Name)
+		return nil
+	}
+
+	desiredMonitor := &unstructured.Unstructured{}
+	desiredMonitor.SetGroupVersionKind(podMonitorGVK)
+	desiredMonitor.SetName(llmCluster.Name)
+	desiredMonitor.SetNamespace(llmCluster.Namespace)
+	desiredMonitor.SetLabels(map[string]string{"app": llmCluster.Name})
+	desiredMonitor.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"app": llmCluster.Name},
+		},
+		"podMetricsEndpoints": []interface{}{
+			podMetricsEndpoint(llmCluster),
+		},
+		// Loading pods' series carry serving_ai_loading, so the autoscaler's
+		// readyOnly queries can leave them out
+		"podTargetLabels": []interface{}{labelLoading},
+	}
+
+	return r.reconcileUnstructured(ctx, llmCluster, desiredMonitor)
+}
+
+// podMetricsEndpoint returns the PodMonitor endpoint for the metrics port,
+// scraping over HTTPS when the engine serves TLS on that port
+func podMetricsEndpoint(llmCluster *servingv1alpha1.LLMCluster) map[string]interface{} {
+	endpoint := map[string]interface{}{
+		"port":     metricsPortName(llmCluster),
+		"path":     "/metrics",
+		"interval": "15s",
+	}
+	if metricsPortName(llmCluster) == "https" {
+		endpoint["scheme"] = "https"
+		endpoint["tlsConfig"] = map[string]interface{}{"insecureSkipVerify": true}
+	}
+	return endpoint
+}
+
+// kindInstalled reports whether the API server serves the given kind
+func (r *LLMClusterReconciler) kindInstalled(gvk schema.GroupVersionKind) (bool, error) {
+	if _, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// reconcileUnstructured creates or updates an owner-referenced unstructured
+// object, replacing its spec on update
+func (r *LLMClusterReconciler) reconcileUnstructured(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster, desired *unstructured.Unstructured) error {
+	if err := ctrl.SetControllerReference(llmCluster, desired, r.Scheme); err != nil {
+		return err
+	}
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(desired.GroupVersionKind())
+	err := r.Get(ctx, client.ObjectKeyFromObject(desired), actual)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err := r.Create(ctx, desired); err != nil {
+				return err
+			}
+			r.recordWrite(llmCluster, "Created", "create", desired.GetKind())
+			return nil
+		}
+		return err
+	}
+
+	actual.SetLabels(desired.GetLabels())
+	actual.Object["spec"] = desired.Object["spec"]
+	return r.Update(ctx, actual)
+}
+
+// configMapName returns the name of the engine config ConfigMap
+func configMapName(llmCluster *servingv1alpha1.LLMCluster) string {
+	return fmt.Sprintf("%s-config", llmCluster.Name)
+}
+
+// tensorParallelSize returns spec.TensorParallelSize, or replicas ×
+// gpusPerPod when unset. The default is derived rather than written back, so
+// a later replicas or gpusPerPod edit doesn't leave a stale explicit value.
+func tensorParallelSize(spec *servingv1alpha1.LLMClusterSpec) int {
+	if spec.TensorParallelSize > 0 {
+		return spec.TensorParallelSize
+	}
+	return spec.Replicas * spec.GPUsPerPod
+}
+
+// inferenceCommand is an engine invocation: Entrypoint is exec'd in the
+// container and Args are rendered to the config ConfigMap
+type inferenceCommand struct {
+	Entrypoint []string
+	Args       []string
+}
+
+// buildInferenceCommand returns the entrypoint and args for
+// spec.InferenceEngine. Every engine listens on inferencePort (8000 unless
+// Network.ContainerPort is set, whatever the engine's own default) so
+// Services, probes and routers need not know which one is running. Unset
+// InferenceArgs fields are left to engine defaults; TGI and SGLang cache
+// prefixes by default, so PrefixCaching only adds vLLM flags.
+func buildInferenceCommand(spec *servingv1alpha1.LLMClusterSpec) inferenceCommand {
+	servedName := spec.ServedModelName
+	if servedName == "" {
+		servedName = spec.Model
+	}
+	inferenceArgs := spec.InferenceArgs
+	gpuMemoryUtilization := strconv.FormatFloat(inferenceArgs.GPUMemoryUtilization, 'f', -1, 64)
+
+	var cmd inferenceCommand
+	switch spec.InferenceEngine {
+	case engineTGI:
+		cmd.Entrypoint = []string{"text-generation-launcher"}
+		cmd.Args = []string{
+			fmt.Sprintf("--model-id=%s", spec.Model),
+			fmt.Sprintf("--num-shard=%d", tensorParallelSize(spec)),
+			"--hostname=0.0.0.0",
+			fmt.Sprintf("--port=%d", inferencePort(spec)),
+		}
+		if spec.ModelRevision != "" {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--revision=%s", spec.ModelRevision))
+		}
+		if inferenceArgs.MaxModelLen > 0 {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--max-total-tokens=%d", inferenceArgs.MaxModelLen))
+		}
+		if inferenceArgs.Dtype != "" {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--dtype=%s", inferenceArgs.Dtype))
+		}
+		if inferenceArgs.GPUMemoryUtilization > 0 {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--cuda-memory-fraction=%s", gpuMemoryUtilization))
+		}
+
+	case engineSGLang:
+		cmd.Entrypoint = []string{"python", "-m", "sglang.launch_server"}
+		cmd.Args = []string{
+			fmt.Sprintf("--model-path=%s", spec.Model),
+			fmt.Sprintf("--tp-size=%d", tensorParallelSize(spec)),
+			"--host=0.0.0.0",
+			fmt.Sprintf("--port=%d", inferencePort(spec)),
+			fmt.Sprintf("--served-model-name=%s", servedName),
+		}
+		if spec.ModelRevision != "" {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--revision=%s", spec.ModelRevision))
+		}
+		if inferenceArgs.MaxModelLen > 0 {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--context-length=%d", inferenceArgs.MaxModelLen))
+		}
+		if inferenceArgs.Dtype != "" {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--dtype=%s", inferenceArgs.Dtype))
+		}
+		if inferenceArgs.GPUMemoryUtilization > 0 {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--mem-fraction-static=%s", gpuMemoryUtilization))
+		}
+
+	default:
+		cmd.Entrypoint = []string{"python", "-m", "vllm.entrypoints.openai.api_server"}
+		cmd.Args = []string{
+			fmt.Sprintf("--model=%s", spec.Model),
+			fmt.Sprintf("--tensor-parallel-size=%d", tensorParallelSize(spec)),
+			"--host=0.0.0.0",
+			fmt.Sprintf("--port=%d", inferencePort(spec)),
+			fmt.Sprintf("--served-model-name=%s", servedName),
+		}
+		if spec.ModelRevision != "" {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--revision=%s", spec.ModelRevision))
+		}
+		if inferenceArgs.MaxModelLen > 0 {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--max-model-len=%d", inferenceArgs.MaxModelLen))
+		}
+		if inferenceArgs.BlockSize > 0 {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--block-size=%d", inferenceArgs.BlockSize))
+		}
+		if inferenceArgs.Dtype != "" {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--dtype=%s", inferenceArgs.Dtype))
+		}
+		if inferenceArgs.GPUMemoryUtilization > 0 {
+			cmd.Args = append(cmd.Args, fmt.Sprintf("--gpu-memory-utilization=%s", gpuMemoryUtilization))
+		}
+		if inferenceArgs.PrefixCaching.Enabled {
+			cmd.Args = append(cmd.Args, "--enable-prefix-caching")
+			if inferenceArgs.PrefixCaching.SharedClaimName != "" {
+				cmd.Args = append(cmd.Args, "--kv-transfer-config="+prefixCacheConnector)
+			}
+		}
+	}
+	return cmd
+}
+
+// renderEngineArgs renders the engine args stored in the config ConfigMap,
+// one flag per line
+func renderEngineArgs(llmCluster *servingv1alpha1.LLMCluster) string {
+	return strings.Join(buildInferenceCommand(&llmCluster.Spec).Args, "\n") + "\n"
+}
+
+// configChecksum hashes rendered config for the pod template annotation
+func configChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// effectiveArgs is the engine command line as launched: the entrypoint, the
+// args rendered into the config ConfigMap, then the container args
+func effectiveArgs(llmCluster *servingv1alpha1.LLMCluster) []string {
+	command := buildInferenceCommand(&llmCluster.Spec)
+	args := append(append([]string{}, command.Entrypoint...), command.Args...)
+	return append(args, tlsArgs(llmCluster)...)
+}
+
+// engineScript returns the shell script starting the engine. With log
+// shipping it also copies output to engineLogFile through a FIFO, keeping the
+// engine as the exec'd process so its exit code reaches the kubelet. With
+// leader election, non-leader ranks first wait for the leader's DNS record.
+func engineScript(llmCluster *servingv1alpha1.LLMCluster) string {
+	entrypoint := strings.Join(buildInferenceCommand(&llmCluster.Spec).Entrypoint, " ")
+	engine := fmt.Sprintf(`exec %s $(cat %s/%s) "$@"`, entrypoint, configMountPath, engineArgsKey)
+	if llmCluster.Spec.Monitoring.LogShipping.Enabled {
+		engine = fmt.Sprintf(`mkfifo /tmp/engine.out; tee -a %s < /tmp/engine.out & %s > /tmp/engine.out 2>&1`, engineLogFile, engine)
+	}
+
+	// Workers wait for the leader (rank 0) to be resolvable before joining
+	coordination := llmCluster.Spec.Coordination
+	if coordination.Enabled && coordination.LeaderElection && llmCluster.Spec.Replicas > 1 {
+		engine = `if [ "$NODE_RANK" != "0" ]; then until getent hosts "$MASTER_ADDR" >/dev/null; do sleep 2; done; fi; ` + engine
+	}
+	return engine
+}
+
+// logShippingContainer returns the fluent-bit sidecar tailing engineLogFile
+func logShippingContainer(llmCluster *servingv1alpha1.LLMCluster) corev1.Container {
+	cfg := llmCluster.Spec.Monitoring.LogShipping
+	image := cfg.Image
+	if image == "" {
+		image = defaultLogShippingImage
+	}
+	output := cfg.Output
+	if output == "" {
+		output = defaultLogShippingOutput
+	}
+
+	args := []string{
+		"-i", "tail", "-p", "path=" + engineLogFile, "-p", "tag=" + llmCluster.Name,
+		"-o", output, "-m", "*",
+	}
+	keys := make([]string, 0, len(cfg.OutputProperties))
+	for k := range cfg.OutputProperties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "-p", fmt.Sprintf("%s=%s", k, cfg.OutputProperties[k]))
+	}
+
+	return corev1.Container{
+		Name:    "log-shipper",
+		Image:   image,
+		Command: []string{"/fluent-bit/bin/fluent-bit"},
+		Args:    args,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "engine-logs", MountPath: engineLogDir, ReadOnly: true},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+		},
+	}
+}
+
+// inferenceResources merges Spec.Resources with the GPU count from
+// GPUsPerPod, which is set in both requests and limits (extended resources
+// cannot be overcommitted)
+func inferenceResources(llmCluster *servingv1alpha1.LLMCluster) corev1.ResourceRequirements {
+	gpus := *resource.NewQuantity(int64(llmCluster.Spec.GPUsPerPod), resource.DecimalSI)
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{gpuResourceName: gpus},
+		Limits:   corev1.ResourceList{gpuResourceName: gpus},
+	}
+	for name, q := range llmCluster.Spec.Resources.Requests {
+		if name != gpuResourceName {
+			resources.Requests[name] = q
+		}
+	}
+	for name, q := range llmCluster.Spec.Resources.Limits {
+		if name != gpuResourceName {
+			resources.Limits[name] = q
+		}
+	}
+	return resources
+}
+
+// estimateHourlyCost returns gpus × the GPU-hour rate for the cluster's GPU
+// type, formatted with two decimals, or "" when no cost ConfigMap is
+// configured or it has no matching rate. gpus is the live count reported in
+// status, which follows the HPA rather than Spec.Replicas.
+func (r *LLMClusterReconciler) estimateHourlyCost(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster, gpus int) (string, error) {
+	if r.CostConfigMap.Name == "" {
+		return "", nil
+	}
+
+	var rates corev1.ConfigMap
+	if err := r.Get(ctx, r.CostConfigMap, &rates); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	rate, ok := rates.Data[gpuType(llmCluster)]
+	if !ok {
+		rate, ok = rates.Data[defaultCostRateKey]
+	}
+	if !ok {
+		return "", nil
+	}
+	perGPUHour, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+	if err != nil {
+		return "", fmt.Errorf("cost configmap %s: invalid rate %q: %w", r.CostConfigMap, rate, err)
+	}
+
+	return strconv.FormatFloat(float64(gpus)*perGPUHour, 'f', 2, 64), nil
+}
+
+// gpuType returns the GPU type the cluster is pinned to by node selector
+func gpuType(llmCluster *servingv1alpha1.LLMCluster) string {
+	if t := llmCluster.Spec.Scheduling.NodeSelector[gpuTypeLabel]; t != "" {
+		return t
+	}
+	return llmCluster.Spec.Scheduling.NodeSelector[gpuProductLabel]
+}
+
+// startupTimeoutByModelSize is how long a model of each size category
+// may take to download and load before the startup probe gives up
+var startupTimeoutByModelSize = map[string]int{
+	"8B":   10 * 60,
+	"13B":  15 * 60,
+	"70B":  30 * 60,
+	"405B": 60 * 60,
+}
+
+// healthProbe returns a probe on the engine's /health endpoint
+func healthProbe(llmCluster *servingv1alpha1.LLMCluster, periodSeconds, failureThreshold int32) *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   "/health",
+				Port:   intstr.FromString(inferencePortName(llmCluster)),
+				Scheme: probeScheme(llmCluster),
+			},
+		},
+		PeriodSeconds:    periodSeconds,
+		FailureThreshold: failureThreshold,
+	}
+}
+
+// startupFailureThreshold converts the model load timeout (Spec.Probes or
+// the ModelSize default) into startup probe failures
+func startupFailureThreshold(llmCluster *servingv1alpha1.LLMCluster) int32 {
+	timeout := llmCluster.Spec.Probes.StartupTimeoutSeconds
+	if timeout == 0 {
+		timeout = startupTimeoutByModelSize[llmCluster.Spec.ModelSize]
+	}
+	if timeout == 0 {
+		timeout = defaultStartupTimeoutSeconds
+	}
+	return int32((timeout + startupProbePeriodSeconds - 1) / startupProbePeriodSeconds)
+}
+
+// readinessPeriodSeconds returns the readiness probe interval
+func readinessPeriodSeconds(llmCluster *servingv1alpha1.LLMCluster) int32 {
+	if period := llmCluster.Spec.Probes.ReadinessPeriodSeconds; period > 0 {
+		return int32(period)
+	}
+	return 10
+}
+
+// livenessProbe returns the engine's liveness probe. Unless set, its failure
+// threshold covers MaxRequestSeconds, so the engine is only restarted once
+// /health has failed for longer than any request should take.
+func livenessProbe(llmCluster *servingv1alpha1.LLMCluster) *corev1.Probe {
+	probes := llmCluster.Spec.Probes
+	period := probes.LivenessPeriodSeconds
+	if period <= 0 {
+		period = defaultLivenessPeriodSeconds
+	}
+	if probes.LivenessFailureThreshold > 0 {
+		return healthProbe(llmCluster, int32(period), int32(probes.LivenessFailureThreshold))
+	}
+
+	maxRequest := probes.MaxRequestSeconds
+	if maxRequest <= 0 {
+		maxRequest = defaultMaxRequestSeconds
+	}
+	threshold := (maxRequest + period - 1) / period
+	if threshold < minLivenessFailureThreshold {
+		threshold = minLivenessFailureThreshold
+	}
+	return healthProbe(llmCluster, int32(period), int32(threshold))
+}
+
+// clusterReady reports whether the cluster can serve, with the Ready
+// condition's reason and message. PerReplica needs every pod Ready. Rank0 is
+// for tensor-parallel groups where only rank 0 runs the HTTP server: pod-0
+// must be Ready and every rank's pod Running so the process group is whole.
+func (r *LLMClusterReconciler) clusterReady(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster, replicas, readyReplicas int32) (bool, string, string, error) {
+	if replicas == 0 {
+		return false, "ScaledToZero", "Scaled to zero while idle; the HPA scales up when its external metric rises above zero", nil
+	}
+	if llmCluster.Spec.Probes.ReadinessAggregation != readinessRank0 {
+		if readyReplicas == replicas {
+			return true, "AllPodsReady", fmt.Sprintf("All %d replicas are ready", readyReplicas), nil
+		}
+		return false, "PodsNotReady", fmt.Sprintf("%d/%d pods ready", readyReplicas, replicas), nil
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(llmCluster.Namespace),
+		client.MatchingLabels{"app": llmCluster.Name}); err != nil {
+		return false, "PodsNotReady", "unable to list pods", err
+	}
+
+	rank0Ready := false
+	var running int32
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodRunning {
+			running++
+		}
+		if pod.Name == llmCluster.Name+"-0" {
+			rank0Ready = podReady(pod)
+		}
+	}
+
+	switch {
+	case running < replicas:
+		return false, "RanksNotRunning", fmt.Sprintf("%d/%d ranks running", running, replicas), nil
+	case !rank0Ready:
+		return false, "Rank0NotReady", fmt.Sprintf("rank 0 (pod %s-0) is not ready", llmCluster.Name), nil
+	}
+	return true, "Rank0Ready", fmt.Sprintf("Rank 0 is ready and all %d ranks are running", replicas), nil
+}
+
+// deleteOwned removes the named child resource left over from a feature that
+// has been switched off. Objects this LLMCluster doesn't control are left
+// alone, and a missing object is not an error.
+func (r *LLMClusterReconciler) deleteOwned(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster, obj client.Object, name, kind string) error {
+	if err := r.Get(ctx, client.ObjectKey{Namespace: llmCluster.Namespace, Name: name}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(obj, llmCluster) {
+		return nil
+	}
+	if err := r.Delete(ctx, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	r.recordWrite(llmCluster, "Deleted", "delete", kind+" (disabled in spec)")
+	return nil
+}
+
+// recordWrite emits a Normal Event for a write to a child object, reading
+// "<reason> <what>" (e.g. "Created StatefulSet"). Under a dry run nothing
+// was written, so it reads "Would <verb> <what>" with reason DryRun instead.
+func (r *LLMClusterReconciler) recordWrite(llmCluster *servingv1alpha1.LLMCluster, reason, verb, what string) {
+	if _, dryRun := r.Client.(dryRunClient); dryRun {
+		r.Recorder.Eventf(llmCluster, corev1.EventTypeNormal, "DryRun", "Would %s %s", verb, what)
+		return
+	}
+	r.Recorder.Eventf(llmCluster, corev1.EventTypeNormal, reason, "%s %s", reason, what)
+}
+
+// markDegraded records a failed child resource reconcile in status: phase
+// Degraded and a Degraded condition naming the resource and the error. It
+// is cleared by the next reconcile that gets through every child resource.
+func (r *LLMClusterReconciler) markDegraded(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster, resource string, reconcileErr error) {
+	reconcileErrors.WithLabelValues(strings.ReplaceAll(resource, " ", "")).Inc()
+	r.setPhase(llmCluster, "Degraded")
+	setCondition(&llmCluster.Status.Conditions, servingv1alpha1.Condition{
+		Type:    "Degraded",
+		Status:  "True",
+		Reason:  strings.ReplaceAll(resource, " ", "") + "Failed",
+		Message: fmt.Sprintf("%s: %v", resource, reconcileErr),
+	})
+	if err := r.Status().Update(ctx, llmCluster); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to update LLMCluster status")
+	}
+}
+
+// setPhase sets Status.Phase, recording a PhaseChanged event only when the
+// phase actually changes so periodic requeues stay quiet
+func (r *LLMClusterReconciler) setPhase(llmCluster *servingv1alpha1.LLMCluster, phase string) {
+	previous := llmCluster.Status.Phase
+	if previous == phase {
+		return
+	}
+	llmCluster.Status.Phase = phase
+	if previous == "" {
+		previous = "None"
+	}
+	eventType := corev1.EventTypeNormal
+	if phase == "Degraded" || phase == "Failed" {
+		eventType = corev1.EventTypeWarning
+	}
+	r.Recorder.Eventf(llmCluster, eventType, "PhaseChanged", "Phase changed from %s to %s", previous, phase)
+}
+
+// recordScaleEvents compares the observed replica counts with the last
+// published status: ScaledUp/ScaledDown when the replica count moved,
+// BecameReady when more replicas are ready than before
+func (r *LLMClusterReconciler) recordScaleEvents(llmCluster *servingv1alpha1.LLMCluster, replicas, readyReplicas int32) {
+	previous := llmCluster.Status.Replicas
+	switch {
+	case llmCluster.Status.ObservedGeneration == 0:
+		// First status write; the Created event covers it
+	case replicas > previous:
+		r.Recorder.Eventf(llmCluster, corev1.EventTypeNormal, "ScaledUp", "Scaled from %d to %d replicas", previous, replicas)
+	case replicas < previous:
+		r.Recorder.Eventf(llmCluster, corev1.EventTypeNormal, "ScaledDown", "Scaled from %d to %d replicas", previous, replicas)
+	}
+
+	if readyReplicas > llmCluster.Status.ReadyReplicas {
+		r.Recorder.Eventf(llmCluster, corev1.EventTypeNormal, "BecameReady", "%d/%d replicas ready", readyReplicas, replicas)
+	}
+}
+
+// conditionTrue reports whether the condition of the given type is True
+func conditionTrue(conditions []servingv1alpha1.Condition, conditionType string) bool {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return condition.Status == "True"
 		}
-		return nil, err
 	}
+	return false
+}
 
-	// Update if needed
-	actualStatefulSet.Spec = desiredStatefulSet.Spec
-	if err := r.Update(ctx, &actualStatefulSet); err != nil {
-		return nil, err
+// setCondition adds or updates the condition of the same type. Its
+// LastTransitionTime only moves when Status flips, so condition age stays
+// meaningful across reconciles.
+func setCondition(conditions *[]servingv1alpha1.Condition, condition servingv1alpha1.Condition) {
+	for i := range *conditions {
+		existing := &(*conditions)[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && !existing.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		} else {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		*existing = condition
+		return
 	}
+	condition.LastTransitionTime = metav1.Now()
+	*conditions = append(*conditions, condition)
+}
 
-	return &actualStatefulSet, nil
+// detectCrashLoop returns a description of the first crash-looping inference
+// container, or "" if none is. A container crash loops when it's in
+// CrashLoopBackOff or has restarted at least the configured threshold and is
+// not ready; pods that never restart count once their engine has failed.
+func (r *LLMClusterReconciler) detectCrashLoop(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) (string, error) {
+	threshold := int32(llmCluster.Spec.HighAvailability.CrashLoopRestartThreshold)
+	if threshold <= 0 {
+		threshold = defaultCrashLoopRestartThreshold
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(llmCluster.Namespace),
+		client.MatchingLabels{"app": llmCluster.Name}); err != nil {
+		return "", err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != "inference" {
+				continue
+			}
+
+			var state string
+			switch {
+			case status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff":
+				state = "CrashLoopBackOff"
+			case pod.Spec.RestartPolicy == corev1.RestartPolicyNever:
+				if t := status.State.Terminated; t != nil && t.ExitCode != 0 {
+					state = "terminated"
+				}
+			case status.RestartCount >= threshold && !status.Ready:
+				state = "restarting"
+			}
+			if state == "" {
+				continue
+			}
+
+			message := fmt.Sprintf("pod %s container %s %s (%d restarts)", pod.Name, status.Name, state, status.RestartCount)
+			last := status.LastTerminationState.Terminated
+			if last == nil {
+				last = status.State.Terminated
+			}
+			if last != nil {
+				message += fmt.Sprintf(": last termination %s, exit code %d", last.Reason, last.ExitCode)
+				if last.Message != "" {
+					message += ": " + strings.TrimSpace(last.Message)
+				}
+			}
+			return message, nil
+		}
+	}
+	return "", nil
 }
 
-// reconcileRouterDeployment creates or updates the router Deployment
-func (r *LLMClusterReconciler) reconcileRouterDeployment(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
-	// TODO: Implement router Deployment creation
-	return nil
+// gpuCapacityShortfall counts inference pods the scheduler left Pending for
+// lack of GPUs, and estimates the GPU nodes needed to place them. Node size is
+// taken from the largest allocatable GPU count among current nodes, else
+// assumed to fit one pod; required anti-affinity also means one pod per node.
+func (r *LLMClusterReconciler) gpuCapacityShortfall(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) (int, int, error) {
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(llmCluster.Namespace),
+		client.MatchingLabels{"app": llmCluster.Name}); err != nil {
+		return 0, 0, err
+	}
+
+	pending := 0
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodPending {
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse &&
+				cond.Reason == corev1.PodReasonUnschedulable &&
+				strings.Contains(cond.Message, "Insufficient "+string(gpuResourceName)) {
+				pending++
+			}
+		}
+	}
+	if pending == 0 {
+		return 0, 0, nil
+	}
+
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		return 0, 0, err
+	}
+	var gpusPerNode int64
+	for _, node := range nodes.Items {
+		if gpus, ok := node.Status.Allocatable[gpuResourceName]; ok && gpus.Value() > gpusPerNode {
+			gpusPerNode = gpus.Value()
+		}
+	}
+
+	podsPerNode := 1
+	if gpusPerPod := int64(llmCluster.Spec.GPUsPerPod); gpusPerPod > 0 && gpusPerNode >= gpusPerPod &&
+		antiAffinityPolicy(llmCluster) != antiAffinityRequired {
+		podsPerNode = int(gpusPerNode / gpusPerPod)
+	}
+	return pending, (pending + podsPerNode - 1) / podsPerNode, nil
 }
 
-// reconcileQueueDeployment creates or updates the queue Deployment
-func (r *LLMClusterReconciler) reconcileQueueDeployment(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
-	// TODO: Implement queue Deployment creation
+// drainCordonedNodes evicts one inference pod running on a cordoned node per
+// reconcile, so the StatefulSet recreates it on a schedulable node. Evictions
+// honor the PDB (a refused eviction is retried on a later reconcile), and
+// nothing is evicted while a pod elsewhere is not ready or terminating.
+func (r *LLMClusterReconciler) drainCordonedNodes(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(llmCluster.Namespace),
+		client.MatchingLabels{"app": llmCluster.Name}); err != nil {
+		return err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	var victim *corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !pod.DeletionTimestamp.IsZero() {
+			// A replica is already moving; one at a time
+			return nil
+		}
+
+		cordoned := false
+		if pod.Spec.NodeName != "" {
+			var node corev1.Node
+			if err := r.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, &node); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			cordoned = node.Spec.Unschedulable
+		}
+
+		switch {
+		case cordoned && victim == nil:
+			victim = pod
+		case !cordoned && !podReady(pod):
+			// Let in-flight restarts settle before taking down another replica
+			return nil
+		}
+	}
+	if victim == nil {
+		return nil
+	}
+
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: victim.Name, Namespace: victim.Namespace},
+	}
+	if err := r.SubResource("eviction").Create(ctx, victim, eviction); err != nil {
+		if errors.IsTooManyRequests(err) {
+			r.Recorder.Eventf(llmCluster, corev1.EventTypeWarning, "EvictionBlocked",
+				"Pod %s on cordoned node %s not evicted: disruption budget exhausted", victim.Name, victim.Spec.NodeName)
+			return nil
+		}
+		return err
+	}
+	r.Recorder.Eventf(llmCluster, corev1.EventTypeNormal, "PodEvicted",
+		"Evicted pod %s from cordoned node %s", victim.Name, victim.Spec.NodeName)
 	return nil
 }
 
-// reconcileServices creates or updates Services
-func (r *LLMClusterReconciler) reconcileServices(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
-	// TODO: Implement Service creation
-	return nil
+// podReady reports whether the pod's Ready condition is True
+func podReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
 }
 
-// reconcileConfigMaps creates or updates ConfigMaps
-func (r *LLMClusterReconciler) reconcileConfigMaps(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
-	// TODO: Implement ConfigMap creation
-	return nil
+// antiAffinityPolicy returns the normalized Spec.Scheduling.PodAntiAffinity
+func antiAffinityPolicy(llmCluster *servingv1alpha1.LLMCluster) string {
+	policy := strings.ToLower(llmCluster.Spec.Scheduling.PodAntiAffinity)
+	if policy == "" {
+		return antiAffinityPreferred
+	}
+	return policy
 }
 
-// reconcileHPA creates or updates HorizontalPodAutoscaler
-func (r *LLMClusterReconciler) reconcileHPA(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
-	desiredHPA := &autoscalingv2.HorizontalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-hpa", llmCluster.Name),
-			Namespace: llmCluster.Namespace,
+// podAntiAffinity returns the hostname anti-affinity between this cluster's
+// pods: hard for required, soft for preferred (so clusters with fewer nodes
+// than replicas still schedule), nil for none
+func podAntiAffinity(llmCluster *servingv1alpha1.LLMCluster) *corev1.Affinity {
+	term := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": llmCluster.Name},
 		},
-		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
-			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
-				APIVersion: "apps/v1",
-				Kind:       "StatefulSet",
-				Name:       llmCluster.Name,
+		TopologyKey: "kubernetes.io/hostname",
+	}
+
+	switch antiAffinityPolicy(llmCluster) {
+	case antiAffinityRequired:
+		return &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{term},
 			},
-			MinReplicas: func() *int32 { i := int32(llmCluster.Spec.Autoscaling.MinReplicas); return &i }(),
-			MaxReplicas: int32(llmCluster.Spec.Autoscaling.MaxReplicas),
-			Metrics: []autoscalingv2.MetricSpec{
-				{
-					Type: autoscalingv2.ResourceMetricSourceType,
-					Resource: &autoscalingv2.ResourceMetricSource{
-						Name: corev1.ResourceCPU,
-						Target: autoscalingv2.MetricTarget{
-							Type:               autoscalingv2.UtilizationMetricType,
-							AverageUtilization: func() *int32 { i := int32(llmCluster.Spec.Autoscaling.TargetCPUUtilizationPercentage); return &i }(),
-						},
-					},
+		}
+	case antiAffinityPreferred:
+		return &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+					{Weight: 100, PodAffinityTerm: term},
 				},
 			},
-		},
+		}
+	default:
+		return nil
 	}
+}
 
-	if err := ctrl.SetControllerReference(llmCluster, desiredHPA, r.Scheme); err != nil {
+// checkAntiAffinity emits a Warning when the anti-affinity policy isn't
+// met: required leaves pods unschedulable, preferred fell back to sharing
+// a node between replicas
+func (r *LLMClusterReconciler) checkAntiAffinity(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
+	policy := antiAffinityPolicy(llmCluster)
+	if policy == antiAffinityNone {
+		return nil
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(llmCluster.Namespace),
+		client.MatchingLabels{"app": llmCluster.Name}); err != nil {
 		return err
 	}
 
-	// Create or update
-	var actualHPA autoscalingv2.HorizontalPodAutoscaler
-	err := r.Get(ctx, client.ObjectKeyFromObject(desiredHPA), &actualHPA)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			if err := r.Create(ctx, desiredHPA); err != nil {
-				return err
+	podsByNode := map[string][]string{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" {
+			podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod.Name)
+			continue
+		}
+		if policy != antiAffinityRequired {
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse &&
+				cond.Reason == corev1.PodReasonUnschedulable {
+				r.Recorder.Eventf(llmCluster, corev1.EventTypeWarning, "AntiAffinityUnsatisfiable",
+					"Pod %s is unschedulable under required pod anti-affinity (%s); "+
+						"set scheduling.podAntiAffinity=preferred to allow replicas to share nodes",
+					pod.Name, cond.Message)
 			}
-			r.Recorder.Event(llmCluster, corev1.EventTypeNormal, "Created", "Created HPA")
-			return nil
 		}
-		return err
 	}
 
-	actualHPA.Spec = desiredHPA.Spec
-	return r.Update(ctx, &actualHPA)
+	if policy == antiAffinityPreferred {
+		nodes := make([]string, 0, len(podsByNode))
+		for node := range podsByNode {
+			nodes = append(nodes, node)
+		}
+		sort.Strings(nodes)
+		for _, node := range nodes {
+			if names := podsByNode[node]; len(names) > 1 {
+				r.Recorder.Eventf(llmCluster, corev1.EventTypeWarning, "AntiAffinityNotSatisfied",
+					"Pods %s share node %s (preferred anti-affinity)", strings.Join(names, ", "), node)
+			}
+		}
+	}
+	return nil
 }
 
-// reconcilePDB creates or updates PodDisruptionBudget
-func (r *LLMClusterReconciler) reconcilePDB(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
-	// TODO: Implement PDB creation
-	return nil
+// schedulerName returns Spec.Scheduling.SchedulerName, else GPUSchedulerName
+// for clusters that span several GPUs (GPUsPerPod > 1 or Replicas > 1),
+// else the default scheduler. The default is spelled out so switching away
+// from a custom scheduler shows up as a template change.
+func (r *LLMClusterReconciler) schedulerName(llmCluster *servingv1alpha1.LLMCluster) string {
+	if name := llmCluster.Spec.Scheduling.SchedulerName; name != "" {
+		return name
+	}
+	if r.GPUSchedulerName != "" && (llmCluster.Spec.GPUsPerPod > 1 || llmCluster.Spec.Replicas > 1) {
+		return r.GPUSchedulerName
+	}
+	return corev1.DefaultSchedulerName
 }
 
-// reconcileNetworkPolicy creates or updates NetworkPolicy
-func (r *LLMClusterReconciler) reconcileNetworkPolicy(ctx context.Context, llmCluster *servingv1alpha1.LLMCluster) error {
-	// TODO: Implement NetworkPolicy creation
+// topologySpreadConstraints returns Spec.Scheduling.TopologySpreadConstraints,
+// defaulting an empty labelSelector to this cluster's pods
+func topologySpreadConstraints(llmCluster *servingv1alpha1.LLMCluster) []corev1.TopologySpreadConstraint {
+	var constraints []corev1.TopologySpreadConstraint
+	for _, constraint := range llmCluster.Spec.Scheduling.TopologySpreadConstraints {
+		constraint := *constraint.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": llmCluster.Name},
+			}
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints
+}
+
+// prefixCacheEnv returns the LMCache settings pointing vLLM at the shared
+// prefix cache volume
+func prefixCacheEnv(llmCluster *servingv1alpha1.LLMCluster) []corev1.EnvVar {
+	cfg := llmCluster.Spec.InferenceArgs.PrefixCaching
+	if !cfg.Enabled || cfg.SharedClaimName == "" {
+		return nil
+	}
+	size := cfg.SharedCacheSizeGB
+	if size == 0 {
+		size = defaultSharedCacheSizeGB
+	}
+	return []corev1.EnvVar{
+		{Name: "LMCACHE_LOCAL_CPU", Value: "False"},
+		{Name: "LMCACHE_LOCAL_DISK", Value: "file://" + prefixCacheMountPath + "/"},
+		{Name: "LMCACHE_MAX_LOCAL_DISK_SIZE", Value: strconv.Itoa(size)},
+	}
+}
+
+// coordinationEnv returns the torch.distributed rendezvous env for tensor
+// parallelism across pods when Spec.Coordination is enabled: pod-0 is the
+// master, each pod's rank is its StatefulSet ordinal
+func coordinationEnv(llmCluster *servingv1alpha1.LLMCluster) []corev1.EnvVar {
+	if !llmCluster.Spec.Coordination.Enabled {
+		return nil
+	}
+
+	podIndex := &corev1.EnvVarSource{
+		FieldRef: &corev1.ObjectFieldSelector{
+			FieldPath: fmt.Sprintf("metadata.labels['%s']", appsv1.PodIndexLabel),
+		},
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  "MASTER_ADDR",
+			Value: fmt.Sprintf("%s-0.%s-backend.%s.svc.cluster.local", llmCluster.Name, llmCluster.Name, llmCluster.Namespace),
+		},
+		{Name: "MASTER_PORT", Value: strconv.Itoa(masterPort)},
+		{Name: "NNODES", Value: strconv.Itoa(llmCluster.Spec.Replicas)},
+		{Name: "NODE_RANK", ValueFrom: podIndex},
+		{Name: "RANK", ValueFrom: podIndex},
+		{Name: "WORLD_SIZE", Value: strconv.Itoa(llmCluster.Spec.Replicas * llmCluster.Spec.GPUsPerPod)},
+	}
+}
+
+// hfTokenEnv returns HUGGING_FACE_HUB_TOKEN from the configured Secret, so
+// gated models can be downloaded
+func hfTokenEnv(llmCluster *servingv1alpha1.LLMCluster) []corev1.EnvVar {
+	token := llmCluster.Spec.Security.HuggingfaceToken
+	if token.SecretName == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{
+		Name: "HUGGING_FACE_HUB_TOKEN",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: token.SecretName},
+				Key:                  token.SecretKey,
+			},
+		},
+	}}
+}
+
+// ncclEnv returns the distributed backend env: operator defaults merged with
+// Spec.Coordination.NCCL, sorted by name for a stable pod template
+func ncclEnv(llmCluster *servingv1alpha1.LLMCluster) []corev1.EnvVar {
+	merged := make(map[string]string, len(defaultNCCLEnv)+len(llmCluster.Spec.Coordination.NCCL))
+	for k, v := range defaultNCCLEnv {
+		merged[k] = v
+	}
+	for k, v := range llmCluster.Spec.Coordination.NCCL {
+		merged[k] = v
+	}
+
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	env := make([]corev1.EnvVar, 0, len(keys))
+	for _, k := range keys {
+		env = append(env, corev1.EnvVar{Name: k, Value: merged[k]})
+	}
+	return env
+}
+
+// containerPorts returns the inference container ports, adding a dedicated
+// metrics port when it differs from the inference port
+func containerPorts(llmCluster *servingv1alpha1.LLMCluster) []corev1.ContainerPort {
+	ports := []corev1.ContainerPort{
+		{Name: inferencePortName(llmCluster), ContainerPort: int32(inferencePort(&llmCluster.Spec))},
+	}
+	if port := metricsPort(llmCluster); port != inferencePort(&llmCluster.Spec) {
+		ports = append(ports, corev1.ContainerPort{Name: "metrics", ContainerPort: int32(port)})
+	}
+	return ports
+}
+
+// metricsPort returns the engine metrics port
+func metricsPort(llmCluster *servingv1alpha1.LLMCluster) int {
+	if llmCluster.Spec.Monitoring.MetricsPort != 0 {
+		return llmCluster.Spec.Monitoring.MetricsPort
+	}
+	return inferencePort(&llmCluster.Spec)
+}
+
+// metricsPortName returns the named container port serving /metrics
+func metricsPortName(llmCluster *servingv1alpha1.LLMCluster) string {
+	if metricsPort(llmCluster) != inferencePort(&llmCluster.Spec) {
+		return "metrics"
+	}
+	return inferencePortName(llmCluster)
+}
+
+// probeScheme returns the inference probe scheme: Spec.Network.ProbeScheme
+// if set, otherwise HTTPS when engine TLS is enabled
+func probeScheme(llmCluster *servingv1alpha1.LLMCluster) corev1.URIScheme {
+	if llmCluster.Spec.Network.ProbeScheme != "" {
+		return corev1.URIScheme(llmCluster.Spec.Network.ProbeScheme)
+	}
+	if llmCluster.Spec.Network.TLS.Enabled {
+		return corev1.URISchemeHTTPS
+	}
+	return corev1.URISchemeHTTP
+}
+
+// inferencePortName names the inference port after its scheme so the
+// container, Services, probes and monitors all agree
+func inferencePortName(llmCluster *servingv1alpha1.LLMCluster) string {
+	if probeScheme(llmCluster) == corev1.URISchemeHTTPS {
+		return "https"
+	}
+	return "http"
+}
+
+// tlsArgs returns the engine flags that enable HTTPS
+func tlsArgs(llmCluster *servingv1alpha1.LLMCluster) []string {
+	if !llmCluster.Spec.Network.TLS.Enabled {
+		return nil
+	}
+	return []string{
+		fmt.Sprintf("--ssl-certfile=%s/tls.crt", tlsMountPath),
+		fmt.Sprintf("--ssl-keyfile=%s/tls.key", tlsMountPath),
+	}
+}
+
+// frontendServiceName returns the Service that receives client traffic:
+// the router when enabled, otherwise the backend Service
+func frontendServiceName(llmCluster *servingv1alpha1.LLMCluster) string {
+	if llmCluster.Spec.Router.Enabled {
+		return fmt.Sprintf("%s-router", llmCluster.Name)
+	}
+	return llmCluster.Name
+}
+
+// servicePort returns the client-facing Service port, by default the
+// container port
+func servicePort(llmCluster *servingv1alpha1.LLMCluster) int {
+	if llmCluster.Spec.Network.Port != 0 {
+		return llmCluster.Spec.Network.Port
+	}
+	return inferencePort(&llmCluster.Spec)
+}
+
+// inferencePort returns the port the engine listens on in the container
+func inferencePort(spec *servingv1alpha1.LLMClusterSpec) int {
+	if spec.Network.ContainerPort != 0 {
+		return spec.Network.ContainerPort
+	}
+	return defaultInferencePort
+}
+
+// controllerOptions applies the concurrency and error backoff settings
+func (r *LLMClusterReconciler) controllerOptions() controller.Options {
+	options := controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}
+	if r.BackoffBase > 0 {
+		options.RateLimiter = workqueue.NewItemExponentialFailureRateLimiter(r.BackoffBase, r.BackoffMax)
+	}
+	return options
+}
+
+// registerMetrics adds the reconcile metrics to the controller-runtime
+// registry, tolerating a second registration
+func registerMetrics() error {
+	for _, c := range []prometheus.Collector{reconcileTotal, reconcileErrors, reconcileDuration, readyReplicasGauge} {
+		if err := metrics.Registry.Register(c); err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				return fmt.Errorf("registering metrics: %w", err)
+			}
+		}
+	}
 	return nil
 }
 
+// llmClusterValidator rejects invalid specs at admission with the same
+// validateSpec checks Reconcile runs, so kubectl apply fails immediately
+// instead of the cluster going Failed later. Checks that read other objects
+// (the prefix cache claim) stay in Reconcile.
+//
+// +kubebuilder:webhook:path=/validate-serving-ai-v1alpha1-llmcluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=serving.ai,resources=llmclusters,verbs=create;update,versions=v1alpha1,name=vllmcluster.serving.ai,admissionReviewVersions=v1
+type llmClusterValidator struct {
+	reconciler *LLMClusterReconciler
+}
+
+func (v *llmClusterValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	llmCluster, ok := obj.(*servingv1alpha1.LLMCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected an LLMCluster, got %T", obj)
+	}
+	return nil, v.reconciler.validateSpec(llmCluster)
+}
+
+// ValidateUpdate also keeps spec.model immutable: a different model is a
+// new cluster, not a rolling update. Updates that leave the spec alone
+// (finalizers, labels, deletion) are always allowed.
+func (v *llmClusterValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCluster, ok := oldObj.(*servingv1alpha1.LLMCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected an LLMCluster, got %T", oldObj)
+	}
+	llmCluster, ok := newObj.(*servingv1alpha1.LLMCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected an LLMCluster, got %T", newObj)
+	}
+	if llmCluster.Spec.Model != oldCluster.Spec.Model {
+		return nil, fmt.Errorf("model is immutable (%q -> %q); create a new LLMCluster instead",
+			oldCluster.Spec.Model, llmCluster.Spec.Model)
+	}
+	if !llmCluster.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(oldCluster.Spec, llmCluster.Spec) {
+		return nil, nil
+	}
+	return nil, v.reconciler.validateSpec(llmCluster)
+}
+
+func (v *llmClusterValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// SetupWebhookWithManager serves llmClusterValidator on the manager's
+// webhook server
+func (r *LLMClusterReconciler) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&servingv1alpha1.LLMCluster{}).
+		WithValidator(&llmClusterValidator{reconciler: r}).
+		Complete()
+}
+
+// clustersForWeightsConfigMap maps a ConfigMap to the LLMClusters using it
+// as their router weight table
+func (r *LLMClusterReconciler) clustersForWeightsConfigMap(ctx context.Context, obj client.Object) []ctrl.Request {
+	var clusters servingv1alpha1.LLMClusterList
+	if err := r.List(ctx, &clusters, client.InNamespace(obj.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "list LLMClusters for router weights ConfigMap", "configMap", obj.GetName())
+		return nil
+	}
+	var requests []ctrl.Request
+	for _, cluster := range clusters.Items {
+		if cluster.Spec.Router.Enabled && cluster.Spec.Router.WeightsConfigMap == obj.GetName() {
+			requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&cluster)})
+		}
+	}
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager
 func (r *LLMClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := registerMetrics(); err != nil {
+		return err
+	}
+
+	// Every generated object is watched, so an edit or deletion is repaired
+	// right away rather than at the next periodic resync
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&servingv1alpha1.LLMCluster{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
+		Owns(&corev1.ConfigMap{}).
 		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
-		Complete(r)
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		// Router weight tables live in user-owned ConfigMaps
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.clustersForWeightsConfigMap)).
+		WithOptions(r.controllerOptions())
+
+	// Optional third-party kinds can only be watched when their CRD exists
+	for _, gvk := range []schema.GroupVersionKind{httpRouteGVK, podMonitorGVK} {
+		installed, err := r.kindInstalled(gvk)
+		if err != nil {
+			return err
+		}
+		if installed {
+			owned := &unstructured.Unstructured{}
+			owned.SetGroupVersionKind(gvk)
+			b = b.Owns(owned)
+		}
+	}
+
+	return b.Complete(r)
 }
 
 func main() {
@@ -475,6 +3836,29 @@ func main() {
 		Development: false,
 	}
 	opts.BindFlags(flag.CommandLine)
+	var costConfigMap string
+	var drainCordonedNodes bool
+	var dryRun bool
+	var enableWebhook bool
+	var webhookPort int
+	var webhookCertDir string
+	var gpuSchedulerName string
+	var maxConcurrentReconciles int
+	var backoffBase, backoffMax time.Duration
+	var modelSizeGPUs string
+	var shmHostPathPrefixes string
+	flag.StringVar(&modelSizeGPUs, "model-size-min-gpus", "", "Overrides of the minimum GPUs per modelSize, e.g. 70B=4,405B=32")
+	flag.BoolVar(&dryRun, "dry-run", false, "Send child object writes as server-side dry runs and log the planned changes; only status is written")
+	flag.StringVar(&gpuSchedulerName, "gpu-scheduler-name", "", "Scheduler for clusters with gpusPerPod > 1 or replicas > 1 that don't set scheduling.schedulerName (e.g. gpu-spread)")
+	flag.BoolVar(&drainCordonedNodes, "drain-cordoned-nodes", false, "Evict inference pods from cordoned nodes so they reschedule elsewhere")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1, "LLMClusters reconciled in parallel")
+	flag.DurationVar(&backoffBase, "error-backoff-base", time.Second, "First requeue delay after a failed reconcile, doubled on each further failure")
+	flag.DurationVar(&backoffMax, "error-backoff-max", 5*time.Minute, "Upper bound of the failed-reconcile requeue delay")
+	flag.BoolVar(&enableWebhook, "enable-webhook", false, "Serve the LLMCluster validating admission webhook (needs a serving certificate, see 09-validating-webhook.yaml)")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "Port of the admission webhook server")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs", "Directory holding the webhook server's tls.crt and tls.key")
+	flag.StringVar(&shmHostPathPrefixes, "shm-host-path-prefixes", "/dev/shm,/mnt/shm", "Comma-separated host directories under which storage.shmHostPath may lie; empty disallows shmSource hostPath")
+	flag.StringVar(&costConfigMap, "cost-configmap", "", "ConfigMap (namespace/name) of GPU-hour rates by GPU type for status.estimatedHourlyCost")
 	flag.Parse()
 
 	log := zap.New(zap.UseFlagOptions(&opts))
@@ -487,6 +3871,7 @@ func main() {
 		Scheme:                 runtime.NewScheme(),
 		Metrics:                server.Options{BindAddress: ":8080"},
 		HealthProbeBindAddress: ":8081",
+		WebhookServer:          webhook.NewServer(webhook.Options{Port: webhookPort, CertDir: webhookCertDir}),
 		// Leader election: only one replica runs the reconcile loop
 		LeaderElection:          true,
 		LeaderElectionID:        "llmcluster-operator",
@@ -512,12 +3897,60 @@ func main() {
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorderFor("llmcluster-operator"),
+
+		DrainCordonedNodes:      drainCordonedNodes,
+		GPUSchedulerName:        gpuSchedulerName,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		BackoffBase:             backoffBase,
+		BackoffMax:              backoffMax,
+	}
+	if maxConcurrentReconciles < 1 || backoffMax < backoffBase {
+		log.Error(fmt.Errorf("invalid --max-concurrent-reconciles %d or --error-backoff-max %s below --error-backoff-base %s",
+			maxConcurrentReconciles, backoffMax, backoffBase), "unable to configure controller")
+		os.Exit(1)
+	}
+	if dryRun {
+		log.Info("dry-run mode: no changes are applied except LLMCluster status")
+		reconciler.Client = newDryRunClient(reconciler.Client)
+	}
+	if modelSizeGPUs != "" {
+		minGPUs, err := parseMinGPUsByModelSize(modelSizeGPUs)
+		if err != nil {
+			log.Error(err, "expected size=gpus")
+			os.Exit(1)
+		}
+		reconciler.MinGPUsByModelSize = minGPUs
+	}
+	for _, prefix := range strings.Split(shmHostPathPrefixes, ",") {
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			continue
+		}
+		if !path.IsAbs(prefix) || prefix == "/" {
+			log.Error(fmt.Errorf("invalid --shm-host-path-prefixes entry %q", prefix), "expected an absolute directory other than /")
+			os.Exit(1)
+		}
+		reconciler.ShmHostPathPrefixes = append(reconciler.ShmHostPathPrefixes, path.Clean(prefix))
+	}
+	if costConfigMap != "" {
+		namespace, name, ok := strings.Cut(costConfigMap, "/")
+		if !ok || namespace == "" || name == "" {
+			log.Error(fmt.Errorf("invalid --cost-configmap %q", costConfigMap), "expected namespace/name")
+			os.Exit(1)
+		}
+		reconciler.CostConfigMap = client.ObjectKey{Namespace: namespace, Name: name}
 	}
 
 	if err := reconciler.SetupWithManager(mgr); err != nil {
 		log.Error(err, "unable to create controller")
 		os.Exit(1)
 	}
+	if enableWebhook {
+		if err := reconciler.SetupWebhookWithManager(mgr); err != nil {
+			log.Error(err, "unable to create webhook")
+			os.Exit(1)
+		}
+	}
 
 	// ============================================
 	// 5. Add health checks
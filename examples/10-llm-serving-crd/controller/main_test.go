@@ -0,0 +1,3178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	servingv1alpha1 "github.com/example/llmcluster-operator/api/v1alpha1"
+)
+
+// newTestCluster returns a valid single-pod vLLM LLMCluster with 8 GPUs.
+func newTestCluster() *servingv1alpha1.LLMCluster {
+	return &servingv1alpha1.LLMCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama", UID: "llama-uid"},
+		Spec: servingv1alpha1.LLMClusterSpec{
+			Model:           "meta-llama/Meta-Llama-3-8B",
+			Replicas:        1,
+			GPUsPerPod:      8,
+			InferenceEngine: engineVLLM,
+		},
+	}
+}
+
+// newTestReconciler returns a reconciler on a fake client holding objects,
+// and a count of the Update calls it receives.
+func newTestReconciler(objects ...client.Object) (*LLMClusterReconciler, *int) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = servingv1alpha1.AddToScheme(scheme)
+
+	updates := 0
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				updates++
+				return c.Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	return &LLMClusterReconciler{Client: c, Scheme: scheme, Recorder: record.NewFakeRecorder(100)}, &updates
+}
+
+// newGatewayTestReconciler returns a reconciler whose fake client serves the
+// Gateway API kinds, holding objects.
+func newGatewayTestReconciler(objects ...client.Object) *LLMClusterReconciler {
+	r, _ := newTestReconciler()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range []schema.GroupVersionKind{httpRouteGVK, gatewayGVK} {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithRESTMapper(mapper).WithObjects(objects...).Build()
+	return r
+}
+
+// newTestGateway returns a Gateway with one listener per name → protocol.
+func newTestGateway(listeners map[string]string) *unstructured.Unstructured {
+	var items []interface{}
+	for name, protocol := range listeners {
+		items = append(items, map[string]interface{}{"name": name, "protocol": protocol, "port": int64(443)})
+	}
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(gatewayGVK)
+	gateway.SetNamespace("infra")
+	gateway.SetName("public")
+	_ = unstructured.SetNestedSlice(gateway.Object, items, "spec", "listeners")
+	return gateway
+}
+
+func hasArg(args []string, want string) bool {
+	for _, arg := range args {
+		if arg == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTensorParallelSizeDefault(t *testing.T) {
+	r := &LLMClusterReconciler{}
+	llmCluster := newTestCluster()
+
+	// Unset: derived from replicas × gpusPerPod and never written back, so
+	// a later replicas edit follows along and still validates
+	if got := tensorParallelSize(&llmCluster.Spec); got != 8 {
+		t.Errorf("unset tensorParallelSize = %d, want 8", got)
+	}
+	llmCluster.Spec.Replicas = 2
+	if err := r.validateSpec(llmCluster); err != nil {
+		t.Errorf("validateSpec after replicas edit: %v", err)
+	}
+	if args := buildInferenceCommand(&llmCluster.Spec).Args; !hasArg(args, "--tensor-parallel-size=16") {
+		t.Errorf("args %s lack --tensor-parallel-size=16", strings.Join(args, " "))
+	}
+	if llmCluster.Spec.TensorParallelSize != 0 {
+		t.Errorf("spec.tensorParallelSize was set to %d", llmCluster.Spec.TensorParallelSize)
+	}
+
+	// Set: used as is, and still checked against the product
+	llmCluster.Spec.TensorParallelSize = 16
+	if got := tensorParallelSize(&llmCluster.Spec); got != 16 {
+		t.Errorf("explicit tensorParallelSize = %d, want 16", got)
+	}
+	llmCluster.Spec.TensorParallelSize = 8
+	if err := r.validateSpec(llmCluster); err == nil {
+		t.Error("validateSpec accepted tensorParallelSize 8 with 2 × 8 GPUs")
+	}
+}
+
+// newTestStatefulSet returns a StatefulSet with every field statefulSetChanged
+// checks explicitly set.
+func newTestStatefulSet() *appsv1.StatefulSet {
+	replicas := int32(2)
+	probe := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromString("http")}}}
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"app": "llama", labelLoading: "true"},
+					Annotations: map[string]string{annotationConfigChecksum: "abc"},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: "llama",
+					Containers: []corev1.Container{{
+						Name:  "inference",
+						Image: "vllm/vllm-openai:latest",
+						Args:  []string{"--model=llama"},
+						Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8000}, {Name: "metrics", ContainerPort: 9090}},
+						Resources: corev1.ResourceRequirements{
+							Limits:   corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("8"), corev1.ResourceMemory: resource.MustParse("64Gi")},
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8"), corev1.ResourceMemory: resource.MustParse("64Gi")},
+						},
+						StartupProbe:   probe.DeepCopy(),
+						ReadinessProbe: probe.DeepCopy(),
+						LivenessProbe:  probe.DeepCopy(),
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestStatefulSetChanged(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(desired *appsv1.StatefulSet)
+		changed bool
+	}{
+		{name: "unchanged", edit: func(*appsv1.StatefulSet) {}},
+		{name: "serviceAccountName cleared", edit: func(s *appsv1.StatefulSet) { s.Spec.Template.Spec.ServiceAccountName = "" }, changed: true},
+		{name: "limit removed", edit: func(s *appsv1.StatefulSet) {
+			delete(s.Spec.Template.Spec.Containers[0].Resources.Limits, corev1.ResourceMemory)
+		}, changed: true},
+		{name: "requests cleared", edit: func(s *appsv1.StatefulSet) { s.Spec.Template.Spec.Containers[0].Resources.Requests = nil }, changed: true},
+		{name: "template label removed", edit: func(s *appsv1.StatefulSet) { delete(s.Spec.Template.Labels, labelLoading) }, changed: true},
+		{name: "template annotation removed", edit: func(s *appsv1.StatefulSet) { s.Spec.Template.Annotations = nil }, changed: true},
+		{name: "liveness probe removed", edit: func(s *appsv1.StatefulSet) { s.Spec.Template.Spec.Containers[0].LivenessProbe = nil }, changed: true},
+		{name: "startup probe removed", edit: func(s *appsv1.StatefulSet) { s.Spec.Template.Spec.Containers[0].StartupProbe = nil }, changed: true},
+		{name: "port removed", edit: func(s *appsv1.StatefulSet) {
+			s.Spec.Template.Spec.Containers[0].Ports = s.Spec.Template.Spec.Containers[0].Ports[:1]
+		}, changed: true},
+		{name: "image changed", edit: func(s *appsv1.StatefulSet) { s.Spec.Template.Spec.Containers[0].Image = "vllm/vllm-openai:v0.6.0" }, changed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := newTestStatefulSet()
+			// API server defaults on the live object never count as changes
+			container := &actual.Spec.Template.Spec.Containers[0]
+			for _, probe := range []*corev1.Probe{container.StartupProbe, container.ReadinessProbe, container.LivenessProbe} {
+				probe.TimeoutSeconds, probe.PeriodSeconds, probe.SuccessThreshold, probe.FailureThreshold = 1, 10, 1, 3
+			}
+			container.Ports[0].Protocol = corev1.ProtocolTCP
+			actual.Spec.Template.Spec.DeprecatedServiceAccount = "llama"
+
+			desired := newTestStatefulSet()
+			tt.edit(desired)
+			if got := statefulSetChanged(desired, actual); got != tt.changed {
+				t.Errorf("statefulSetChanged = %v, want %v", got, tt.changed)
+			}
+		})
+	}
+}
+
+func TestReconcileStatefulSetNoOp(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	r, updates := newTestReconciler(llmCluster)
+
+	if _, err := r.reconcileStatefulSet(ctx, llmCluster); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := r.reconcileStatefulSet(ctx, llmCluster); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if *updates != 0 {
+		t.Errorf("unchanged reconcile issued %d updates", *updates)
+	}
+
+	llmCluster.Spec.Image = "vllm/vllm-openai:v0.6.0"
+	if _, err := r.reconcileStatefulSet(ctx, llmCluster); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if *updates != 1 {
+		t.Errorf("image change issued %d updates, want 1", *updates)
+	}
+}
+
+func TestRouterReplicas(t *testing.T) {
+	backends := func(active, draining int) []servingv1alpha1.RouterBackend {
+		var list []servingv1alpha1.RouterBackend
+		for i := 0; i < active+draining; i++ {
+			list = append(list, servingv1alpha1.RouterBackend{
+				Name:     fmt.Sprintf("b%d", i),
+				Service:  fmt.Sprintf("b%d", i),
+				Draining: i >= active,
+			})
+		}
+		return list
+	}
+
+	tests := []struct {
+		name     string
+		replicas int
+		router   servingv1alpha1.RouterConfig
+		want     int32
+	}{
+		{name: "static default", router: servingv1alpha1.RouterConfig{}, want: 1},
+		{name: "static", router: servingv1alpha1.RouterConfig{Replicas: 3, Backends: backends(20, 0)}, want: 3},
+		{
+			name:   "proportional",
+			router: servingv1alpha1.RouterConfig{Backends: backends(10, 0), AutoReplicas: servingv1alpha1.RouterAutoReplicas{Enabled: true, BackendsPerReplica: 3}},
+			want:   4,
+		},
+		{
+			name:   "draining backends don't count",
+			router: servingv1alpha1.RouterConfig{Backends: backends(6, 6), AutoReplicas: servingv1alpha1.RouterAutoReplicas{Enabled: true, BackendsPerReplica: 2}},
+			want:   3,
+		},
+		{
+			name:   "min",
+			router: servingv1alpha1.RouterConfig{Backends: backends(1, 0), AutoReplicas: servingv1alpha1.RouterAutoReplicas{Enabled: true}},
+			want:   defaultMinRouterReplicas,
+		},
+		{
+			name:   "max",
+			router: servingv1alpha1.RouterConfig{Backends: backends(40, 0), AutoReplicas: servingv1alpha1.RouterAutoReplicas{Enabled: true, BackendsPerReplica: 2, MaxReplicas: 5}},
+			want:   5,
+		},
+		{
+			name:     "engine pods without configured backends",
+			replicas: 12,
+			router:   servingv1alpha1.RouterConfig{AutoReplicas: servingv1alpha1.RouterAutoReplicas{Enabled: true}},
+			want:     3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			if tt.replicas > 0 {
+				llmCluster.Spec.Replicas = tt.replicas
+			}
+			llmCluster.Spec.Router = tt.router
+			if got := routerReplicas(llmCluster); got != tt.want {
+				t.Errorf("routerReplicas = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateHourlyCost(t *testing.T) {
+	rates := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "llm-system", Name: "gpu-rates"},
+		Data:       map[string]string{"H100": "4.50", defaultCostRateKey: "2"},
+	}
+	r, _ := newTestReconciler(rates)
+	r.CostConfigMap = client.ObjectKeyFromObject(rates)
+
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Scheduling.NodeSelector = map[string]string{gpuTypeLabel: "H100"}
+	// Three live replicas × 8 GPUs, whatever Spec.Replicas says
+	cost, err := r.estimateHourlyCost(context.Background(), llmCluster, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != "108.00" {
+		t.Errorf("H100 cost = %s, want 108.00", cost)
+	}
+
+	llmCluster.Spec.Scheduling.NodeSelector = nil
+	if cost, _ := r.estimateHourlyCost(context.Background(), llmCluster, 8); cost != "16.00" {
+		t.Errorf("default-rate cost = %s, want 16.00", cost)
+	}
+	if cost, _ := r.estimateHourlyCost(context.Background(), llmCluster, 0); cost != "0.00" {
+		t.Errorf("scaled-to-zero cost = %s, want 0.00", cost)
+	}
+}
+
+func TestReconcileHTTPRoute(t *testing.T) {
+	ctx := context.Background()
+	newCluster := func(sectionName string) *servingv1alpha1.LLMCluster {
+		llmCluster := newTestCluster()
+		llmCluster.Spec.Network.Gateway = servingv1alpha1.GatewayConfig{
+			ParentRef: servingv1alpha1.GatewayParentRef{Name: "public", Namespace: "infra", SectionName: sectionName},
+			Hostname:  "llm.example.com",
+			Path:      "/v1",
+		}
+		return llmCluster
+	}
+
+	r := newGatewayTestReconciler(newTestGateway(map[string]string{"web": "HTTP", "websecure": "HTTPS"}))
+	llmCluster := newCluster("web")
+	if err := r.reconcileHTTPRoute(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(httpRouteGVK)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama"}, route); err != nil {
+		t.Fatalf("HTTPRoute not created: %v", err)
+	}
+	if owner := metav1.GetControllerOf(route); owner == nil || owner.Name != "llama" {
+		t.Errorf("HTTPRoute controller = %v, want the LLMCluster", owner)
+	}
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if len(hostnames) != 1 || hostnames[0] != "llm.example.com" {
+		t.Errorf("hostnames = %v", hostnames)
+	}
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	if len(parentRefs) != 1 || parentRefs[0].(map[string]interface{})["sectionName"] != "web" {
+		t.Errorf("parentRefs = %v", parentRefs)
+	}
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	if len(rules) != 1 {
+		t.Fatalf("rules = %v", rules)
+	}
+	matches, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "matches")
+	if len(matches) != 1 {
+		t.Fatalf("matches = %v", matches)
+	}
+	if path, _, _ := unstructured.NestedString(matches[0].(map[string]interface{}), "path", "value"); path != "/v1" {
+		t.Errorf("path = %q, want /v1", path)
+	}
+	backendRefs, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "backendRefs")
+	if len(backendRefs) != 1 || backendRefs[0].(map[string]interface{})["name"] != frontendServiceName(llmCluster) {
+		t.Errorf("backendRefs = %v", backendRefs)
+	}
+
+	tests := []struct {
+		name        string
+		sectionName string
+		tls         bool
+		gateway     *unstructured.Unstructured
+		want        string
+	}{
+		{name: "HTTP listener", sectionName: "web", want: "http://llm.example.com/v1"},
+		{name: "HTTPS listener", sectionName: "websecure", want: "https://llm.example.com/v1"},
+		{name: "any HTTPS listener", want: "https://llm.example.com/v1"},
+		{name: "engine TLS", sectionName: "web", tls: true, want: "https://llm.example.com/v1"},
+		{name: "HTTP-only gateway", gateway: newTestGateway(map[string]string{"web": "HTTP"}), want: "http://llm.example.com/v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := tt.gateway
+			if gateway == nil {
+				gateway = newTestGateway(map[string]string{"web": "HTTP", "websecure": "HTTPS"})
+			}
+			r := newGatewayTestReconciler(gateway)
+			llmCluster := newCluster(tt.sectionName)
+			llmCluster.Spec.Network.TLS.Enabled = tt.tls
+			if err := r.reconcileHTTPRoute(ctx, llmCluster); err != nil {
+				t.Fatal(err)
+			}
+			if llmCluster.Status.RouterURL != tt.want {
+				t.Errorf("routerURL = %q, want %q", llmCluster.Status.RouterURL, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileHTTPRouteWithoutGatewayAPI(t *testing.T) {
+	r, _ := newTestReconciler()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Network.Gateway.Hostname = "llm.example.com"
+	if err := r.reconcileHTTPRoute(context.Background(), llmCluster); err != nil {
+		t.Fatalf("missing Gateway API should be skipped, got %v", err)
+	}
+	if llmCluster.Status.RouterURL != "" {
+		t.Errorf("routerURL = %q without an HTTPRoute", llmCluster.Status.RouterURL)
+	}
+}
+
+func TestRouterWeightedConfig(t *testing.T) {
+	backends := []servingv1alpha1.RouterBackend{
+		{Name: "stable", Service: "llama-stable"},
+		{Name: "canary", Service: "llama-canary"},
+		{Name: "old", Service: "llama-old", Draining: true},
+	}
+	tests := []struct {
+		name    string
+		table   map[string]int32
+		want    map[string]int
+		wantErr bool
+	}{
+		{name: "no table", want: nil},
+		{name: "canary at 5%", table: map[string]int32{"canary": 5}, want: map[string]int{"llama-stable:8000": 19, "llama-canary:8000": 1}},
+		{name: "all listed scale to 100", table: map[string]int32{"stable": 30, "canary": 10}, want: map[string]int{"llama-stable:8000": 3, "llama-canary:8000": 1}},
+		{name: "draining and absent ignored", table: map[string]int32{"canary": 50, "old": 50, "gone": 10}, want: map[string]int{"llama-stable:8000": 1, "llama-canary:8000": 1}},
+		{name: "all zero", table: map[string]int32{"stable": 0, "canary": 0}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.Router = servingv1alpha1.RouterConfig{Enabled: true, Backends: backends}
+			weights, err := routerWeights(llmCluster, tt.table)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(weights) != len(tt.want) {
+				t.Fatalf("weights = %v, want %v", weights, tt.want)
+			}
+			for address, weight := range tt.want {
+				if weights[address] != weight {
+					t.Errorf("weights = %v, want %v", weights, tt.want)
+				}
+			}
+		})
+	}
+
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Router = servingv1alpha1.RouterConfig{Enabled: true, Backends: backends}
+	weights, err := routerWeights(llmCluster, map[string]int32{"canary": 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := renderNginxConfig(llmCluster, routerUpstreams(llmCluster), routerDrainingUpstreams(llmCluster), weights)
+	for _, want := range []string{
+		"server llama-stable:8000 weight=19 ",
+		"server llama-canary:8000 weight=1 ",
+		"server llama-old:8000 down;",
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("nginx config lacks %q:\n%s", want, config)
+		}
+	}
+}
+
+func TestRouterWeightTable(t *testing.T) {
+	ctx := context.Background()
+	newCluster := func() *servingv1alpha1.LLMCluster {
+		llmCluster := newTestCluster()
+		llmCluster.Spec.Router = servingv1alpha1.RouterConfig{
+			Enabled:          true,
+			Weights:          map[string]int32{"stable": 90, "canary": 10},
+			WeightsConfigMap: "llama-weights",
+		}
+		return llmCluster
+	}
+	weightsConfigMap := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama-weights"}, Data: data}
+	}
+
+	r, _ := newTestReconciler(weightsConfigMap(map[string]string{"canary": " 5 "}))
+	table, err := r.routerWeightTable(ctx, newCluster())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table["stable"] != 90 || table["canary"] != 5 {
+		t.Errorf("table = %v, want the ConfigMap to override canary", table)
+	}
+
+	for name, data := range map[string]map[string]string{
+		"not an integer": {"canary": "five"},
+		"over 100":       {"canary": "20"},
+		"negative":       {"canary": "-1"},
+	} {
+		r, _ := newTestReconciler(weightsConfigMap(data))
+		if _, err := r.routerWeightTable(ctx, newCluster()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	r, _ = newTestReconciler(newCluster(), weightsConfigMap(nil))
+	requests := r.clustersForWeightsConfigMap(ctx, weightsConfigMap(nil))
+	if len(requests) != 1 || requests[0].Name != "llama" {
+		t.Errorf("ConfigMap change enqueued %v, want llama", requests)
+	}
+}
+
+func TestReconcileHPABehavior(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Autoscaling = servingv1alpha1.AutoscalingConfig{
+		Enabled:                        true,
+		MinReplicas:                    1,
+		MaxReplicas:                    4,
+		TargetCPUUtilizationPercentage: 70,
+		Behavior: servingv1alpha1.AutoscalingBehavior{
+			ScaleUpStabilizationWindowSeconds:   120,
+			ScaleDownStabilizationWindowSeconds: 600,
+			ReadyPodsMetric: servingv1alpha1.CustomMetric{
+				Name:   "vllm_num_requests_running",
+				Target: servingv1alpha1.MetricTarget{AverageValue: "8"},
+			},
+		},
+	}
+	r, _ := newTestReconciler(llmCluster)
+	if err := r.reconcileHPA(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama-hpa"}, &hpa); err != nil {
+		t.Fatal(err)
+	}
+	behavior := hpa.Spec.Behavior
+	if behavior == nil || behavior.ScaleUp == nil || behavior.ScaleDown == nil {
+		t.Fatalf("behavior = %+v, want both stabilization windows", behavior)
+	}
+	if *behavior.ScaleUp.StabilizationWindowSeconds != 120 || *behavior.ScaleDown.StabilizationWindowSeconds != 600 {
+		t.Errorf("windows = %d/%d, want 120/600",
+			*behavior.ScaleUp.StabilizationWindowSeconds, *behavior.ScaleDown.StabilizationWindowSeconds)
+	}
+	// The ready-pods metric replaces CPU, which loading pods would drag down
+	if len(hpa.Spec.Metrics) != 1 || hpa.Spec.Metrics[0].Pods == nil || hpa.Spec.Metrics[0].Pods.Metric.Name != "vllm_num_requests_running" {
+		t.Errorf("metrics = %+v, want only the ready-pods metric", hpa.Spec.Metrics)
+	}
+
+	if got := buildHPABehavior(servingv1alpha1.AutoscalingBehavior{}); got != nil {
+		t.Errorf("no windows: behavior = %+v, want nil for the Kubernetes defaults", got)
+	}
+	if got := buildHPABehavior(servingv1alpha1.AutoscalingBehavior{ScaleDownStabilizationWindowSeconds: 300}); got == nil || got.ScaleUp != nil || got.ScaleDown == nil {
+		t.Errorf("scale-down window only: behavior = %+v", got)
+	}
+}
+
+func TestRenderEngineArgs70B(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Model = "meta-llama/Meta-Llama-3-70B-Instruct"
+	llmCluster.Spec.InferenceArgs = servingv1alpha1.InferenceArgs{
+		MaxModelLen:          8192,
+		BlockSize:            16,
+		Dtype:                "bfloat16",
+		GPUMemoryUtilization: 0.9,
+	}
+	want := strings.Join([]string{
+		"--model=meta-llama/Meta-Llama-3-70B-Instruct",
+		"--tensor-parallel-size=8",
+		"--host=0.0.0.0",
+		"--port=8000",
+		"--served-model-name=meta-llama/Meta-Llama-3-70B-Instruct",
+		"--max-model-len=8192",
+		"--block-size=16",
+		"--dtype=bfloat16",
+		"--gpu-memory-utilization=0.9",
+	}, "\n") + "\n"
+
+	r, _ := newTestReconciler(llmCluster)
+	if err := r.reconcileConfigMaps(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	var configMap corev1.ConfigMap
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: configMapName(llmCluster)}, &configMap); err != nil {
+		t.Fatal(err)
+	}
+	if got := configMap.Data[engineArgsKey]; got != want {
+		t.Errorf("rendered args:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The pod template carries the checksum, so a config change rolls pods
+	statefulSet, err := r.reconcileStatefulSet(ctx, llmCluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum := statefulSet.Spec.Template.Annotations[annotationConfigChecksum]
+	if checksum != configChecksum(want) {
+		t.Errorf("pod template checksum = %q, want the rendered args' checksum", checksum)
+	}
+	llmCluster.Spec.InferenceArgs.Dtype = "float16"
+	if configChecksum(renderEngineArgs(llmCluster)) == checksum {
+		t.Error("checksum unchanged after a dtype change")
+	}
+}
+
+func TestReconcileDeletionCleanup(t *testing.T) {
+	ctx := context.Background()
+	now := metav1.Now()
+
+	llmCluster := newTestCluster()
+	llmCluster.Finalizers = []string{cleanupFinalizer}
+	llmCluster.DeletionTimestamp = &now
+	llmCluster.Spec.Storage.ModelCache.Enabled = true
+
+	router := newTestCluster()
+	router.Name, router.UID = "gateway", "gateway-uid"
+	router.Spec.Router = servingv1alpha1.RouterConfig{
+		Enabled: true,
+		Backends: []servingv1alpha1.RouterBackend{
+			{Name: "llama", Service: "llama"},
+			{Name: "mistral", Service: "mistral"},
+		},
+	}
+	modelCacheClaim := func(name, cluster string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Labels:    map[string]string{labelModelCache: cluster},
+		}}
+	}
+
+	r, _ := newTestReconciler(llmCluster, router,
+		modelCacheClaim("model-cache-llama-0", "llama"),
+		modelCacheClaim("model-cache-llama-1", "llama"),
+		modelCacheClaim("model-cache-mistral-0", "mistral"))
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	// Finalizer removed, so the fake client completes the delete
+	if err := r.Get(ctx, req.NamespacedName, &servingv1alpha1.LLMCluster{}); !errors.IsNotFound(err) {
+		t.Errorf("LLMCluster still present after cleanup: %v", err)
+	}
+	var updated servingv1alpha1.LLMCluster
+	if err := r.Get(ctx, client.ObjectKeyFromObject(router), &updated); err != nil {
+		t.Fatal(err)
+	}
+	if backends := updated.Spec.Router.Backends; len(backends) != 1 || backends[0].Name != "mistral" {
+		t.Errorf("router backends = %+v, want only mistral", backends)
+	}
+	var claims corev1.PersistentVolumeClaimList
+	if err := r.List(ctx, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if len(claims.Items) != 1 || claims.Items[0].Name != "model-cache-mistral-0" {
+		t.Errorf("claims left = %d, want only mistral's", len(claims.Items))
+	}
+
+	// A retried delete finds nothing left to clean up
+	if err := r.finalize(ctx, llmCluster); err != nil {
+		t.Errorf("repeated finalize: %v", err)
+	}
+}
+
+func TestStatefulSetModelCache(t *testing.T) {
+	ctx := context.Background()
+	findMount := func(container corev1.Container, name string) *corev1.VolumeMount {
+		for i := range container.VolumeMounts {
+			if container.VolumeMounts[i].Name == name {
+				return &container.VolumeMounts[i]
+			}
+		}
+		return nil
+	}
+	shmLimit := func(statefulSet *appsv1.StatefulSet) string {
+		for _, volume := range statefulSet.Spec.Template.Spec.Volumes {
+			if volume.Name == "shm" && volume.EmptyDir != nil && volume.EmptyDir.SizeLimit != nil {
+				return volume.EmptyDir.SizeLimit.String()
+			}
+		}
+		return ""
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		llmCluster := newTestCluster()
+		r, _ := newTestReconciler(llmCluster)
+		statefulSet, err := r.reconcileStatefulSet(ctx, llmCluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(statefulSet.Spec.VolumeClaimTemplates) != 0 {
+			t.Errorf("volumeClaimTemplates = %d, want none", len(statefulSet.Spec.VolumeClaimTemplates))
+		}
+		if findMount(statefulSet.Spec.Template.Spec.Containers[0], "model-cache") != nil {
+			t.Error("model-cache mounted while disabled")
+		}
+		if limit := shmLimit(statefulSet); limit != defaultShmSize {
+			t.Errorf("shm limit = %q, want %s", limit, defaultShmSize)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		llmCluster := newTestCluster()
+		llmCluster.Spec.Storage.ShmSize = "32Gi"
+		llmCluster.Spec.Storage.ModelCache = servingv1alpha1.ModelCache{Enabled: true, StorageClass: "fast-ssd", Size: "500Gi"}
+		r, _ := newTestReconciler(llmCluster)
+		statefulSet, err := r.reconcileStatefulSet(ctx, llmCluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(statefulSet.Spec.VolumeClaimTemplates) != 1 {
+			t.Fatalf("volumeClaimTemplates = %d, want 1", len(statefulSet.Spec.VolumeClaimTemplates))
+		}
+		claim := statefulSet.Spec.VolumeClaimTemplates[0]
+		if claim.Name != "model-cache" || claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName != "fast-ssd" {
+			t.Errorf("claim %s storageClass %v, want model-cache on fast-ssd", claim.Name, claim.Spec.StorageClassName)
+		}
+		if size := claim.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "500Gi" {
+			t.Errorf("claim size = %s, want 500Gi", size.String())
+		}
+		if claim.Labels[labelModelCache] != "llama" {
+			t.Errorf("claim labels = %v, want %s=llama for cleanup", claim.Labels, labelModelCache)
+		}
+		mount := findMount(statefulSet.Spec.Template.Spec.Containers[0], "model-cache")
+		if mount == nil || mount.MountPath != modelCacheMountPath {
+			t.Errorf("model-cache mount = %+v, want %s", mount, modelCacheMountPath)
+		}
+		if limit := shmLimit(statefulSet); limit != "32Gi" {
+			t.Errorf("shm limit = %q, want 32Gi", limit)
+		}
+	})
+}
+
+func TestReconcileMarksDegraded(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Finalizers = []string{cleanupFinalizer}
+
+	r, _ := newTestReconciler()
+	failCreate := true
+	r.Client = fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithObjects(llmCluster).
+		WithStatusSubresource(llmCluster).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*appsv1.StatefulSet); ok && failCreate {
+					return errors.NewForbidden(appsv1.Resource("statefulsets"), obj.GetName(), fmt.Errorf("exceeded quota"))
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	degraded := func() (string, *servingv1alpha1.Condition) {
+		var current servingv1alpha1.LLMCluster
+		if err := r.Get(ctx, req.NamespacedName, &current); err != nil {
+			t.Fatal(err)
+		}
+		for i := range current.Status.Conditions {
+			if current.Status.Conditions[i].Type == "Degraded" {
+				return current.Status.Phase, &current.Status.Conditions[i]
+			}
+		}
+		return current.Status.Phase, nil
+	}
+
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatal("Reconcile succeeded although the StatefulSet create failed")
+	}
+	phase, condition := degraded()
+	if phase != "Degraded" || condition == nil || condition.Status != "True" {
+		t.Fatalf("phase %q, condition %+v, want Degraded", phase, condition)
+	}
+	if condition.Reason != "StatefulSetFailed" || !strings.Contains(condition.Message, "exceeded quota") {
+		t.Errorf("condition = %+v, want the StatefulSet and its error", condition)
+	}
+
+	failCreate = false
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if phase, condition = degraded(); phase == "Degraded" || condition == nil || condition.Status != "False" {
+		t.Errorf("after recovery: phase %q, condition %+v, want Degraded cleared", phase, condition)
+	}
+}
+
+// childObjectCount counts the objects the controller would create for a
+// cluster, to assert a dry run left none behind.
+func childObjectCount(t *testing.T, c client.Reader) int {
+	ctx := context.Background()
+	count := 0
+	for _, list := range []client.ObjectList{&appsv1.StatefulSetList{}, &appsv1.DeploymentList{}, &corev1.ServiceList{}, &corev1.ConfigMapList{}} {
+		if err := c.List(ctx, list); err != nil {
+			t.Fatal(err)
+		}
+		count += meta.LenList(list)
+	}
+	return count
+}
+
+func TestDryRunFlagWritesOnlyStatus(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+
+	r, _ := newTestReconciler()
+	live := fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(llmCluster).WithStatusSubresource(llmCluster).Build()
+	r.Client = newDryRunClient(live)
+
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	if n := childObjectCount(t, live); n != 0 {
+		t.Errorf("dry run persisted %d child objects", n)
+	}
+	var current servingv1alpha1.LLMCluster
+	if err := live.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatal(err)
+	}
+	if len(current.Finalizers) != 0 {
+		t.Errorf("dry run persisted finalizers %v", current.Finalizers)
+	}
+	if current.Status.Phase == "" || len(current.Status.EffectiveArgs) == 0 {
+		t.Errorf("status not written: phase %q, effectiveArgs %v", current.Status.Phase, current.Status.EffectiveArgs)
+	}
+}
+
+func TestValidatorCreate(t *testing.T) {
+	r, _ := newTestReconciler()
+	v := &llmClusterValidator{reconciler: r}
+
+	for name, tc := range map[string]struct {
+		mutate  func(*servingv1alpha1.LLMCluster)
+		wantErr string
+	}{
+		"valid":                {mutate: func(*servingv1alpha1.LLMCluster) {}},
+		"zero replicas":        {mutate: func(c *servingv1alpha1.LLMCluster) { c.Spec.Replicas = 0 }, wantErr: "replicas must be > 0"},
+		"tensor parallel size": {mutate: func(c *servingv1alpha1.LLMCluster) { c.Spec.TensorParallelSize = 4 }, wantErr: "tensorParallelSize"},
+		"bad revision":         {mutate: func(c *servingv1alpha1.LLMCluster) { c.Spec.ModelRevision = "a..b" }, wantErr: "modelRevision"},
+		"unknown engine":       {mutate: func(c *servingv1alpha1.LLMCluster) { c.Spec.InferenceEngine = "triton" }, wantErr: "triton"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			tc.mutate(llmCluster)
+			_, err := v.ValidateCreate(context.Background(), llmCluster)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateCreate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("ValidateCreate error = %v, want it to mention %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	if _, err := v.ValidateCreate(context.Background(), &corev1.Pod{}); err == nil {
+		t.Error("ValidateCreate accepted a non-LLMCluster object")
+	}
+}
+
+func TestValidatorUpdate(t *testing.T) {
+	r, _ := newTestReconciler()
+	v := &llmClusterValidator{reconciler: r}
+	ctx := context.Background()
+	oldCluster := newTestCluster()
+
+	llmCluster := oldCluster.DeepCopy()
+	llmCluster.Spec.Replicas = 2
+	llmCluster.Spec.GPUsPerPod = 4
+	if _, err := v.ValidateUpdate(ctx, oldCluster, llmCluster); err != nil {
+		t.Errorf("valid update rejected: %v", err)
+	}
+
+	llmCluster = oldCluster.DeepCopy()
+	llmCluster.Spec.Model = "meta-llama/Meta-Llama-3-70B"
+	if _, err := v.ValidateUpdate(ctx, oldCluster, llmCluster); err == nil || !strings.Contains(err.Error(), "immutable") {
+		t.Errorf("model change: error = %v, want immutable", err)
+	}
+
+	llmCluster = oldCluster.DeepCopy()
+	llmCluster.Spec.Replicas = 0
+	if _, err := v.ValidateUpdate(ctx, oldCluster, llmCluster); err == nil {
+		t.Error("update to an invalid spec accepted")
+	}
+
+	// An object already stored with an invalid spec must still be able to
+	// drop its finalizer.
+	invalid := oldCluster.DeepCopy()
+	invalid.Spec.Replicas = 0
+	llmCluster = invalid.DeepCopy()
+	llmCluster.Finalizers = nil
+	if _, err := v.ValidateUpdate(ctx, invalid, llmCluster); err != nil {
+		t.Errorf("metadata-only update rejected: %v", err)
+	}
+}
+
+func TestReconcilePolicyDryRun(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.ReconcilePolicy = reconcilePolicyDryRun
+
+	r, _ := newTestReconciler()
+	writes, finalizerWrites := 0, 0
+	r.Client = fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithObjects(llmCluster).
+		WithStatusSubresource(llmCluster).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if len((&client.CreateOptions{}).ApplyOptions(opts).DryRun) == 0 {
+					writes++
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if len((&client.UpdateOptions{}).ApplyOptions(opts).DryRun) == 0 {
+					if _, ok := obj.(*servingv1alpha1.LLMCluster); ok {
+						finalizerWrites++
+					} else {
+						writes++
+					}
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				writes++
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				writes++
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+	}
+
+	// Only the finalizer is written for real, once, so deleting the
+	// cluster still runs cleanup
+	if writes != 0 || finalizerWrites != 1 {
+		t.Errorf("dryRun reconciles made %d writes and %d finalizer writes, want 0 and 1", writes, finalizerWrites)
+	}
+	if n := childObjectCount(t, r.Client); n != 0 {
+		t.Errorf("dryRun reconcile created %d child objects", n)
+	}
+	var current servingv1alpha1.LLMCluster
+	if err := r.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatal(err)
+	}
+	if !controllerutil.ContainsFinalizer(&current, cleanupFinalizer) {
+		t.Errorf("finalizers = %v, want %s", current.Finalizers, cleanupFinalizer)
+	}
+	if !containsPrefix(current.Status.PendingChanges, "create StatefulSet llama") {
+		t.Errorf("pendingChanges = %v, want the StatefulSet create", current.Status.PendingChanges)
+	}
+	if containsPrefix(current.Status.PendingChanges, "update LLMCluster") {
+		t.Errorf("pendingChanges = %v, want no LLMCluster update", current.Status.PendingChanges)
+	}
+
+	// Switching back to apply creates the children and clears the report
+	current.Spec.ReconcilePolicy = reconcilePolicyApply
+	if err := r.Update(ctx, &current); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if n := childObjectCount(t, r.Client); n == 0 {
+		t.Error("apply reconcile created no child objects")
+	}
+	if err := r.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatal(err)
+	}
+	if len(current.Status.PendingChanges) != 0 {
+		t.Errorf("pendingChanges = %v after switching to apply, want none", current.Status.PendingChanges)
+	}
+}
+
+func containsPrefix(values []string, prefix string) bool {
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReconcileLoadingProtectionPodGone(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	loadingPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: name,
+			Labels: map[string]string{"app": "llama", labelLoading: "true"},
+		}, Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}}
+	}
+
+	r, _ := newTestReconciler()
+	patchErr := errors.NewNotFound(corev1.Resource("pods"), "llama-0")
+	r.Client = fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithObjects(llmCluster, loadingPod("llama-0"), loadingPod("llama-1")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if obj.GetName() == "llama-0" {
+					return patchErr
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).
+		Build()
+
+	// llama-0 was deleted after the list: llama-1 still turns ready
+	if err := r.reconcileLoadingProtection(ctx, llmCluster); err != nil {
+		t.Fatalf("reconcileLoadingProtection: %v", err)
+	}
+	var pod corev1.Pod
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama-1"}, &pod); err != nil {
+		t.Fatal(err)
+	}
+	if _, loading := pod.Labels[labelLoading]; loading {
+		t.Error("ready pod after a vanished one kept the loading label")
+	}
+	var pdb policyv1.PodDisruptionBudget
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama-loading-pdb"}, &pdb); err != nil {
+		t.Errorf("loading PDB not reconciled: %v", err)
+	}
+
+	// Any other failure surfaces instead of being swallowed
+	patchErr = errors.NewConflict(corev1.Resource("pods"), "llama-0", fmt.Errorf("stale"))
+	if err := r.reconcileLoadingProtection(ctx, llmCluster); !errors.IsConflict(err) {
+		t.Errorf("reconcileLoadingProtection error = %v, want the conflict", err)
+	}
+}
+
+func TestDryRunEventsAndPatches(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.ReconcilePolicy = reconcilePolicyDryRun
+	llmCluster.Spec.HighAvailability.ProtectLoadingPods = true
+	// Ready, so reconcileLoadingProtection would drop its loading label
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama-0", Labels: map[string]string{"app": "llama", labelLoading: "true"}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}
+
+	r, _ := newTestReconciler()
+	recorder := record.NewFakeRecorder(100)
+	r.Recorder = recorder
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(llmCluster, pod).WithStatusSubresource(llmCluster).Build()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	if !containsPrefix(events, "Normal DryRun Would create StatefulSet") {
+		t.Errorf("events = %v, want a would-create StatefulSet event", events)
+	}
+	for _, event := range events {
+		if strings.HasPrefix(event, "Normal Created") {
+			t.Errorf("dry run emitted %q", event)
+		}
+	}
+
+	var current corev1.Pod
+	if err := r.Get(ctx, client.ObjectKeyFromObject(pod), &current); err != nil {
+		t.Fatal(err)
+	}
+	if _, loading := current.Labels[labelLoading]; !loading {
+		t.Error("dry run removed the pod's loading label")
+	}
+	var cluster servingv1alpha1.LLMCluster
+	if err := r.Get(ctx, req.NamespacedName, &cluster); err != nil {
+		t.Fatal(err)
+	}
+	if want := "patch Pod llama-0: metadata.labels"; !containsPrefix(cluster.Status.PendingChanges, want) {
+		t.Errorf("pendingChanges = %v, want %q", cluster.Status.PendingChanges, want)
+	}
+
+	// Applied for real, the same events read as done
+	writer := &LLMClusterReconciler{Client: r.Client, Scheme: r.Scheme, Recorder: recorder}
+	writer.recordWrite(llmCluster, "Created", "create", "StatefulSet")
+	if event := <-recorder.Events; event != "Normal Created Created StatefulSet" {
+		t.Errorf("event = %q", event)
+	}
+}
+
+func TestBuildInferenceCommandPerEngine(t *testing.T) {
+	for _, tc := range []struct {
+		engine     string
+		entrypoint string
+		want       []string
+	}{
+		{
+			engine:     engineVLLM,
+			entrypoint: "python -m vllm.entrypoints.openai.api_server",
+			want: []string{
+				"--model=meta-llama/Meta-Llama-3-8B", "--tensor-parallel-size=8", "--port=8000",
+				"--revision=abc123", "--max-model-len=4096", "--gpu-memory-utilization=0.85",
+			},
+		},
+		{
+			engine:     engineTGI,
+			entrypoint: "text-generation-launcher",
+			want: []string{
+				"--model-id=meta-llama/Meta-Llama-3-8B", "--num-shard=8", "--port=8000",
+				"--revision=abc123", "--max-total-tokens=4096", "--cuda-memory-fraction=0.85",
+			},
+		},
+		{
+			engine:     engineSGLang,
+			entrypoint: "python -m sglang.launch_server",
+			want: []string{
+				"--model-path=meta-llama/Meta-Llama-3-8B", "--tp-size=8", "--port=8000",
+				"--revision=abc123", "--context-length=4096", "--mem-fraction-static=0.85",
+			},
+		},
+	} {
+		t.Run(tc.engine, func(t *testing.T) {
+			spec := newTestCluster().Spec
+			spec.InferenceEngine = tc.engine
+			spec.ModelRevision = "abc123"
+			spec.InferenceArgs = servingv1alpha1.InferenceArgs{MaxModelLen: 4096, GPUMemoryUtilization: 0.85}
+
+			cmd := buildInferenceCommand(&spec)
+			if got := strings.Join(cmd.Entrypoint, " "); got != tc.entrypoint {
+				t.Errorf("entrypoint = %q, want %q", got, tc.entrypoint)
+			}
+			for _, want := range tc.want {
+				if !hasArg(cmd.Args, want) {
+					t.Errorf("args %s lack %s", strings.Join(cmd.Args, " "), want)
+				}
+			}
+		})
+	}
+}
+
+func TestPDBMinAvailableHeadroom(t *testing.T) {
+	for _, tc := range []struct {
+		minAvailable, headroom, want int
+	}{
+		{minAvailable: 3, want: 3},
+		{minAvailable: 3, headroom: 1, want: 2},
+		{minAvailable: 2, headroom: 2, want: 0},
+		{minAvailable: 1, headroom: 3, want: 0},
+	} {
+		pdb := servingv1alpha1.PDBConfig{Enabled: true, MinAvailable: tc.minAvailable, PreemptionHeadroom: tc.headroom}
+		if got := pdbMinAvailable(pdb); got != tc.want {
+			t.Errorf("minAvailable %d, headroom %d: got %d, want %d", tc.minAvailable, tc.headroom, got, tc.want)
+		}
+	}
+
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Replicas = 4
+	llmCluster.Spec.HighAvailability.PodDisruptionBudget = servingv1alpha1.PDBConfig{Enabled: true, MinAvailable: 3, PreemptionHeadroom: 1}
+	r, _ := newTestReconciler(llmCluster)
+	if err := r.reconcilePDB(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	var pdb policyv1.PodDisruptionBudget
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama-pdb"}, &pdb); err != nil {
+		t.Fatal(err)
+	}
+	if pdb.Spec.MinAvailable == nil || pdb.Spec.MinAvailable.IntValue() != 2 {
+		t.Errorf("PDB minAvailable = %v, want 2 after the headroom", pdb.Spec.MinAvailable)
+	}
+}
+
+func TestReconcilePDBLifecycle(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Replicas = 3
+	llmCluster.Spec.HighAvailability.PodDisruptionBudget = servingv1alpha1.PDBConfig{Enabled: true, MinAvailable: 1}
+	r, _ := newTestReconciler(llmCluster)
+	key := client.ObjectKey{Namespace: "default", Name: "llama-pdb"}
+	minAvailable := func() int {
+		t.Helper()
+		var pdb policyv1.PodDisruptionBudget
+		if err := r.Get(ctx, key, &pdb); err != nil {
+			t.Fatal(err)
+		}
+		if !metav1.IsControlledBy(&pdb, llmCluster) || pdb.Spec.Selector.MatchLabels["app"] != "llama" {
+			t.Errorf("PDB owner %v, selector %v; want controlled by llama, selecting app=llama", pdb.OwnerReferences, pdb.Spec.Selector)
+		}
+		return pdb.Spec.MinAvailable.IntValue()
+	}
+
+	if err := r.reconcilePDB(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	if got := minAvailable(); got != 1 {
+		t.Errorf("created minAvailable = %d, want 1", got)
+	}
+
+	llmCluster.Spec.HighAvailability.PodDisruptionBudget.MinAvailable = 2
+	if err := r.reconcilePDB(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	if got := minAvailable(); got != 2 {
+		t.Errorf("updated minAvailable = %d, want 2", got)
+	}
+
+	// Disabled: Reconcile removes the PDB it created
+	if err := r.deleteOwned(ctx, llmCluster, &policyv1.PodDisruptionBudget{}, "llama-pdb", "PDB"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &policyv1.PodDisruptionBudget{}); !errors.IsNotFound(err) {
+		t.Errorf("PDB after disabling: %v, want NotFound", err)
+	}
+}
+
+func TestReconcileNetworkPolicyRules(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Router.Enabled = true
+	llmCluster.Spec.Monitoring.MetricsPort = 9100
+	r, _ := newTestReconciler(llmCluster)
+	if err := r.reconcileNetworkPolicy(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	var policy networkingv1.NetworkPolicy
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama"}, &policy); err != nil {
+		t.Fatal(err)
+	}
+
+	// ingressFrom returns the peers allowed to reach the named port
+	ingressFrom := func(portName string) []networkingv1.NetworkPolicyPeer {
+		for _, rule := range policy.Spec.Ingress {
+			for _, p := range rule.Ports {
+				if p.Port != nil && p.Port.StrVal == portName {
+					return rule.From
+				}
+			}
+		}
+		t.Fatalf("no ingress rule for port %s in %+v", portName, policy.Spec.Ingress)
+		return nil
+	}
+	if from := ingressFrom("http"); len(from) != 1 || from[0].PodSelector.MatchLabels["app"] != "llama-router" {
+		t.Errorf("inference port open to %+v, want only the router pods", from)
+	}
+	if from := ingressFrom("metrics"); len(from) != 1 || from[0].NamespaceSelector == nil ||
+		from[0].PodSelector.MatchLabels[prometheusPodLabel] != prometheusPodValue {
+		t.Errorf("metrics port open to %+v, want Prometheus in any namespace", from)
+	}
+
+	egressPorts := map[string]bool{}
+	for _, rule := range policy.Spec.Egress {
+		for _, p := range rule.Ports {
+			egressPorts[fmt.Sprintf("%s/%d", *p.Protocol, p.Port.IntValue())] = len(rule.To) == 0
+		}
+	}
+	for _, want := range []string{"UDP/53", "TCP/53", "TCP/443"} {
+		if open, found := egressPorts[want]; !found || !open {
+			t.Errorf("egress %s not open to any destination: %v", want, egressPorts)
+		}
+	}
+
+	// Without a router, any client may reach the inference port
+	llmCluster.Spec.Router.Enabled = false
+	if err := r.reconcileNetworkPolicy(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama"}, &policy); err != nil {
+		t.Fatal(err)
+	}
+	if from := ingressFrom("http"); len(from) != 0 {
+		t.Errorf("inference port limited to %+v without a router", from)
+	}
+}
+
+func TestReconcileRouterDeploymentPerType(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		routerType string
+		image      string
+		configKey  string
+		mountPath  string
+	}{
+		{routerType: "", image: defaultRouterImage, configKey: "nginx.conf", mountPath: "/etc/nginx/nginx.conf"},
+		{routerType: routerTypeEnvoy, image: defaultEnvoyRouterImage, configKey: "envoy.yaml", mountPath: "/etc/envoy"},
+		{routerType: routerTypeCustom, image: "example.com/llm-router:v2"},
+	} {
+		name := tc.routerType
+		if name == "" {
+			name = "default nginx"
+		}
+		t.Run(name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.Replicas = 2
+			llmCluster.Spec.Router = servingv1alpha1.RouterConfig{Enabled: true, Type: tc.routerType}
+			if tc.routerType == routerTypeCustom {
+				llmCluster.Spec.Router.Image = tc.image
+			}
+			r, _ := newTestReconciler(llmCluster)
+			if err := r.reconcileRouterDeployment(ctx, llmCluster); err != nil {
+				t.Fatal(err)
+			}
+
+			var deployment appsv1.Deployment
+			if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama-router"}, &deployment); err != nil {
+				t.Fatal(err)
+			}
+			container := deployment.Spec.Template.Spec.Containers[0]
+			if container.Image != tc.image {
+				t.Errorf("image = %q, want %q", container.Image, tc.image)
+			}
+
+			var configMap corev1.ConfigMap
+			configErr := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama-router-config"}, &configMap)
+			if tc.configKey == "" {
+				// Custom routers get the backends through the environment
+				env := map[string]string{}
+				for _, e := range container.Env {
+					env[e.Name] = e.Value
+				}
+				if !strings.Contains(env["BACKENDS"], "llama-0.llama-backend.default.svc.cluster.local:8000") ||
+					strings.Count(env["BACKENDS"], ",") != 1 || env["PORT"] == "" {
+					t.Errorf("env = %v, want both engine pods in BACKENDS and PORT set", env)
+				}
+				if !errors.IsNotFound(configErr) || len(container.VolumeMounts) != 0 {
+					t.Errorf("custom router got a config: %v, mounts %v", configErr, container.VolumeMounts)
+				}
+				return
+			}
+
+			if configErr != nil {
+				t.Fatal(configErr)
+			}
+			if !strings.Contains(configMap.Data[tc.configKey], "llama-1.llama-backend") {
+				t.Errorf("%s lacks the engine backends:\n%s", tc.configKey, configMap.Data[tc.configKey])
+			}
+			if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].MountPath != tc.mountPath {
+				t.Errorf("mounts = %+v, want the config at %s", container.VolumeMounts, tc.mountPath)
+			}
+			if deployment.Spec.Template.Annotations[annotationConfigChecksum] != configChecksum(configMap.Data[tc.configKey]) {
+				t.Error("pod template checksum doesn't match the rendered config")
+			}
+			if len(container.Env) != 0 {
+				t.Errorf("env = %v, want none with a rendered config", container.Env)
+			}
+		})
+	}
+}
+
+func TestMetricsPortExposedForScraping(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Monitoring = servingv1alpha1.MonitoringConfig{Enabled: true, Prometheus: true, MetricsPort: 9100}
+	r, _ := newTestReconciler(llmCluster)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(podMonitorGVK, meta.RESTScopeNamespace)
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithRESTMapper(mapper).WithObjects(llmCluster).Build()
+
+	if err := r.reconcileServices(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	servicePorts := func(name string) map[string]int32 {
+		t.Helper()
+		var service corev1.Service
+		if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, &service); err != nil {
+			t.Fatal(err)
+		}
+		ports := map[string]int32{}
+		for _, p := range service.Spec.Ports {
+			ports[p.Name] = p.Port
+		}
+		return ports
+	}
+	if ports := servicePorts("llama-backend"); len(ports) != 2 || ports["http"] != 8000 || ports["metrics"] != 9100 {
+		t.Errorf("headless Service ports = %v, want http 8000 and metrics 9100", ports)
+	}
+	if ports := servicePorts("llama"); len(ports) != 1 || ports["metrics"] != 0 {
+		t.Errorf("client Service ports = %v, want the inference port only", ports)
+	}
+
+	if err := r.reconcilePodMonitor(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(podMonitorGVK)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama"}, monitor); err != nil {
+		t.Fatal(err)
+	}
+	endpoints, _, _ := unstructured.NestedSlice(monitor.Object, "spec", "podMetricsEndpoints")
+	if len(endpoints) != 1 || endpoints[0].(map[string]interface{})["port"] != "metrics" {
+		t.Errorf("podMetricsEndpoints = %v, want the metrics port", endpoints)
+	}
+	if labels, _, _ := unstructured.NestedStringSlice(monitor.Object, "spec", "podTargetLabels"); len(labels) != 1 || labels[0] != labelLoading {
+		t.Errorf("podTargetLabels = %v, want %s", labels, labelLoading)
+	}
+}
+
+func TestNCCLEnv(t *testing.T) {
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Coordination.NCCL = map[string]string{
+		"NCCL_IB_DISABLE":          "0",
+		"NCCL_IB_HCA":              "mlx5",
+		"TORCH_NCCL_BLOCKING_WAIT": "1",
+	}
+
+	var names []string
+	env := map[string]string{}
+	for _, e := range ncclEnv(llmCluster) {
+		names = append(names, e.Name)
+		env[e.Name] = e.Value
+	}
+	want := map[string]string{
+		"NCCL_DEBUG":               "WARN",
+		"NCCL_IB_DISABLE":          "0",
+		"NCCL_IB_HCA":              "mlx5",
+		"NCCL_SOCKET_IFNAME":       "eth0",
+		"TORCH_NCCL_BLOCKING_WAIT": "1",
+	}
+	if fmt.Sprint(env) != fmt.Sprint(want) {
+		t.Errorf("env = %v, want %v", env, want)
+	}
+	// Sorted, so the pod template is stable across reconciles
+	if got := strings.Join(names, ","); got != "NCCL_DEBUG,NCCL_IB_DISABLE,NCCL_IB_HCA,NCCL_SOCKET_IFNAME,TORCH_NCCL_BLOCKING_WAIT" {
+		t.Errorf("env order = %s", got)
+	}
+
+	r := &LLMClusterReconciler{}
+	if err := r.validateSpec(llmCluster); err != nil {
+		t.Errorf("validateSpec: %v", err)
+	}
+	for _, key := range []string{"LD_PRELOAD", "nccl_debug", "NCCL_", "PATH"} {
+		llmCluster.Spec.Coordination.NCCL = map[string]string{key: "x"}
+		if err := r.validateSpec(llmCluster); err == nil || !strings.Contains(err.Error(), "coordination.nccl") {
+			t.Errorf("key %q: validateSpec error = %v, want it rejected", key, err)
+		}
+	}
+}
+
+func TestAdoptStatefulSet(t *testing.T) {
+	ctx := context.Background()
+
+	// A hand-rolled StatefulSet shaped like the one the controller renders
+	r, _ := newTestReconciler(newTestCluster())
+	rendered, err := r.reconcileStatefulSet(ctx, newTestCluster())
+	if err != nil {
+		t.Fatal(err)
+	}
+	handRolled := func() *appsv1.StatefulSet {
+		sts := rendered.DeepCopy()
+		sts.OwnerReferences = nil
+		sts.ResourceVersion = ""
+		return sts
+	}
+
+	for name, tc := range map[string]struct {
+		annotate bool
+		mutate   func(*appsv1.StatefulSet)
+		wantErr  string
+	}{
+		"annotated":         {annotate: true},
+		"not annotated":     {wantErr: "set annotation " + annotationAdopt + "=true"},
+		"selector mismatch": {annotate: true, mutate: func(s *appsv1.StatefulSet) { s.Spec.Selector.MatchLabels = map[string]string{"app": "other"} }, wantErr: "selector does not match"},
+		"service mismatch":  {annotate: true, mutate: func(s *appsv1.StatefulSet) { s.Spec.ServiceName = "llama-headless" }, wantErr: `serviceName "llama-headless"`},
+		"owned elsewhere": {annotate: true, mutate: func(s *appsv1.StatefulSet) {
+			controller := true
+			s.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "legacy", UID: "legacy-uid", Controller: &controller}}
+		}, wantErr: "controlled by Deployment legacy"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			if tc.annotate {
+				llmCluster.Annotations = map[string]string{annotationAdopt: "true"}
+			}
+			existing := handRolled()
+			if tc.mutate != nil {
+				tc.mutate(existing)
+			}
+			r, _ := newTestReconciler(llmCluster, existing)
+
+			_, err := r.reconcileStatefulSet(ctx, llmCluster)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want it to mention %q", err, tc.wantErr)
+				}
+				select {
+				case event := <-r.Recorder.(*record.FakeRecorder).Events:
+					if !strings.Contains(event, "AdoptFailed") {
+						t.Errorf("event = %q, want AdoptFailed", event)
+					}
+				default:
+					t.Error("no AdoptFailed event")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var adopted appsv1.StatefulSet
+			if err := r.Get(ctx, client.ObjectKeyFromObject(existing), &adopted); err != nil {
+				t.Fatal(err)
+			}
+			if !metav1.IsControlledBy(&adopted, llmCluster) {
+				t.Errorf("owners = %v, want the LLMCluster as controller", adopted.OwnerReferences)
+			}
+		})
+	}
+}
+
+func TestServedModelName(t *testing.T) {
+	for _, engine := range []string{engineVLLM, engineSGLang} {
+		spec := newTestCluster().Spec
+		spec.InferenceEngine = engine
+		if args := buildInferenceCommand(&spec).Args; !hasArg(args, "--served-model-name=meta-llama/Meta-Llama-3-8B") {
+			t.Errorf("%s: args %s don't default the served name to the model", engine, strings.Join(args, " "))
+		}
+
+		spec.ServedModelName = "llama-3-8b"
+		args := buildInferenceCommand(&spec).Args
+		if !hasArg(args, "--served-model-name=llama-3-8b") || hasArg(args, "--served-model-name=meta-llama/Meta-Llama-3-8B") {
+			t.Errorf("%s: args %s don't serve the alias alone", engine, strings.Join(args, " "))
+		}
+	}
+}
+
+func TestHTTPSPortNamingConsistent(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name        string
+		tls         bool
+		probeScheme string
+		want        corev1.URIScheme
+		wantPort    string
+	}{
+		{name: "plain", want: corev1.URISchemeHTTP, wantPort: "http"},
+		{name: "engine TLS", tls: true, want: corev1.URISchemeHTTPS, wantPort: "https"},
+		{name: "explicit scheme", probeScheme: "HTTPS", want: corev1.URISchemeHTTPS, wantPort: "https"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.Network.TLS = servingv1alpha1.TLSConfig{Enabled: tc.tls, SecretName: "llama-tls"}
+			llmCluster.Spec.Network.ProbeScheme = tc.probeScheme
+			if got := probeScheme(llmCluster); got != tc.want {
+				t.Fatalf("probeScheme = %s, want %s", got, tc.want)
+			}
+			if got := inferencePortName(llmCluster); got != tc.wantPort {
+				t.Fatalf("inferencePortName = %s, want %s", got, tc.wantPort)
+			}
+
+			r, _ := newTestReconciler(llmCluster)
+			statefulSet, err := r.reconcileStatefulSet(ctx, llmCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			container := statefulSet.Spec.Template.Spec.Containers[0]
+			if container.Ports[0].Name != tc.wantPort {
+				t.Errorf("container port name = %s, want %s", container.Ports[0].Name, tc.wantPort)
+			}
+			checked := 0
+			for probeName, probe := range map[string]*corev1.Probe{
+				"readiness": container.ReadinessProbe, "liveness": container.LivenessProbe, "startup": container.StartupProbe,
+			} {
+				if probe == nil || probe.HTTPGet == nil {
+					continue
+				}
+				checked++
+				if probe.HTTPGet.Scheme != tc.want || probe.HTTPGet.Port.StrVal != tc.wantPort {
+					t.Errorf("%s probe %s on port %q, want %s on %q", probeName, probe.HTTPGet.Scheme, probe.HTTPGet.Port.String(), tc.want, tc.wantPort)
+				}
+			}
+			if checked == 0 {
+				t.Error("no HTTP probes on the inference container")
+			}
+
+			if err := r.reconcileServices(ctx, llmCluster); err != nil {
+				t.Fatal(err)
+			}
+			var service corev1.Service
+			if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama"}, &service); err != nil {
+				t.Fatal(err)
+			}
+			if port := service.Spec.Ports[0]; port.Name != tc.wantPort || port.TargetPort.StrVal != tc.wantPort {
+				t.Errorf("Service port %s → %s, want %s", port.Name, port.TargetPort.String(), tc.wantPort)
+			}
+			if endpoint := podMetricsEndpoint(llmCluster); endpoint["port"] != tc.wantPort || (endpoint["scheme"] == "https") != (tc.wantPort == "https") {
+				t.Errorf("PodMonitor endpoint = %v, want port %s", endpoint, tc.wantPort)
+			}
+		})
+	}
+}
+
+func TestHuggingFaceTokenEnv(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	if env := hfTokenEnv(llmCluster); env != nil {
+		t.Errorf("env without a token secret = %v, want none", env)
+	}
+
+	llmCluster.Spec.Security.HuggingfaceToken = servingv1alpha1.HuggingfaceToken{SecretName: "hf-token", SecretKey: "token"}
+	r, _ := newTestReconciler(llmCluster)
+	if err := r.validateSpec(llmCluster); err != nil {
+		t.Fatalf("validateSpec: %v", err)
+	}
+	statefulSet, err := r.reconcileStatefulSet(ctx, llmCluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found *corev1.EnvVar
+	for i, e := range statefulSet.Spec.Template.Spec.Containers[0].Env {
+		if e.Name == "HUGGING_FACE_HUB_TOKEN" {
+			found = &statefulSet.Spec.Template.Spec.Containers[0].Env[i]
+		}
+	}
+	if found == nil || found.Value != "" || found.ValueFrom == nil || found.ValueFrom.SecretKeyRef == nil ||
+		found.ValueFrom.SecretKeyRef.Name != "hf-token" || found.ValueFrom.SecretKeyRef.Key != "token" {
+		t.Errorf("HUGGING_FACE_HUB_TOKEN = %+v, want a reference to hf-token/token", found)
+	}
+
+	llmCluster.Spec.Security.HuggingfaceToken.SecretKey = ""
+	if err := r.validateSpec(llmCluster); err == nil || !strings.Contains(err.Error(), "secretKey is required") {
+		t.Errorf("validateSpec error = %v, want the missing secretKey rejected", err)
+	}
+}
+
+func TestLogShippingSidecar(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	if script := engineScript(llmCluster); strings.Contains(script, "mkfifo") || !strings.HasPrefix(script, "exec ") {
+		t.Errorf("script without log shipping = %q, want the engine exec'd directly", script)
+	}
+
+	llmCluster.Spec.Monitoring.LogShipping = servingv1alpha1.LogShippingConfig{
+		Enabled:          true,
+		Output:           "loki",
+		OutputProperties: map[string]string{"host": "loki.monitoring", "port": "3100"},
+	}
+	r, _ := newTestReconciler(llmCluster)
+	statefulSet, err := r.reconcileStatefulSet(ctx, llmCluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	podSpec := statefulSet.Spec.Template.Spec
+	if len(podSpec.Containers) != 2 || podSpec.Containers[1].Name != "log-shipper" {
+		t.Fatalf("containers = %d, want the engine and the log-shipper", len(podSpec.Containers))
+	}
+	engine, shipper := podSpec.Containers[0], podSpec.Containers[1]
+
+	// The engine writes through a FIFO into the shared volume and stays the
+	// exec'd process; tee keeps the output on the container log as well
+	script := engine.Command[2]
+	for _, want := range []string{"mkfifo /tmp/engine.out", "tee -a " + engineLogFile + " < /tmp/engine.out &", "exec python -m vllm", "> /tmp/engine.out 2>&1"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script %q lacks %q", script, want)
+		}
+	}
+
+	mountOf := func(c corev1.Container) *corev1.VolumeMount {
+		for i := range c.VolumeMounts {
+			if c.VolumeMounts[i].Name == "engine-logs" {
+				return &c.VolumeMounts[i]
+			}
+		}
+		return nil
+	}
+	if m := mountOf(engine); m == nil || m.MountPath != engineLogDir || m.ReadOnly {
+		t.Errorf("engine log mount = %+v, want %s writable", m, engineLogDir)
+	}
+	if m := mountOf(shipper); m == nil || m.MountPath != engineLogDir || !m.ReadOnly {
+		t.Errorf("shipper log mount = %+v, want %s read-only", m, engineLogDir)
+	}
+	shared := false
+	for _, v := range podSpec.Volumes {
+		shared = shared || (v.Name == "engine-logs" && v.EmptyDir != nil)
+	}
+	if !shared {
+		t.Error("no engine-logs emptyDir volume")
+	}
+
+	args := strings.Join(shipper.Args, " ")
+	for _, want := range []string{"path=" + engineLogFile, "tag=llama", "-o loki", "-p host=loki.monitoring -p port=3100"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("shipper args %q lack %q", args, want)
+		}
+	}
+}
+
+func TestInferenceResourcesMergeGPUCount(t *testing.T) {
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Resources.Requests = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("16"),
+		corev1.ResourceMemory: resource.MustParse("200Gi"),
+	}
+	llmCluster.Spec.Resources.Limits = corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse("256Gi"),
+		gpuResourceName:       resource.MustParse("8"),
+	}
+	r := &LLMClusterReconciler{}
+	if err := r.validateSpec(llmCluster); err != nil {
+		t.Fatalf("validateSpec: %v", err)
+	}
+
+	resources := inferenceResources(llmCluster)
+	for _, tc := range []struct {
+		field string
+		list  corev1.ResourceList
+		name  corev1.ResourceName
+		want  string
+	}{
+		{"requests", resources.Requests, corev1.ResourceCPU, "16"},
+		{"requests", resources.Requests, corev1.ResourceMemory, "200Gi"},
+		{"requests", resources.Requests, gpuResourceName, "8"},
+		{"limits", resources.Limits, corev1.ResourceMemory, "256Gi"},
+		{"limits", resources.Limits, gpuResourceName, "8"},
+	} {
+		if got, ok := tc.list[tc.name]; !ok || got.Cmp(resource.MustParse(tc.want)) != 0 {
+			t.Errorf("%s[%s] = %v, want %s", tc.field, tc.name, got.String(), tc.want)
+		}
+	}
+	if _, ok := resources.Limits[corev1.ResourceCPU]; ok {
+		t.Error("cpu limit set although the spec has none")
+	}
+
+	llmCluster.Spec.Resources.Limits[gpuResourceName] = resource.MustParse("4")
+	if err := r.validateSpec(llmCluster); err == nil || !strings.Contains(err.Error(), "conflicts with gpusPerPod") {
+		t.Errorf("validateSpec error = %v, want the GPU limit conflict rejected", err)
+	}
+}
+
+func TestTopologySpreadConstraintsDefaultSelector(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	explicit := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gpu"}}
+	llmCluster.Spec.Scheduling.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+		{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.DoNotSchedule},
+		{MaxSkew: 2, TopologyKey: "kubernetes.io/hostname", WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: explicit},
+	}
+
+	r, _ := newTestReconciler(llmCluster)
+	statefulSet, err := r.reconcileStatefulSet(ctx, llmCluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	constraints := statefulSet.Spec.Template.Spec.TopologySpreadConstraints
+	if len(constraints) != 2 {
+		t.Fatalf("constraints = %d, want 2", len(constraints))
+	}
+	if sel := constraints[0].LabelSelector; sel == nil || !reflect.DeepEqual(sel.MatchLabels, map[string]string{"app": "llama"}) {
+		t.Errorf("defaulted labelSelector = %v, want app=llama", sel)
+	}
+	if sel := constraints[1].LabelSelector; !reflect.DeepEqual(sel, explicit) {
+		t.Errorf("explicit labelSelector = %v, want %v", sel, explicit)
+	}
+	if llmCluster.Spec.Scheduling.TopologySpreadConstraints[0].LabelSelector != nil {
+		t.Error("defaulting mutated the LLMCluster spec")
+	}
+
+	llmCluster.Spec.Scheduling.TopologySpreadConstraints = nil
+	if constraints := topologySpreadConstraints(llmCluster); constraints != nil {
+		t.Errorf("constraints without any configured = %v, want none", constraints)
+	}
+}
+
+func TestPrefixCacheSharedClaim(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Replicas = 2
+	llmCluster.Spec.InferenceArgs.PrefixCaching = servingv1alpha1.PrefixCachingConfig{Enabled: true, SharedClaimName: "prefix-cache"}
+	newClaim := func(modes ...corev1.PersistentVolumeAccessMode) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "prefix-cache", Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{AccessModes: modes},
+		}
+	}
+
+	t.Run("missing claim", func(t *testing.T) {
+		r, _ := newTestReconciler(llmCluster)
+		if err := r.validatePrefixCacheClaim(ctx, llmCluster); err == nil || !errors.IsNotFound(err) {
+			t.Errorf("err = %v, want a not-found error", err)
+		}
+	})
+	t.Run("ReadWriteOnce claim", func(t *testing.T) {
+		r, _ := newTestReconciler(llmCluster, newClaim(corev1.ReadWriteOnce))
+		if err := r.validatePrefixCacheClaim(ctx, llmCluster); err == nil || !strings.Contains(err.Error(), "must be ReadWriteMany") {
+			t.Errorf("err = %v, want the ReadWriteMany error", err)
+		}
+	})
+	t.Run("ReadWriteMany claim", func(t *testing.T) {
+		r, _ := newTestReconciler(llmCluster, newClaim(corev1.ReadWriteOnce, corev1.ReadWriteMany))
+		if err := r.validatePrefixCacheClaim(ctx, llmCluster); err != nil {
+			t.Fatal(err)
+		}
+
+		statefulSet, err := r.reconcileStatefulSet(ctx, llmCluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+		podSpec := statefulSet.Spec.Template.Spec
+		var volume *corev1.Volume
+		for i := range podSpec.Volumes {
+			if podSpec.Volumes[i].Name == "prefix-cache" {
+				volume = &podSpec.Volumes[i]
+			}
+		}
+		if volume == nil || volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != "prefix-cache" {
+			t.Errorf("prefix-cache volume = %+v, want the shared claim", volume)
+		}
+		mounted := false
+		for _, m := range podSpec.Containers[0].VolumeMounts {
+			mounted = mounted || (m.Name == "prefix-cache" && m.MountPath == prefixCacheMountPath)
+		}
+		if !mounted {
+			t.Errorf("engine mounts = %v, want prefix-cache at %s", podSpec.Containers[0].VolumeMounts, prefixCacheMountPath)
+		}
+		if args := buildInferenceCommand(&llmCluster.Spec).Args; !hasArg(args, "--kv-transfer-config="+prefixCacheConnector) {
+			t.Errorf("args %v lack the LMCache connector", args)
+		}
+	})
+
+	env := map[string]string{}
+	for _, e := range prefixCacheEnv(llmCluster) {
+		env[e.Name] = e.Value
+	}
+	if env["LMCACHE_LOCAL_DISK"] != "file://"+prefixCacheMountPath+"/" || env["LMCACHE_MAX_LOCAL_DISK_SIZE"] != "50" || env["LMCACHE_LOCAL_CPU"] != "False" {
+		t.Errorf("LMCache env = %v, want the disk backend at %s capped at the 50GB default", env, prefixCacheMountPath)
+	}
+	llmCluster.Spec.InferenceArgs.PrefixCaching.SharedCacheSizeGB = 200
+	for _, e := range prefixCacheEnv(llmCluster) {
+		if e.Name == "LMCACHE_MAX_LOCAL_DISK_SIZE" && e.Value != "200" {
+			t.Errorf("LMCACHE_MAX_LOCAL_DISK_SIZE = %s, want 200", e.Value)
+		}
+	}
+
+	llmCluster.Spec.InferenceArgs.PrefixCaching.SharedClaimName = ""
+	if env := prefixCacheEnv(llmCluster); env != nil {
+		t.Errorf("LMCache env without a shared claim = %v, want none", env)
+	}
+}
+
+func TestPodAntiAffinityPolicies(t *testing.T) {
+	ctx := context.Background()
+	newPod := func(name, node string, unschedulable bool) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "llama"}},
+			Spec:       corev1.PodSpec{NodeName: node},
+		}
+		if unschedulable {
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse,
+				Reason: corev1.PodReasonUnschedulable, Message: "0/2 nodes are available",
+			}}
+		}
+		return pod
+	}
+	pods := []client.Object{
+		newPod("llama-0", "gpu-a", false),
+		newPod("llama-1", "gpu-a", false),
+		newPod("llama-2", "", true),
+	}
+
+	tests := []struct {
+		policy     string
+		required   bool
+		preferred  bool
+		wantEvents []string
+	}{
+		{
+			policy:     "",
+			preferred:  true,
+			wantEvents: []string{"Warning AntiAffinityNotSatisfied Pods llama-0, llama-1 share node gpu-a (preferred anti-affinity)"},
+		},
+		{
+			policy:     "Required",
+			required:   true,
+			wantEvents: []string{"Warning AntiAffinityUnsatisfiable Pod llama-2 is unschedulable under required pod anti-affinity (0/2 nodes are available); set scheduling.podAntiAffinity=preferred to allow replicas to share nodes"},
+		},
+		{policy: "none"},
+	}
+	for _, tt := range tests {
+		name := tt.policy
+		if name == "" {
+			name = "default preferred"
+		}
+		t.Run(name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.Replicas = 3
+			llmCluster.Spec.Scheduling.PodAntiAffinity = tt.policy
+
+			affinity := podAntiAffinity(llmCluster)
+			if !tt.required && !tt.preferred {
+				if affinity != nil {
+					t.Errorf("affinity = %+v, want none", affinity)
+				}
+			} else {
+				anti := affinity.PodAntiAffinity
+				if got := len(anti.RequiredDuringSchedulingIgnoredDuringExecution) == 1; got != tt.required {
+					t.Errorf("required terms = %d, want required=%v", len(anti.RequiredDuringSchedulingIgnoredDuringExecution), tt.required)
+				}
+				if got := len(anti.PreferredDuringSchedulingIgnoredDuringExecution) == 1; got != tt.preferred {
+					t.Errorf("preferred terms = %d, want preferred=%v", len(anti.PreferredDuringSchedulingIgnoredDuringExecution), tt.preferred)
+				}
+			}
+
+			r, _ := newTestReconciler(append([]client.Object{llmCluster}, pods...)...)
+			if err := r.checkAntiAffinity(ctx, llmCluster); err != nil {
+				t.Fatal(err)
+			}
+			recorder := r.Recorder.(*record.FakeRecorder)
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			if !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Errorf("events = %q, want %q", events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestInferenceProbes(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		modelSize      string
+		startupTimeout int
+		want           int32
+	}{
+		{modelSize: "8B", want: 60},
+		{modelSize: "70B", want: 180},
+		{modelSize: "405B", want: 360},
+		{modelSize: "", want: 120},
+		{modelSize: "70B", startupTimeout: 95, want: 10},
+	} {
+		llmCluster := newTestCluster()
+		llmCluster.Spec.ModelSize = tt.modelSize
+		llmCluster.Spec.Probes.StartupTimeoutSeconds = tt.startupTimeout
+		if got := startupFailureThreshold(llmCluster); got != tt.want {
+			t.Errorf("startupFailureThreshold(%q, timeout %d) = %d, want %d", tt.modelSize, tt.startupTimeout, got, tt.want)
+		}
+	}
+
+	llmCluster := newTestCluster()
+	llmCluster.Spec.ModelSize = "70B"
+	llmCluster.Spec.Probes.ReadinessPeriodSeconds = 5
+	r, _ := newTestReconciler(llmCluster)
+	statefulSet, err := r.reconcileStatefulSet(ctx, llmCluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := statefulSet.Spec.Template.Spec.Containers[0]
+	for name, probe := range map[string]*corev1.Probe{"startup": engine.StartupProbe, "readiness": engine.ReadinessProbe, "liveness": engine.LivenessProbe} {
+		if probe == nil || probe.HTTPGet == nil || probe.HTTPGet.Path != "/health" {
+			t.Errorf("%s probe = %+v, want GET /health", name, probe)
+		}
+	}
+	if p := engine.StartupProbe; p != nil && (p.PeriodSeconds != startupProbePeriodSeconds || p.FailureThreshold != 180) {
+		t.Errorf("startup probe every %ds x %d, want every %ds x 180", p.PeriodSeconds, p.FailureThreshold, startupProbePeriodSeconds)
+	}
+	if p := engine.ReadinessProbe; p != nil && p.PeriodSeconds != 5 {
+		t.Errorf("readiness period = %d, want the 5s override", p.PeriodSeconds)
+	}
+}
+
+func TestLivenessProbeTiming(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		probes        servingv1alpha1.ProbeConfig
+		wantPeriod    int32
+		wantThreshold int32
+	}{
+		{name: "defaults cover 300s requests", wantPeriod: 10, wantThreshold: 30},
+		{name: "rounds up to cover max request", probes: servingv1alpha1.ProbeConfig{MaxRequestSeconds: 205, LivenessPeriodSeconds: 20}, wantPeriod: 20, wantThreshold: 11},
+		{name: "short requests keep the floor", probes: servingv1alpha1.ProbeConfig{MaxRequestSeconds: 5}, wantPeriod: 10, wantThreshold: minLivenessFailureThreshold},
+		{name: "explicit threshold wins", probes: servingv1alpha1.ProbeConfig{MaxRequestSeconds: 600, LivenessFailureThreshold: 2, LivenessPeriodSeconds: 15}, wantPeriod: 15, wantThreshold: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.Probes = tt.probes
+			probe := livenessProbe(llmCluster)
+			if probe.PeriodSeconds != tt.wantPeriod || probe.FailureThreshold != tt.wantThreshold {
+				t.Errorf("liveness every %ds x %d, want every %ds x %d", probe.PeriodSeconds, probe.FailureThreshold, tt.wantPeriod, tt.wantThreshold)
+			}
+			if tt.probes.LivenessFailureThreshold == 0 {
+				maxRequest := tt.probes.MaxRequestSeconds
+				if maxRequest == 0 {
+					maxRequest = defaultMaxRequestSeconds
+				}
+				if tolerated := int(probe.PeriodSeconds * probe.FailureThreshold); tolerated < maxRequest {
+					t.Errorf("liveness tolerates %ds of failures, less than the %ds max request", tolerated, maxRequest)
+				}
+			}
+		})
+	}
+}
+
+func TestEngineScriptWaitsForLeader(t *testing.T) {
+	const wait = `if [ "$NODE_RANK" != "0" ]; then until getent hosts "$MASTER_ADDR" >/dev/null; do sleep 2; done; fi; `
+	for _, tt := range []struct {
+		name           string
+		enabled        bool
+		leaderElection bool
+		replicas       int
+		want           bool
+	}{
+		{name: "leader election across replicas", enabled: true, leaderElection: true, replicas: 2, want: true},
+		{name: "single replica", enabled: true, leaderElection: true, replicas: 1},
+		{name: "no leader election", enabled: true, replicas: 2},
+		{name: "coordination disabled", leaderElection: true, replicas: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.Replicas = tt.replicas
+			llmCluster.Spec.Coordination.Enabled = tt.enabled
+			llmCluster.Spec.Coordination.LeaderElection = tt.leaderElection
+			script := engineScript(llmCluster)
+			if got := strings.HasPrefix(script, wait); got != tt.want {
+				t.Errorf("script %q waits for MASTER_ADDR = %v, want %v", script, got, tt.want)
+			}
+			if !strings.HasSuffix(script, `exec python -m vllm.entrypoints.openai.api_server $(cat `+configMountPath+`/`+engineArgsKey+`) "$@"`) {
+				t.Errorf("script %q does not end by exec'ing the engine", script)
+			}
+		})
+	}
+}
+
+func TestDetectCrashLoop(t *testing.T) {
+	ctx := context.Background()
+	newPod := func(policy corev1.RestartPolicy, status corev1.ContainerStatus) *corev1.Pod {
+		status.Name = "inference"
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama-0", Namespace: "default", Labels: map[string]string{"app": "llama"}},
+			Spec:       corev1.PodSpec{RestartPolicy: policy},
+			Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{status}},
+		}
+	}
+	oom := &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137, Message: "out of memory\n"}
+
+	for _, tt := range []struct {
+		name      string
+		threshold int
+		pod       *corev1.Pod
+		want      string
+	}{
+		{
+			name: "CrashLoopBackOff",
+			pod: newPod(corev1.RestartPolicyAlways, corev1.ContainerStatus{
+				RestartCount:         1,
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: oom},
+			}),
+			want: "pod llama-0 container inference CrashLoopBackOff (1 restarts): last termination OOMKilled, exit code 137: out of memory",
+		},
+		{
+			name: "restarts at the default threshold",
+			pod:  newPod(corev1.RestartPolicyAlways, corev1.ContainerStatus{RestartCount: 3}),
+			want: "pod llama-0 container inference restarting (3 restarts)",
+		},
+		{
+			name: "ready despite restarts",
+			pod:  newPod(corev1.RestartPolicyAlways, corev1.ContainerStatus{RestartCount: 5, Ready: true}),
+		},
+		{
+			name:      "below a raised threshold",
+			threshold: 10,
+			pod:       newPod(corev1.RestartPolicyAlways, corev1.ContainerStatus{RestartCount: 5}),
+		},
+		{
+			name: "never restarted pod whose engine failed",
+			pod: newPod(corev1.RestartPolicyNever, corev1.ContainerStatus{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+			}),
+			want: "pod llama-0 container inference terminated (0 restarts): last termination Error, exit code 1",
+		},
+		{
+			name: "never restarted pod that exited cleanly",
+			pod: newPod(corev1.RestartPolicyNever, corev1.ContainerStatus{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+			}),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.HighAvailability.CrashLoopRestartThreshold = tt.threshold
+			r, _ := newTestReconciler(llmCluster, tt.pod)
+			got, err := r.detectCrashLoop(ctx, llmCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("detectCrashLoop = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetConditionTransitionTime(t *testing.T) {
+	earlier := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	conditions := []servingv1alpha1.Condition{
+		{Type: "Ready", Status: "False", Reason: "Loading", LastTransitionTime: earlier},
+		{Type: "Degraded", Status: "False", LastTransitionTime: earlier},
+	}
+
+	// Same status: the reason and message update, the transition time stays
+	setCondition(&conditions, servingv1alpha1.Condition{Type: "Ready", Status: "False", Reason: "RanksNotRunning"})
+	if c := conditions[0]; c.Reason != "RanksNotRunning" || !c.LastTransitionTime.Equal(&earlier) {
+		t.Errorf("unchanged status: reason %q at %v, want RanksNotRunning at %v", c.Reason, c.LastTransitionTime, earlier)
+	}
+
+	// Status flip: the transition time moves
+	setCondition(&conditions, servingv1alpha1.Condition{Type: "Ready", Status: "True", Reason: "Ready"})
+	if c := conditions[0]; c.Status != "True" || !c.LastTransitionTime.After(earlier.Time) {
+		t.Errorf("flipped status: %s at %v, want True after %v", c.Status, c.LastTransitionTime, earlier)
+	}
+	if c := conditions[1]; !c.LastTransitionTime.Equal(&earlier) {
+		t.Errorf("other condition moved to %v", c.LastTransitionTime)
+	}
+
+	// New type: appended with a transition time
+	setCondition(&conditions, servingv1alpha1.Condition{Type: "WaitingForNodes", Status: "True"})
+	if len(conditions) != 3 || conditions[2].Type != "WaitingForNodes" || conditions[2].LastTransitionTime.IsZero() {
+		t.Errorf("conditions = %+v, want WaitingForNodes appended with a transition time", conditions)
+	}
+}
+
+func TestDrainCordonedNodes(t *testing.T) {
+	ctx := context.Background()
+	readyPod := func(name, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "llama"}},
+			Spec:       corev1.PodSpec{NodeName: node},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+		}
+	}
+	nodes := []client.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cordoned"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "healthy"}},
+	}
+	notReady := readyPod("llama-2", "healthy")
+	notReady.Status.Conditions = nil
+	terminating := readyPod("llama-2", "healthy")
+	terminating.Finalizers = []string{"test/hold"}
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	for _, tt := range []struct {
+		name        string
+		third       *corev1.Pod
+		evictErr    error
+		wantEvicted []string
+		wantEvent   string
+		wantErr     bool
+	}{
+		{
+			name:        "evicts the first cordoned pod only",
+			third:       readyPod("llama-2", "healthy"),
+			wantEvicted: []string{"llama-0"},
+			wantEvent:   "Normal PodEvicted Evicted pod llama-0 from cordoned node cordoned",
+		},
+		{name: "waits for a not-ready pod elsewhere", third: notReady},
+		{name: "waits for a terminating pod", third: terminating},
+		{
+			name:        "blocked by the disruption budget",
+			third:       readyPod("llama-2", "healthy"),
+			evictErr:    errors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0),
+			wantEvicted: []string{"llama-0"},
+			wantEvent:   "Warning EvictionBlocked Pod llama-0 on cordoned node cordoned not evicted: disruption budget exhausted",
+		},
+		{
+			name:        "other eviction errors are returned",
+			third:       readyPod("llama-2", "healthy"),
+			evictErr:    errors.NewInternalError(fmt.Errorf("boom")),
+			wantEvicted: []string{"llama-0"},
+			wantErr:     true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			r, _ := newTestReconciler()
+			var evicted []string
+			objects := append([]client.Object{llmCluster, readyPod("llama-0", "cordoned"), readyPod("llama-1", "cordoned"), tt.third}, nodes...)
+			r.Client = fake.NewClientBuilder().
+				WithScheme(r.Scheme).
+				WithObjects(objects...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					SubResourceCreate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+						if subResourceName != "eviction" {
+							return fmt.Errorf("unexpected subresource %q", subResourceName)
+						}
+						evicted = append(evicted, obj.GetName())
+						return tt.evictErr
+					},
+				}).
+				Build()
+
+			if err := r.drainCordonedNodes(ctx, llmCluster); (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(evicted, tt.wantEvicted) {
+				t.Errorf("evicted = %v, want %v", evicted, tt.wantEvicted)
+			}
+			var event string
+			select {
+			case event = <-r.Recorder.(*record.FakeRecorder).Events:
+			default:
+			}
+			if event != tt.wantEvent {
+				t.Errorf("event = %q, want %q", event, tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestValidateSpecMinGPUsByModelSize(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		modelSize  string
+		replicas   int
+		gpusPerPod int
+		overrides  map[string]int
+		wantErr    string
+	}{
+		{name: "70B on one GPU", modelSize: "70B", replicas: 1, gpusPerPod: 1,
+			wantErr: "modelSize 70B needs at least 2 GPUs (80GB, fp16), got replicas × gpusPerPod = 1 × 1 = 1"},
+		{name: "70B on two GPUs", modelSize: "70B", replicas: 1, gpusPerPod: 2},
+		{name: "405B counts GPUs across replicas", modelSize: "405B", replicas: 2, gpusPerPod: 8},
+		{name: "405B on one node", modelSize: "405B", replicas: 1, gpusPerPod: 8,
+			wantErr: "modelSize 405B needs at least 16 GPUs (80GB, fp16), got replicas × gpusPerPod = 1 × 8 = 8"},
+		{name: "override lowers the minimum", modelSize: "405B", replicas: 1, gpusPerPod: 8, overrides: map[string]int{"405B": 8}},
+		{name: "override raises the minimum", modelSize: "70B", replicas: 1, gpusPerPod: 2, overrides: map[string]int{"70B": 4},
+			wantErr: "modelSize 70B needs at least 4 GPUs"},
+		{name: "override of another size keeps the default", modelSize: "70B", replicas: 1, gpusPerPod: 1, overrides: map[string]int{"405B": 8},
+			wantErr: "modelSize 70B needs at least 2 GPUs"},
+		{name: "unknown size has no minimum", modelSize: "7B", replicas: 1, gpusPerPod: 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.ModelSize = tt.modelSize
+			llmCluster.Spec.Replicas = tt.replicas
+			llmCluster.Spec.GPUsPerPod = tt.gpusPerPod
+			r := &LLMClusterReconciler{MinGPUsByModelSize: tt.overrides}
+			err := r.validateSpec(llmCluster)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateSpec = %v, want nil", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateSpec = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseMinGPUsByModelSize(t *testing.T) {
+	got, err := parseMinGPUsByModelSize("70B=4, 405B=32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"70B": 4, "405B": 32}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMinGPUsByModelSize = %v, want %v", got, want)
+	}
+	for _, value := range []string{"70B", "70B=", "70B=0", "70B=four", "70B=4,"} {
+		if _, err := parseMinGPUsByModelSize(value); err == nil {
+			t.Errorf("parseMinGPUsByModelSize(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestClusterReadyAggregation(t *testing.T) {
+	ctx := context.Background()
+	newPod := func(name string, phase corev1.PodPhase, ready bool) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "llama"}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		if ready {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		return pod
+	}
+
+	for _, tt := range []struct {
+		name          string
+		aggregation   string
+		readyReplicas int32
+		pods          []client.Object
+		wantReady     bool
+		wantReason    string
+	}{
+		{name: "per replica, all ready", readyReplicas: 2, wantReady: true, wantReason: "AllPodsReady"},
+		{name: "per replica, one ready", readyReplicas: 1, wantReason: "PodsNotReady"},
+		{
+			name: "rank0, a rank not running", aggregation: readinessRank0,
+			pods:       []client.Object{newPod("llama-0", corev1.PodRunning, true), newPod("llama-1", corev1.PodPending, false)},
+			wantReason: "RanksNotRunning",
+		},
+		{
+			name: "rank0, rank 0 not ready", aggregation: readinessRank0, readyReplicas: 1,
+			pods:       []client.Object{newPod("llama-0", corev1.PodRunning, false), newPod("llama-1", corev1.PodRunning, true)},
+			wantReason: "Rank0NotReady",
+		},
+		{
+			// Workers run no HTTP server, so only rank 0 ever reports Ready
+			name: "rank0, rank 0 ready and all running", aggregation: readinessRank0, readyReplicas: 1,
+			pods:      []client.Object{newPod("llama-0", corev1.PodRunning, true), newPod("llama-1", corev1.PodRunning, false)},
+			wantReady: true, wantReason: "Rank0Ready",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.Replicas = 2
+			llmCluster.Spec.Probes.ReadinessAggregation = tt.aggregation
+			r, _ := newTestReconciler(append([]client.Object{llmCluster}, tt.pods...)...)
+			ready, reason, _, err := r.clusterReady(ctx, llmCluster, 2, tt.readyReplicas)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ready != tt.wantReady || reason != tt.wantReason {
+				t.Errorf("clusterReady = %v, %s; want %v, %s", ready, reason, tt.wantReady, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestPhaseAndScaleEvents(t *testing.T) {
+	llmCluster := newTestCluster()
+	recorder := record.NewFakeRecorder(10)
+	r := &LLMClusterReconciler{Recorder: recorder}
+	drain := func() []string {
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		return events
+	}
+
+	r.setPhase(llmCluster, "Creating")
+	r.setPhase(llmCluster, "Creating")
+	r.setPhase(llmCluster, "Running")
+	r.setPhase(llmCluster, "Degraded")
+	want := []string{
+		"Normal PhaseChanged Phase changed from None to Creating",
+		"Normal PhaseChanged Phase changed from Creating to Running",
+		"Warning PhaseChanged Phase changed from Running to Degraded",
+	}
+	if got := drain(); !reflect.DeepEqual(got, want) {
+		t.Errorf("phase events = %q, want %q", got, want)
+	}
+
+	// The first status write is covered by the Created event
+	r.recordScaleEvents(llmCluster, 2, 0)
+	if got := drain(); got != nil {
+		t.Errorf("first observation events = %q, want none", got)
+	}
+
+	llmCluster.Status.ObservedGeneration = 1
+	llmCluster.Status.Replicas, llmCluster.Status.ReadyReplicas = 2, 1
+	for _, tt := range []struct {
+		replicas, ready int32
+		want            []string
+	}{
+		{replicas: 2, ready: 1},
+		{replicas: 3, ready: 2, want: []string{"Normal ScaledUp Scaled from 2 to 3 replicas", "Normal BecameReady 2/3 replicas ready"}},
+		{replicas: 1, ready: 1, want: []string{"Normal ScaledDown Scaled from 2 to 1 replicas"}},
+	} {
+		r.recordScaleEvents(llmCluster, tt.replicas, tt.ready)
+		if got := drain(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("recordScaleEvents(%d, %d) = %q, want %q", tt.replicas, tt.ready, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateStrategy(t *testing.T) {
+	ctx := context.Background()
+	percent := intstr.FromString("50%")
+
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Replicas = 4
+	llmCluster.Spec.GPUsPerPod = 2
+	if s := updateStrategy(llmCluster); s.Type != appsv1.RollingUpdateStatefulSetStrategyType ||
+		s.RollingUpdate == nil || *s.RollingUpdate.Partition != 0 || s.RollingUpdate.MaxUnavailable != nil {
+		t.Errorf("default strategy = %+v, want RollingUpdate with partition 0", s)
+	}
+
+	// A canary partition rolls onto an existing StatefulSet
+	r, _ := newTestReconciler(llmCluster)
+	if _, err := r.reconcileStatefulSet(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	llmCluster.Spec.Rollout = servingv1alpha1.RolloutConfig{Partition: 3, MaxUnavailable: &percent}
+	if err := r.validateSpec(llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.reconcileStatefulSet(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	var statefulSet appsv1.StatefulSet
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama"}, &statefulSet); err != nil {
+		t.Fatal(err)
+	}
+	if ru := statefulSet.Spec.UpdateStrategy.RollingUpdate; ru == nil || *ru.Partition != 3 || ru.MaxUnavailable == nil || *ru.MaxUnavailable != percent {
+		t.Errorf("stored rolling update = %+v, want partition 3, maxUnavailable 50%%", ru)
+	}
+
+	llmCluster.Spec.Rollout = servingv1alpha1.RolloutConfig{Strategy: "OnDelete"}
+	if s := updateStrategy(llmCluster); s.Type != appsv1.OnDeleteStatefulSetStrategyType || s.RollingUpdate != nil {
+		t.Errorf("OnDelete strategy = %+v, want no rolling update", s)
+	}
+
+	bad := intstr.FromString("half")
+	for _, tt := range []struct {
+		rollout servingv1alpha1.RolloutConfig
+		wantErr string
+	}{
+		{servingv1alpha1.RolloutConfig{Partition: -1}, "rollout.partition must be >= 0, got -1"},
+		{servingv1alpha1.RolloutConfig{MaxUnavailable: &bad}, "rollout.maxUnavailable: "},
+		{servingv1alpha1.RolloutConfig{Strategy: "OnDelete", Partition: 1}, "rollout.partition and rollout.maxUnavailable require strategy RollingUpdate"},
+		{servingv1alpha1.RolloutConfig{Strategy: "OnDelete", MaxUnavailable: &percent}, "rollout.partition and rollout.maxUnavailable require strategy RollingUpdate"},
+		{servingv1alpha1.RolloutConfig{Strategy: "BlueGreen"}, `rollout.strategy must be RollingUpdate or OnDelete, got "BlueGreen"`},
+	} {
+		llmCluster.Spec.Rollout = tt.rollout
+		if err := r.validateSpec(llmCluster); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("validateSpec(%+v) = %v, want %q", tt.rollout, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGPUCapacityShortfall(t *testing.T) {
+	ctx := context.Background()
+	pendingPod := func(name, message string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "llama"}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type: corev1.PodScheduled, Status: corev1.ConditionFalse,
+					Reason: corev1.PodReasonUnschedulable, Message: message,
+				}},
+			},
+		}
+	}
+	gpuNode := func(name string, gpus int64) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				gpuResourceName: *resource.NewQuantity(gpus, resource.DecimalSI),
+			}},
+		}
+	}
+	insufficient := "0/2 nodes are available: 2 Insufficient " + string(gpuResourceName) + "."
+	pods := []client.Object{
+		pendingPod("llama-1", insufficient),
+		pendingPod("llama-2", insufficient),
+		pendingPod("llama-3", insufficient),
+		pendingPod("llama-4", "0/2 nodes are available: 2 node(s) had untolerated taint."),
+	}
+
+	for _, tt := range []struct {
+		name         string
+		antiAffinity string
+		nodes        []client.Object
+		wantNodes    int
+	}{
+		{name: "no GPU nodes yet, one pod per node", wantNodes: 3},
+		{name: "8-GPU nodes fit two 4-GPU pods", nodes: []client.Object{gpuNode("a", 4), gpuNode("b", 8)}, wantNodes: 2},
+		{name: "required anti-affinity keeps one pod per node", antiAffinity: "required", nodes: []client.Object{gpuNode("b", 8)}, wantNodes: 3},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.Replicas = 5
+			llmCluster.Spec.GPUsPerPod = 4
+			llmCluster.Spec.Scheduling.PodAntiAffinity = tt.antiAffinity
+			objects := append(append([]client.Object{llmCluster}, pods...), tt.nodes...)
+			r, _ := newTestReconciler(objects...)
+			pending, nodes, err := r.gpuCapacityShortfall(ctx, llmCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pending != 3 || nodes != tt.wantNodes {
+				t.Errorf("gpuCapacityShortfall = %d pods, %d nodes; want 3 pods, %d nodes", pending, nodes, tt.wantNodes)
+			}
+		})
+	}
+
+	// Reconcile surfaces the shortfall as WaitingForNodes, warning once
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Replicas = 5
+	llmCluster.Spec.GPUsPerPod = 4
+	r, _ := newTestReconciler()
+	r.Client = fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithObjects(append([]client.Object{llmCluster}, pods...)...).
+		WithStatusSubresource(llmCluster).
+		Build()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+	}
+	var current servingv1alpha1.LLMCluster
+	if err := r.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatal(err)
+	}
+	want := "3 pods pending on insufficient " + string(gpuResourceName) + "; about 3 more GPU nodes needed"
+	found := false
+	for _, c := range current.Status.Conditions {
+		if c.Type == "WaitingForNodes" {
+			found = true
+			if c.Status != "True" || c.Reason != "InsufficientGPUs" || c.Message != want {
+				t.Errorf("WaitingForNodes = %s/%s %q, want True/InsufficientGPUs %q", c.Status, c.Reason, c.Message, want)
+			}
+		}
+	}
+	if !found {
+		t.Error("no WaitingForNodes condition")
+	}
+	warnings := 0
+	recorder := r.Recorder.(*record.FakeRecorder)
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.HasPrefix(event, "Warning WaitingForNodes ") {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("WaitingForNodes warnings = %d over two reconciles, want 1", warnings)
+	}
+}
+
+func TestBuildHPAMetricsGPUMetric(t *testing.T) {
+	autoscaling := servingv1alpha1.AutoscalingConfig{
+		Enabled:                        true,
+		TargetCPUUtilizationPercentage: 80,
+		CustomMetric: servingv1alpha1.CustomMetric{
+			Name:   "dcgm_gpu_utilization",
+			Target: servingv1alpha1.MetricTarget{AverageValue: "70"},
+		},
+	}
+	metrics, err := buildHPAMetrics(autoscaling)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 1 || metrics[0].Type != autoscalingv2.PodsMetricSourceType || metrics[0].Pods == nil {
+		t.Fatalf("metrics = %+v, want one Pods metric", metrics)
+	}
+	pods := metrics[0].Pods
+	if pods.Metric.Name != "dcgm_gpu_utilization" || pods.Metric.Selector != nil ||
+		pods.Target.Type != autoscalingv2.AverageValueMetricType || pods.Target.AverageValue.Cmp(resource.MustParse("70")) != 0 {
+		t.Errorf("Pods metric = %+v, want dcgm_gpu_utilization at an average of 70", pods)
+	}
+
+	// External, e.g. the utilization averaged by a Prometheus adapter
+	autoscaling.CustomMetric.Type = "External"
+	autoscaling.CustomMetric.Selector = map[string]string{"model": "llama"}
+	metrics, err = buildHPAMetrics(autoscaling)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 1 || metrics[0].External == nil || metrics[0].External.Metric.Selector == nil ||
+		!reflect.DeepEqual(metrics[0].External.Metric.Selector.MatchLabels, map[string]string{"model": "llama"}) {
+		t.Errorf("metrics = %+v, want one External metric selecting model=llama", metrics)
+	}
+
+	// Without a custom metric, CPU remains the fallback
+	autoscaling.CustomMetric = servingv1alpha1.CustomMetric{}
+	metrics, err = buildHPAMetrics(autoscaling)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 1 || metrics[0].Resource == nil || metrics[0].Resource.Name != corev1.ResourceCPU ||
+		*metrics[0].Resource.Target.AverageUtilization != 80 {
+		t.Errorf("metrics = %+v, want CPU at 80%% utilization", metrics)
+	}
+
+	for _, metric := range []servingv1alpha1.CustomMetric{
+		{Name: "dcgm_gpu_utilization", Target: servingv1alpha1.MetricTarget{AverageValue: "seventy"}},
+		{Name: "dcgm_gpu_utilization", Type: "Object", Target: servingv1alpha1.MetricTarget{AverageValue: "70"}},
+	} {
+		autoscaling.CustomMetric = metric
+		if _, err := buildHPAMetrics(autoscaling); err == nil || !strings.HasPrefix(err.Error(), "invalid customMetric: ") {
+			t.Errorf("buildHPAMetrics(%+v) = %v, want an invalid customMetric error", metric, err)
+		}
+	}
+}
+
+func TestScaleToZero(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Autoscaling = servingv1alpha1.AutoscalingConfig{
+		Enabled:     true,
+		MinReplicas: 0,
+		MaxReplicas: 4,
+	}
+	r, _ := newTestReconciler()
+
+	// Nothing wakes a zero-replica cluster without an External metric
+	for _, metric := range []servingv1alpha1.CustomMetric{
+		{},
+		{Name: "vllm_num_requests_waiting", Type: "Pods", Target: servingv1alpha1.MetricTarget{AverageValue: "1"}},
+	} {
+		llmCluster.Spec.Autoscaling.CustomMetric = metric
+		if err := r.validateSpec(llmCluster); err == nil || !strings.Contains(err.Error(), "minReplicas 0 requires an External customMetric") {
+			t.Errorf("validateSpec with customMetric %+v = %v, want the External metric error", metric, err)
+		}
+	}
+	llmCluster.Spec.Autoscaling.CustomMetric = servingv1alpha1.CustomMetric{
+		Name:   "llm_queue_length",
+		Type:   "External",
+		Target: servingv1alpha1.MetricTarget{Value: "1"},
+	}
+	if err := r.validateSpec(llmCluster); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(llmCluster).WithStatusSubresource(llmCluster).Build()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama-hpa"}, &hpa); err != nil {
+		t.Fatal(err)
+	}
+	if hpa.Spec.MinReplicas == nil || *hpa.Spec.MinReplicas != 0 || len(hpa.Spec.Metrics) != 1 || hpa.Spec.Metrics[0].External == nil {
+		t.Errorf("HPA minReplicas %v metrics %+v, want 0 with the External metric", hpa.Spec.MinReplicas, hpa.Spec.Metrics)
+	}
+
+	// The HPA idles the StatefulSet; the controller keeps its replica count
+	var statefulSet appsv1.StatefulSet
+	if err := r.Get(ctx, req.NamespacedName, &statefulSet); err != nil {
+		t.Fatal(err)
+	}
+	zero := int32(0)
+	statefulSet.Spec.Replicas = &zero
+	if err := r.Update(ctx, &statefulSet); err != nil {
+		t.Fatal(err)
+	}
+	result, err := r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if result.RequeueAfter == 10*time.Second {
+		t.Error("an idle cluster requeued as if waiting to become ready")
+	}
+
+	if err := r.Get(ctx, req.NamespacedName, &statefulSet); err != nil {
+		t.Fatal(err)
+	}
+	if *statefulSet.Spec.Replicas != 0 {
+		t.Errorf("StatefulSet replicas = %d, want the HPA's 0 kept", *statefulSet.Spec.Replicas)
+	}
+	var current servingv1alpha1.LLMCluster
+	if err := r.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatal(err)
+	}
+	if current.Status.Phase != "ScaledToZero" || current.Status.Replicas != 0 || current.Status.Metrics.TotalGPUs != 0 {
+		t.Errorf("status phase %s, replicas %d, GPUs %d; want ScaledToZero, 0, 0",
+			current.Status.Phase, current.Status.Replicas, current.Status.Metrics.TotalGPUs)
+	}
+	for _, c := range current.Status.Conditions {
+		if c.Type == "Ready" && (c.Status != "False" || c.Reason != "ScaledToZero") {
+			t.Errorf("Ready = %s/%s, want False/ScaledToZero", c.Status, c.Reason)
+		}
+	}
+}
+
+func TestModelRevision(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestReconciler()
+	for revision, valid := range map[string]bool{
+		"5f0b02c75b57c5855da9ae460ce51323ea669d8a": true,
+		"v1.0":                   true,
+		"refs/pr/12":             true,
+		"release/2024-06":        true,
+		"-main":                  false,
+		"main/":                  false,
+		"a..b":                   false,
+		"main branch":            false,
+		strings.Repeat("a", 129): false,
+	} {
+		llmCluster := newTestCluster()
+		llmCluster.Spec.ModelRevision = revision
+		if err := r.validateSpec(llmCluster); (err == nil) != valid {
+			t.Errorf("validateSpec(modelRevision %q) = %v, want valid %v", revision, err, valid)
+		}
+	}
+
+	spec := newTestCluster().Spec
+	for _, arg := range buildInferenceCommand(&spec).Args {
+		if strings.HasPrefix(arg, "--revision") {
+			t.Errorf("args without a modelRevision include %s", arg)
+		}
+	}
+
+	llmCluster := newTestCluster()
+	llmCluster.Spec.ModelRevision = "v1.0"
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(llmCluster).WithStatusSubresource(llmCluster).Build()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	var current servingv1alpha1.LLMCluster
+	if err := r.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatal(err)
+	}
+	if current.Status.ModelRevision != "v1.0" {
+		t.Errorf("status.modelRevision = %q, want v1.0", current.Status.ModelRevision)
+	}
+	var configMap corev1.ConfigMap
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: configMapName(llmCluster)}, &configMap); err != nil {
+		t.Fatal(err)
+	}
+	if args := strings.Split(configMap.Data[engineArgsKey], "\n"); !hasArg(args, "--revision=v1.0") {
+		t.Errorf("rendered engine args %q lack --revision=v1.0", args)
+	}
+}
+
+func TestDeleteOwnedHPAWhenAutoscalingDisabled(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Autoscaling = servingv1alpha1.AutoscalingConfig{Enabled: true, MinReplicas: 1, MaxReplicas: 2, TargetCPUUtilizationPercentage: 80}
+	r, _ := newTestReconciler()
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(llmCluster).WithStatusSubresource(llmCluster).Build()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	hpaKey := client.ObjectKey{Namespace: "default", Name: "llama-hpa"}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if err := r.Get(ctx, hpaKey, &autoscalingv2.HorizontalPodAutoscaler{}); err != nil {
+		t.Fatalf("HPA with autoscaling enabled: %v", err)
+	}
+
+	var current servingv1alpha1.LLMCluster
+	if err := r.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatal(err)
+	}
+	current.Spec.Autoscaling.Enabled = false
+	if err := r.Update(ctx, &current); err != nil {
+		t.Fatal(err)
+	}
+	recorder := r.Recorder.(*record.FakeRecorder)
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if err := r.Get(ctx, hpaKey, &autoscalingv2.HorizontalPodAutoscaler{}); !errors.IsNotFound(err) {
+		t.Errorf("HPA after disabling autoscaling: %v, want NotFound", err)
+	}
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	if !containsPrefix(events, "Normal Deleted Deleted HPA (disabled in spec)") {
+		t.Errorf("events = %q, want the HPA deletion", events)
+	}
+
+	// Already gone: nothing to do
+	if err := r.deleteOwned(ctx, llmCluster, &autoscalingv2.HorizontalPodAutoscaler{}, "llama-hpa", "HPA"); err != nil {
+		t.Errorf("deleteOwned of a missing HPA = %v, want nil", err)
+	}
+
+	// An HPA someone else created under the same name is left alone
+	unowned := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama-hpa"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "llama"},
+			MaxReplicas:    2,
+		},
+	}
+	if err := r.Create(ctx, unowned); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.deleteOwned(ctx, llmCluster, &autoscalingv2.HorizontalPodAutoscaler{}, "llama-hpa", "HPA"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, hpaKey, &autoscalingv2.HorizontalPodAutoscaler{}); err != nil {
+		t.Errorf("unowned HPA: %v, want it kept", err)
+	}
+}
+
+func TestValidateSpecAttentionHeads(t *testing.T) {
+	r := &LLMClusterReconciler{}
+	for _, tt := range []struct {
+		name       string
+		modelSize  string
+		heads      int
+		replicas   int
+		gpusPerPod int
+		wantErr    string
+	}{
+		{name: "70B's 64 heads over 8 ranks", modelSize: "70B", replicas: 1, gpusPerPod: 8},
+		{name: "13B's 40 heads over 16 ranks", modelSize: "13B", replicas: 2, gpusPerPod: 8,
+			wantErr: `tensor-parallel size 16 (replicas × gpusPerPod) must divide the model's 40 attention heads; set inferenceArgs.numAttentionHeads if the default for modelSize "13B" is wrong`},
+		{name: "405B's 128 heads over 6 ranks", modelSize: "405B", replicas: 3, gpusPerPod: 8, wantErr: "must divide the model's 128 attention heads"},
+		{name: "explicit heads override the size default", modelSize: "13B", heads: 48, replicas: 2, gpusPerPod: 8},
+		{name: "explicit heads that don't divide", heads: 14, replicas: 1, gpusPerPod: 4, wantErr: "must divide the model's 14 attention heads"},
+		{name: "unknown model skips the check", replicas: 3, gpusPerPod: 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.ModelSize = tt.modelSize
+			llmCluster.Spec.InferenceArgs.NumAttentionHeads = tt.heads
+			llmCluster.Spec.Replicas = tt.replicas
+			llmCluster.Spec.GPUsPerPod = tt.gpusPerPod
+			err := r.validateSpec(llmCluster)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateSpec = %v, want nil", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateSpec = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReconcileMetrics(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Name, llmCluster.UID = "metrics", "metrics-uid"
+	llmCluster.Finalizers = []string{cleanupFinalizer}
+
+	r, _ := newTestReconciler()
+	failCreate := true
+	r.Client = fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithObjects(llmCluster).
+		WithStatusSubresource(llmCluster).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*appsv1.StatefulSet); ok && failCreate {
+					return errors.NewForbidden(appsv1.Resource("statefulsets"), obj.GetName(), fmt.Errorf("exceeded quota"))
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	observations := func() uint64 {
+		var m dto.Metric
+		if err := reconcileDuration.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		return m.GetHistogram().GetSampleCount()
+	}
+	successes := testutil.ToFloat64(reconcileTotal.WithLabelValues("success"))
+	failures := testutil.ToFloat64(reconcileTotal.WithLabelValues("error"))
+	statefulSetErrors := testutil.ToFloat64(reconcileErrors.WithLabelValues("StatefulSet"))
+	observed := observations()
+
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatal("Reconcile succeeded although the StatefulSet create failed")
+	}
+	failCreate = false
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	if got := testutil.ToFloat64(reconcileTotal.WithLabelValues("error")) - failures; got != 1 {
+		t.Errorf("error reconciles += %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(reconcileTotal.WithLabelValues("success")) - successes; got != 1 {
+		t.Errorf("successful reconciles += %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(reconcileErrors.WithLabelValues("StatefulSet")) - statefulSetErrors; got != 1 {
+		t.Errorf("StatefulSet errors += %v, want 1", got)
+	}
+	if got := observations() - observed; got != 2 {
+		t.Errorf("duration observations += %d, want 2", got)
+	}
+	if got := testutil.ToFloat64(readyReplicasGauge.WithLabelValues("default", "metrics")); got != 0 {
+		t.Errorf("ready replicas gauge = %v, want 0 before pods are ready", got)
+	}
+
+	// Once the cluster is gone its gauge series is dropped
+	if err := r.Delete(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if readyReplicasGauge.DeleteLabelValues("default", "metrics") {
+		t.Error("ready replicas gauge still has a series for the deleted cluster")
+	}
+}
+
+func TestClustersForWeightsConfigMap(t *testing.T) {
+	router := func(namespace, name, weights string, enabled bool) *servingv1alpha1.LLMCluster {
+		llmCluster := newTestCluster()
+		llmCluster.Namespace, llmCluster.Name = namespace, name
+		llmCluster.Spec.Router = servingv1alpha1.RouterConfig{Enabled: enabled, WeightsConfigMap: weights}
+		return llmCluster
+	}
+	r, _ := newTestReconciler(
+		router("default", "gateway", "canary-weights", true),
+		router("default", "gateway-b", "canary-weights", true),
+		router("default", "disabled", "canary-weights", false),
+		router("default", "other", "other-weights", true),
+		router("staging", "gateway", "canary-weights", true),
+	)
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "canary-weights"}}
+
+	var got []string
+	for _, req := range r.clustersForWeightsConfigMap(context.Background(), configMap) {
+		got = append(got, req.String())
+	}
+	want := []string{"default/gateway", "default/gateway-b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+
+	configMap.Name = "llama-config"
+	if reqs := r.clustersForWeightsConfigMap(context.Background(), configMap); len(reqs) != 0 {
+		t.Errorf("requests for an unrelated ConfigMap = %v, want none", reqs)
+	}
+}
+
+func TestControllerOptions(t *testing.T) {
+	if options := (&LLMClusterReconciler{}).controllerOptions(); options.MaxConcurrentReconciles != 0 || options.RateLimiter != nil {
+		t.Errorf("default options = %+v, want controller-runtime's defaults", options)
+	}
+
+	r := &LLMClusterReconciler{MaxConcurrentReconciles: 4, BackoffBase: time.Second, BackoffMax: 10 * time.Second}
+	options := r.controllerOptions()
+	if options.MaxConcurrentReconciles != 4 {
+		t.Errorf("MaxConcurrentReconciles = %d, want 4", options.MaxConcurrentReconciles)
+	}
+	if options.RateLimiter == nil {
+		t.Fatal("no rate limiter with a backoff base set")
+	}
+
+	// Failures of one LLMCluster back off exponentially up to the max,
+	// independently of other clusters, and reset once it succeeds
+	llama := ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "llama"}}
+	mistral := ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "mistral"}}
+	var delays []time.Duration
+	for i := 0; i < 6; i++ {
+		delays = append(delays, options.RateLimiter.When(llama))
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	if !reflect.DeepEqual(delays, want) {
+		t.Errorf("backoff = %v, want %v", delays, want)
+	}
+	if d := options.RateLimiter.When(mistral); d != time.Second {
+		t.Errorf("another cluster's first backoff = %v, want 1s", d)
+	}
+	options.RateLimiter.Forget(llama)
+	if d := options.RateLimiter.When(llama); d != time.Second {
+		t.Errorf("backoff after success = %v, want 1s", d)
+	}
+}
+
+func TestSchedulerName(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		override     string
+		gpuScheduler string
+		replicas     int
+		gpusPerPod   int
+		want         string
+	}{
+		{name: "spec override wins", override: "volcano", gpuScheduler: "gpu-scheduler", replicas: 2, gpusPerPod: 8, want: "volcano"},
+		{name: "multi-GPU pods use the GPU scheduler", gpuScheduler: "gpu-scheduler", replicas: 1, gpusPerPod: 8, want: "gpu-scheduler"},
+		{name: "multi-replica clusters use the GPU scheduler", gpuScheduler: "gpu-scheduler", replicas: 2, gpusPerPod: 1, want: "gpu-scheduler"},
+		{name: "single GPU stays on the default", gpuScheduler: "gpu-scheduler", replicas: 1, gpusPerPod: 1, want: corev1.DefaultSchedulerName},
+		{name: "no GPU scheduler configured", replicas: 2, gpusPerPod: 8, want: corev1.DefaultSchedulerName},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.Scheduling.SchedulerName = tt.override
+			llmCluster.Spec.Replicas = tt.replicas
+			llmCluster.Spec.GPUsPerPod = tt.gpusPerPod
+			r, _ := newTestReconciler(llmCluster)
+			r.GPUSchedulerName = tt.gpuScheduler
+			statefulSet, err := r.reconcileStatefulSet(context.Background(), llmCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := statefulSet.Spec.Template.Spec.SchedulerName; got != tt.want {
+				t.Errorf("schedulerName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomInferencePort(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Network.ContainerPort = 9000
+	r, _ := newTestReconciler(llmCluster)
+	if err := r.validateSpec(llmCluster); err != nil {
+		t.Fatal(err)
+	}
+
+	if args := buildInferenceCommand(&llmCluster.Spec).Args; !hasArg(args, "--port=9000") {
+		t.Errorf("args %v lack --port=9000", args)
+	}
+	statefulSet, err := r.reconcileStatefulSet(ctx, llmCluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := statefulSet.Spec.Template.Spec.Containers[0]
+	if len(engine.Ports) != 1 || engine.Ports[0].ContainerPort != 9000 || engine.Ports[0].Name != "http" {
+		t.Errorf("container ports = %+v, want http on 9000 serving metrics too", engine.Ports)
+	}
+
+	// A separate metrics port gets its own container port
+	llmCluster.Spec.Monitoring.MetricsPort = 9100
+	want := []corev1.ContainerPort{{Name: "http", ContainerPort: 9000}, {Name: "metrics", ContainerPort: 9100}}
+	if got := containerPorts(llmCluster); !reflect.DeepEqual(got, want) {
+		t.Errorf("containerPorts = %+v, want %+v", got, want)
+	}
+	if err := r.reconcileServices(ctx, llmCluster); err != nil {
+		t.Fatal(err)
+	}
+	var backend corev1.Service
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "llama-backend"}, &backend); err != nil {
+		t.Fatal(err)
+	}
+	if ports := backend.Spec.Ports; len(ports) != 2 || ports[0].Port != 9000 || ports[0].TargetPort != intstr.FromString("http") ||
+		ports[1].Port != 9100 || ports[1].TargetPort != intstr.FromString("metrics") {
+		t.Errorf("backend Service ports = %+v, want http 9000 and metrics 9100 by name", ports)
+	}
+
+	for _, port := range []int{masterPort, -1, 65536} {
+		llmCluster.Spec.Network.ContainerPort = port
+		want := fmt.Sprintf("network.containerPort %d is out of range or collides with the rendezvous port %d", port, masterPort)
+		if err := r.validateSpec(llmCluster); err == nil || err.Error() != want {
+			t.Errorf("validateSpec(containerPort %d) = %v, want %q", port, err, want)
+		}
+	}
+}
+
+func TestCustomMetricSpecValueTarget(t *testing.T) {
+	queue := servingv1alpha1.CustomMetric{
+		Name:     "llm_queue_length",
+		Selector: map[string]string{"cluster": "llama"},
+		Target:   servingv1alpha1.MetricTarget{Value: "20"},
+	}
+	metric, err := customMetricSpec(queue, autoscalingv2.ExternalMetricSourceType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	external := metric.External
+	if metric.Type != autoscalingv2.ExternalMetricSourceType || external == nil {
+		t.Fatalf("metric = %+v, want External", metric)
+	}
+	if external.Target.Type != autoscalingv2.ValueMetricType || external.Target.AverageValue != nil ||
+		external.Target.Value == nil || external.Target.Value.Cmp(resource.MustParse("20")) != 0 {
+		t.Errorf("target = %+v, want a total Value of 20", external.Target)
+	}
+	if external.Metric.Name != "llm_queue_length" || !reflect.DeepEqual(external.Metric.Selector.MatchLabels, queue.Selector) {
+		t.Errorf("metric identifier = %+v, want llm_queue_length selecting cluster=llama", external.Metric)
+	}
+
+	// A total makes no sense for a per-pod average
+	if _, err := customMetricSpec(queue, autoscalingv2.PodsMetricSourceType); err == nil ||
+		err.Error() != `target.value needs type External, got "Pods"` {
+		t.Errorf("Pods metric with target.value: %v, want the External error", err)
+	}
+	queue.Target.Value = "lots"
+	if _, err := customMetricSpec(queue, autoscalingv2.ExternalMetricSourceType); err == nil || !strings.HasPrefix(err.Error(), `target "lots": `) {
+		t.Errorf("unparsable target.value: %v, want a parse error", err)
+	}
+
+	// Without a value, the average stays the target
+	queue.Target = servingv1alpha1.MetricTarget{AverageValue: "5"}
+	metric, err = customMetricSpec(queue, autoscalingv2.ExternalMetricSourceType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target := metric.External.Target; target.Type != autoscalingv2.AverageValueMetricType || target.Value != nil ||
+		target.AverageValue.Cmp(resource.MustParse("5")) != 0 {
+		t.Errorf("target = %+v, want an AverageValue of 5", target)
+	}
+}
+
+func TestValidateSpecPDBMinAvailable(t *testing.T) {
+	r := &LLMClusterReconciler{}
+	for _, tt := range []struct {
+		name         string
+		minAvailable int
+		replicas     int
+		autoscaling  servingv1alpha1.AutoscalingConfig
+		wantErr      string
+	}{
+		{name: "below replicas", minAvailable: 2, replicas: 3},
+		{name: "equal to replicas", minAvailable: 3, replicas: 3},
+		{name: "above replicas", minAvailable: 4, replicas: 3,
+			wantErr: "highAvailability.podDisruptionBudget.minAvailable 4 exceeds replicas 3"},
+		{name: "within minReplicas", minAvailable: 2, replicas: 3,
+			autoscaling: servingv1alpha1.AutoscalingConfig{Enabled: true, MinReplicas: 2, MaxReplicas: 6}},
+		{name: "above minReplicas", minAvailable: 3, replicas: 3,
+			autoscaling: servingv1alpha1.AutoscalingConfig{Enabled: true, MinReplicas: 2, MaxReplicas: 6},
+			wantErr:     "highAvailability.podDisruptionBudget.minAvailable 3 exceeds autoscaling.minReplicas 2; once scaled in, no pod could be evicted"},
+		{name: "minReplicas ignored with autoscaling off", minAvailable: 3, replicas: 3,
+			autoscaling: servingv1alpha1.AutoscalingConfig{MinReplicas: 1, MaxReplicas: 6}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			llmCluster := newTestCluster()
+			llmCluster.Spec.Replicas = tt.replicas
+			llmCluster.Spec.GPUsPerPod = 1
+			llmCluster.Spec.Autoscaling = tt.autoscaling
+			llmCluster.Spec.HighAvailability.PodDisruptionBudget = servingv1alpha1.PDBConfig{Enabled: true, MinAvailable: tt.minAvailable}
+			err := r.validateSpec(llmCluster)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateSpec = %v, want nil", err)
+				}
+			} else if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("validateSpec = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEffectiveArgsAndImageInStatus(t *testing.T) {
+	ctx := context.Background()
+	llmCluster := newTestCluster()
+	llmCluster.Spec.Image = "vllm/vllm-openai:v0.5.0"
+	llmCluster.Spec.Network.TLS = servingv1alpha1.TLSConfig{Enabled: true, SecretName: "llama-tls"}
+	r, _ := newTestReconciler()
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(llmCluster).WithStatusSubresource(llmCluster).Build()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(llmCluster)}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	var current servingv1alpha1.LLMCluster
+	if err := r.Get(ctx, req.NamespacedName, &current); err != nil {
+		t.Fatal(err)
+	}
+	var statefulSet appsv1.StatefulSet
+	if err := r.Get(ctx, req.NamespacedName, &statefulSet); err != nil {
+		t.Fatal(err)
+	}
+	var configMap corev1.ConfigMap
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: configMapName(llmCluster)}, &configMap); err != nil {
+		t.Fatal(err)
+	}
+	engine := statefulSet.Spec.Template.Spec.Containers[0]
+
+	if current.Status.EffectiveImage != engine.Image || engine.Image != "vllm/vllm-openai:v0.5.0" {
+		t.Errorf("status.effectiveImage = %q, container image %q; want both vllm/vllm-openai:v0.5.0", current.Status.EffectiveImage, engine.Image)
+	}
+
+	// What the container actually runs: the entrypoint, the args file, then
+	// the container args appended through "$@"
+	launched := append([]string{}, buildInferenceCommand(&llmCluster.Spec).Entrypoint...)
+	launched = append(launched, strings.Fields(configMap.Data[engineArgsKey])...)
+	launched = append(launched, engine.Args...)
+	if !reflect.DeepEqual(current.Status.EffectiveArgs, launched) {
+		t.Errorf("status.effectiveArgs =\n%q\nwant what the container launches:\n%q", current.Status.EffectiveArgs, launched)
+	}
+	if !hasArg(current.Status.EffectiveArgs, "--ssl-certfile="+tlsMountPath+"/tls.crt") {
+		t.Errorf("status.effectiveArgs %q lack the TLS args", current.Status.EffectiveArgs)
+	}
+}
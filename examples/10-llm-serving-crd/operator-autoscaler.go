@@ -12,43 +12,120 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"text/template"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 )
 
 const (
-	defaultSyncInterval       = 30 * time.Second
-	defaultScaleUpCooldown    = 120
-	defaultScaleDownCooldown  = 600
-	defaultPrometheusAddress  = "http://prometheus:9090"
-	defaultRouterBackendPort  = 8000
-	defaultDrainDelay         = 30 * time.Second
-	annotationLastScaleUp     = "autoscaling.serving.ai/last-scale-up-epoch"
-	annotationLastScaleDown   = "autoscaling.serving.ai/last-scale-down-epoch"
-	annotationLastAction      = "autoscaling.serving.ai/last-action"
-	annotationCurrentInstance = "autoscaling.serving.ai/current-instances"
+	defaultSyncInterval      = 30 * time.Second
+	defaultScaleUpCooldown   = 120
+	defaultScaleDownCooldown = 600
+	defaultPrometheusAddress = "http://prometheus:9090"
+	defaultRouterBackendPort = 8000
+	defaultDrainDelay        = 30 * time.Second
+	defaultMetricsHistory    = 10
+	annotationScaleDownStep  = "autoscaling.serving.ai/gradual-scale-down-target"
+	annotationDrainingSince  = "autoscaling.serving.ai/draining-since-epoch"
+	annotationProtected      = "autoscaling.serving.ai/protected"
+	labelManagedBy           = "autoscaling.serving.ai/managed-by"
+
+	// Decision Events are annotated with the observed metric values and,
+	// when Blocked, the llmcluster_autoscaler_blocked reason label.
+	eventAnnotationObservedPrefix = "autoscaling.serving.ai/observed-"
+	eventAnnotationBlockedReason  = "autoscaling.serving.ai/blocked-reason"
+
+	// Scale times were annotations before they moved to status; they are
+	// still read while status has none, so an upgrade keeps the cooldown.
+	annotationLegacyLastScaleUp   = "autoscaling.serving.ai/last-scale-up-epoch"
+	annotationLegacyLastScaleDown = "autoscaling.serving.ai/last-scale-down-epoch"
+
+	scaleDownModeBatch   = "batch"
+	scaleDownModeGradual = "gradual"
+
+	// How per-metric threshold checks combine into a decision: any metric
+	// above scale-up scales up (default), all of them must be, or their
+	// weighted threshold-normalized score is compared against 1
+	decisionModeAny      = "any"
+	decisionModeAll      = "all"
+	decisionModeWeighted = "weighted"
+
+	// Scale-down victim selection: the newest instance, or the one with the
+	// lowest per-instance load (falling back to newest without metrics)
+	scaleDownPolicyNewest              = "Newest"
+	scaleDownPolicyLeastActiveRequests = "LeastActiveRequests"
+	scaleDownPolicyLeastGPUUtilization = "LeastGPUUtilization"
+
+	// modeRecommend publishes status.recommendation without creating,
+	// deleting, or re-routing any instance.
+	modeRecommend = "recommend"
+
+	// Metric aggregations over the series a query returns
+	aggregationSum = "sum"
+	aggregationAvg = "avg"
+	aggregationMax = "max"
+
+	// modeReplicas scales spec.replicas of a single LLMCluster
+	// (scaleTargetRef.name) through its scale subresource.
+	modeReplicas = "replicas"
+
+	// tracerName names the spans of autoscaler reconciles
+	tracerName = "llmcluster-autoscaler"
+
+	// readyMetricMatcher selects series from Ready pods only: those without
+	// the serving.ai/loading label, which the LLMCluster controller keeps on
+	// pods until Ready (highAvailability.protectLoadingPods) and its
+	// PodMonitor copies into Prometheus as serving_ai_loading. An empty
+	// matcher also matches series that lack the label.
+	readyMetricMatcher = `serving_ai_loading=""`
 )
 
 type metricPolicy struct {
@@ -56,15 +133,115 @@ type metricPolicy struct {
 	Query     string
 	ScaleUp   float64
 	ScaleDown float64
+
+	// Schedules override ScaleUp/ScaleDown during matching time windows.
+	// The first matching window wins.
+	Schedules []thresholdSchedule
+
+	// Panic lets a scale-up triggered by this metric skip the scale-up
+	// cooldown (default on for RejectedRequestRate: 429s mean requests are
+	// already being dropped).
+	Panic bool
+
+	// Aggregation (sum, avg or max) combines the series the query returns,
+	// e.g. one per instance, before the threshold comparison.
+	Aggregation string
+
+	// Weight is the metric's share of the weighted decision score
+	// (default 1).
+	Weight float64
+
+	// Window is the PromQL range the query's rate/deriv looks back over
+	// (default 2m); widen it for low-traffic services that leave short
+	// windows without samples.
+	Window string
+}
+
+// metricObservation is one metric's aggregated value and the thresholds in
+// effect when it was read.
+type metricObservation struct {
+	Metric    metricPolicy
+	Value     float64
+	ScaleUp   float64
+	ScaleDown float64
+}
+
+// prometheusAuth authenticates queries to a Prometheus behind bearer-token
+// or basic auth and/or TLS (Thanos, Cortex, managed Prometheus). The Secrets
+// are read from the autoscaler's namespace.
+type prometheusAuth struct {
+	BearerTokenSecret secretKeyRef
+
+	// BasicAuthSecret names a Secret with username and password keys
+	BasicAuthSecret string
+
+	// CASecret holds the PEM CA bundle verifying the server certificate
+	CASecret           secretKeyRef
+	InsecureSkipVerify bool
+}
+
+type secretKeyRef struct {
+	Name string
+	Key  string
+}
+
+func (a prometheusAuth) enabled() bool {
+	return a.BearerTokenSecret.Name != "" || a.BasicAuthSecret != "" || a.CASecret.Name != "" || a.InsecureSkipVerify
+}
+
+// thresholdSchedule is a daily time window with threshold overrides.
+// Windows with End before Start wrap past midnight.
+type thresholdSchedule struct {
+	StartMinute int
+	EndMinute   int
+	Days        map[time.Weekday]bool
+	Location    *time.Location
+	ScaleUp     float64
+	ScaleDown   float64
+}
+
+// operatorDefaults are operator-wide values used when an autoscaler's spec
+// omits them, loaded at startup from the --defaults-configmap ConfigMap:
+//
+//	prometheusAddress:             http://prometheus.monitoring:9090
+//	scaleUpStabilizationSeconds:   "120"
+//	scaleDownStabilizationSeconds: "600"
+//	query.<MetricType>:            PromQL template used when a metric has no query
+//	namespaceMaxInstances:         "12" (instances per namespace, all autoscalers)
+type operatorDefaults struct {
+	PrometheusAddress        string
+	ScaleUpCooldownSeconds   int
+	ScaleDownCooldownSeconds int
+	Queries                  map[string]string
+
+	// NamespaceMaxInstances caps the autoscaler-managed instances in a
+	// namespace, summed over every autoscaler there; 0 means no cap
+	NamespaceMaxInstances int
+}
+
+func builtinDefaults() operatorDefaults {
+	return operatorDefaults{
+		PrometheusAddress:        defaultPrometheusAddress,
+		ScaleUpCooldownSeconds:   defaultScaleUpCooldown,
+		ScaleDownCooldownSeconds: defaultScaleDownCooldown,
+		Queries:                  map[string]string{},
+	}
 }
 
 type autoscalerPolicy struct {
 	Namespace string
 	Name      string
+	Mode      string
 
 	PrometheusAddress string
+	ViaAPIServerProxy bool
+	PrometheusAuth    prometheusAuth
 	AppLabel          string
 	LabelSelector     string
+	ReadyOnly         bool
+
+	// TargetName is the LLMCluster scaled in replicas mode
+	TargetName string
 
 	MinInstances int
 	MaxInstances int
@@ -82,6 +259,49 @@ type autoscalerPolicy struct {
 
 	ScaleUpCooldownSeconds   int
 	ScaleDownCooldownSeconds int
+
+	// ScaleDownMode is "batch" (one removal per scale-down cooldown) or
+	// "gradual" (one removal per sync interval, each step verified and
+	// re-evaluated, halting as soon as metrics recover).
+	ScaleDownMode string
+
+	// DecisionMode is decisionModeAny, decisionModeAll or
+	// decisionModeWeighted; see combineObservations.
+	DecisionMode string
+
+	// ScaleDownPolicy picks which instance a scale-down removes
+	// (scaleDownPolicyNewest, ...LeastActiveRequests, ...LeastGPUUtilization).
+	ScaleDownPolicy string
+
+	// DrainGraceSeconds is how long a scaled-down instance stays in the
+	// router marked draining (no new requests, in-flight streams continue)
+	// before it is removed from the router; 0 removes it right away.
+	DrainGraceSeconds int
+
+	// ScaleUpResetsScaleDown restarts the scale-down cooldown on every
+	// scale-up, so a transient spike can't be followed by an immediate
+	// shrink; ScaleDownResetsScaleUp is the reverse.
+	ScaleUpResetsScaleDown bool
+	ScaleDownResetsScaleUp bool
+
+	// MetricsHistoryLimit is how many timestamped observedMetrics samples
+	// status.observedMetricsHistory keeps; 0 disables the history.
+	MetricsHistoryLimit int
+
+	// ScaleUpWindowSeconds and ScaleDownWindowSeconds are how long a
+	// threshold must stay breached, in consecutive sync-interval samples,
+	// before the decision scales; 0 acts on a single sample. They are not
+	// the behavior.*StabilizationSeconds fields, which already mean the
+	// cooldown after a scale action (ScaleUpCooldownSeconds above); reusing
+	// those would make every existing autoscaler wait out its cooldown
+	// before its first scale-up as well.
+	ScaleUpWindowSeconds   int
+	ScaleDownWindowSeconds int
+
+	// ScaleUpMaxStep and ScaleUpMaxPercent (of the current count, 0 = off)
+	// cap how many instances one scale-up adds; the larger cap applies.
+	ScaleUpMaxStep    int
+	ScaleUpMaxPercent int
 }
 
 type scaleDecision struct {
@@ -91,6 +311,72 @@ type scaleDecision struct {
 	Reason           string
 	MetricsAvailable bool
 	Observed         map[string]float64
+
+	// Panic is set when a Panic metric triggered the scale-up; the
+	// scale-up cooldown is skipped.
+	Panic bool
+
+	// Overload is the largest value/threshold ratio among the metrics above
+	// their scale-up threshold (+Inf for a threshold of 0).
+	Overload float64
+}
+
+// metricQuerier evaluates an instant query against a metrics backend and
+// returns one value per series (a scalar result is a single value). An
+// empty result means the query succeeded but returned no samples.
+type metricQuerier interface {
+	Query(ctx context.Context, address, query string) (values []float64, err error)
+}
+
+// cycleQueryCache is a metricQuerier that sends each distinct (address,
+// query) pair once per sync cycle. Autoscalers for different apps render
+// different PromQL, so only truly identical queries share a result.
+// Reconciles run sequentially, so it needs no locking.
+type cycleQueryCache struct {
+	results map[[2]string]cachedQueryResult
+	hits    int
+}
+
+type cachedQueryResult struct {
+	values []float64
+	err    error
+}
+
+// cachingQuerier routes one querier's requests through the cycle cache.
+type cachingQuerier struct {
+	next  metricQuerier
+	cache *cycleQueryCache
+}
+
+func (q cachingQuerier) Query(ctx context.Context, address, query string) ([]float64, error) {
+	key := [2]string{address, query}
+	if result, ok := q.cache.results[key]; ok {
+		q.cache.hits++
+		return result.values, result.err
+	}
+	values, err := q.next.Query(ctx, address, query)
+	q.cache.results[key] = cachedQueryResult{values: values, err: err}
+	return values, err
+}
+
+// prometheusQuerier is the default metricQuerier backed by the Prometheus HTTP API.
+type prometheusQuerier struct {
+	httpClient *http.Client
+
+	// bearerToken, or bearerTokenFile re-read on every query so a rotated
+	// token is picked up, is sent as Authorization: Bearer; username and
+	// password as basic auth instead.
+	bearerToken     string
+	bearerTokenFile string
+	username        string
+	password        string
+}
+
+// authQuerierEntry is an autoscaler's credentialed querier and the
+// fingerprint of the Secret contents it was built from.
+type authQuerierEntry struct {
+	fingerprint string
+	querier     *prometheusQuerier
 }
 
 type controller struct {
@@ -98,14 +384,190 @@ type controller struct {
 
 	autoscalerGVR schema.GroupVersionResource
 	llmclusterGVR schema.GroupVersionResource
+	podGVR        schema.GroupVersionResource
+	pdbGVR        schema.GroupVersionResource
+	secretGVR     schema.GroupVersionResource
 
-	httpClient   *http.Client
+	querier      metricQuerier
 	syncInterval time.Duration
+	queryTimeout time.Duration
 	drainDelay   time.Duration
+	defaults     operatorDefaults
+
+	// authQueriers holds per-autoscaler queriers for spec.prometheus
+	// credentials and TLS, rebuilt when the Secrets change.
+	authQueriers map[string]authQuerierEntry
+
+	// socketQueriers holds a querier per unix:// Prometheus socket path.
+	socketQueriers map[string]*prometheusQuerier
+
+	// proxyQuerier sends queries through the kube-apiserver service proxy
+	// using the operator's own credentials; nil if no rest config was given.
+	proxyQuerier  metricQuerier
+	apiServerHost string
+
+	// shareQueries enables queryCache, reset at the start of every cycle.
+	shareQueries bool
+	queryCache   *cycleQueryCache
+
+	// recorder emits Events on autoscalers; metrics backs /metrics.
+	recorder record.EventRecorder
+	metrics  *autoscalerMetrics
+
+	// lastReconcile holds the last published outcome per autoscaler so an
+	// unchanged fleet doesn't rewrite identical status every sync interval.
+	lastReconcile map[string]reconcileSnapshot
+	verbose       bool
+
+	// recentDecisions holds the latest raw decisions per autoscaler for the
+	// scale-up/scale-down windows, newest last.
+	recentDecisions map[string][]scaleDecision
+
+	// unsavedScaleTimes holds scale times per autoscaler (by status field)
+	// whose status write failed, so the cooldown still applies and the next
+	// successful write persists them.
+	unsavedScaleTimes map[string]map[string]time.Time
+}
+
+// autoscalerMetrics holds the counters and gauges served on /metrics.
+type autoscalerMetrics struct {
+	mu sync.Mutex
+	// blocked counts Blocked reconciles by namespace, autoscaler and reason
+	blocked map[[3]string]float64
+	// instances is the last published current and desired instance count
+	// by namespace and autoscaler
+	instances map[[2]string][2]int
+}
+
+func newAutoscalerMetrics() *autoscalerMetrics {
+	return &autoscalerMetrics{
+		blocked:   map[[3]string]float64{},
+		instances: map[[2]string][2]int{},
+	}
+}
+
+func (m *autoscalerMetrics) setInstances(namespace, name string, current, desired int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.instances[[2]string{namespace, name}] = [2]int{current, desired}
+}
+
+// retainInstances drops the gauges of autoscalers not in live, so a deleted
+// autoscaler doesn't look stuck forever.
+func (m *autoscalerMetrics) retainInstances(live map[[2]string]bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k := range m.instances {
+		if !live[k] {
+			delete(m.instances, k)
+		}
+	}
+}
+
+func (m *autoscalerMetrics) incBlocked(namespace, name, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blocked[[3]string{namespace, name, reason}]++
+}
+
+// render writes the metrics in the Prometheus text format.
+func (m *autoscalerMetrics) render() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([][3]string, 0, len(m.blocked))
+	for k := range m.blocked {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		for n := range keys[i] {
+			if keys[i][n] != keys[j][n] {
+				return keys[i][n] < keys[j][n]
+			}
+		}
+		return false
+	})
+
+	var b strings.Builder
+	b.WriteString("# HELP llmcluster_autoscaler_blocked Reconciles that ended Blocked, by reason.\n")
+	b.WriteString("# TYPE llmcluster_autoscaler_blocked counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "llmcluster_autoscaler_blocked{namespace=%q,autoscaler=%q,reason=%q} %g\n", k[0], k[1], k[2], m.blocked[k])
+	}
+
+	instanceKeys := make([][2]string, 0, len(m.instances))
+	for k := range m.instances {
+		instanceKeys = append(instanceKeys, k)
+	}
+	sort.Slice(instanceKeys, func(i, j int) bool {
+		if instanceKeys[i][0] != instanceKeys[j][0] {
+			return instanceKeys[i][0] < instanceKeys[j][0]
+		}
+		return instanceKeys[i][1] < instanceKeys[j][1]
+	})
+	for n, name := range []string{"current", "desired"} {
+		fmt.Fprintf(&b, "# HELP llmcluster_autoscaler_%s_instances The autoscaler's %s instance (or replica) count.\n", name, name)
+		fmt.Fprintf(&b, "# TYPE llmcluster_autoscaler_%s_instances gauge\n", name)
+		for _, k := range instanceKeys {
+			fmt.Fprintf(&b, "llmcluster_autoscaler_%s_instances{namespace=%q,autoscaler=%q} %d\n", name, k[0], k[1], m.instances[k][n])
+		}
+	}
+	return b.String()
+}
+
+// blockedReasons maps Blocked action reasons to a bounded metric label, so
+// alerts can target e.g. router detach failures. Anything else means the
+// metrics needed for a decision were unavailable.
+var blockedReasons = []struct {
+	prefix string
+	reason string
+}{
+	{"scale-up create failed", "scale_up_create_failed"},
+	{"namespace instance count failed", "namespace_count_failed"},
+	{"NamespaceCapReached", "namespace_cap_reached"},
+	{"PDB check failed", "pdb_check_failed"},
+	{"scale-down of", "pdb_violation"},
+	{"router detach failed", "router_detach_failed"},
+	{"scale-down delete failed", "scale_down_delete_failed"},
+	{"router reconcile failed", "router_reconcile_failed"},
+	{"conflict:", "bounds_conflict"},
+	{"scale update failed", "scale_update_failed"},
+}
+
+func blockedReason(actionReason string) string {
+	for _, r := range blockedReasons {
+		if strings.HasPrefix(actionReason, r.prefix) {
+			return r.reason
+		}
+	}
+	return "metrics_unavailable"
 }
 
-func newController(dynamicClient dynamic.Interface, syncInterval, queryTimeout, drainDelay time.Duration) *controller {
-	return &controller{
+// reconcileSnapshot is the part of a reconcile outcome that is published to status.
+type reconcileSnapshot struct {
+	Action           string
+	Reason           string
+	CurrentInstances int
+	DesiredInstances int
+	Observed         map[string]float64
+}
+
+func (s reconcileSnapshot) equal(other reconcileSnapshot) bool {
+	if s.Action != other.Action || s.Reason != other.Reason ||
+		s.CurrentInstances != other.CurrentInstances || s.DesiredInstances != other.DesiredInstances ||
+		len(s.Observed) != len(other.Observed) {
+		return false
+	}
+	for k, v := range s.Observed {
+		if ov, ok := other.Observed[k]; !ok || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
+func newController(dynamicClient dynamic.Interface, restConfig *rest.Config, syncInterval, queryTimeout, drainDelay time.Duration) (*controller, error) {
+	c := &controller{
 		dynamicClient: dynamicClient,
 		autoscalerGVR: schema.GroupVersionResource{
 			Group:    "serving.ai",
@@ -117,12 +579,51 @@ func newController(dynamicClient dynamic.Interface, syncInterval, queryTimeout,
 			Version:  "v1alpha1",
 			Resource: "llmclusters",
 		},
-		httpClient: &http.Client{
-			Timeout: queryTimeout,
+		podGVR: schema.GroupVersionResource{
+			Version:  "v1",
+			Resource: "pods",
+		},
+		pdbGVR: schema.GroupVersionResource{
+			Group:    "policy",
+			Version:  "v1",
+			Resource: "poddisruptionbudgets",
+		},
+		secretGVR: schema.GroupVersionResource{
+			Version:  "v1",
+			Resource: "secrets",
+		},
+		querier: &prometheusQuerier{
+			httpClient: &http.Client{
+				Timeout: queryTimeout,
+			},
 		},
-		syncInterval: syncInterval,
-		drainDelay:   drainDelay,
+		syncInterval:  syncInterval,
+		queryTimeout:  queryTimeout,
+		drainDelay:    drainDelay,
+		authQueriers:  map[string]authQuerierEntry{},
+		defaults:      builtinDefaults(),
+		metrics:       newAutoscalerMetrics(),
+		lastReconcile: map[string]reconcileSnapshot{},
+
+		recentDecisions:   map[string][]scaleDecision{},
+		unsavedScaleTimes: map[string]map[string]time.Time{},
+		socketQueriers:    map[string]*prometheusQuerier{},
+	}
+
+	if restConfig != nil {
+		transport, err := rest.TransportFor(restConfig)
+		if err != nil {
+			return nil, fmt.Errorf("build apiserver proxy transport: %w", err)
+		}
+		c.proxyQuerier = &prometheusQuerier{
+			httpClient: &http.Client{
+				Transport: transport,
+				Timeout:   queryTimeout,
+			},
+		}
+		c.apiServerHost = restConfig.Host
 	}
+	return c, nil
 }
 
 func (c *controller) run(ctx context.Context) {
@@ -152,33 +653,94 @@ func (c *controller) reconcileAll(ctx context.Context) {
 		return
 	}
 
+	if c.shareQueries {
+		c.queryCache = &cycleQueryCache{results: map[[2]string]cachedQueryResult{}}
+		defer func() {
+			c.debugf("query cache: %d queries sent, %d shared", len(c.queryCache.results), c.queryCache.hits)
+		}()
+	}
+
+	live := make(map[[2]string]bool, len(list.Items))
 	for i := range list.Items {
 		item := &list.Items[i]
-		if err := c.reconcileAutoscaler(ctx, item); err != nil {
+		live[[2]string{item.GetNamespace(), item.GetName()}] = true
+		spanCtx, span := otel.Tracer(tracerName).Start(ctx, "reconcileAutoscaler", trace.WithAttributes(
+			attribute.String("autoscaler.namespace", item.GetNamespace()),
+			attribute.String("autoscaler.name", item.GetName())))
+		if err := c.reconcileAutoscaler(spanCtx, item); err != nil {
 			log.Printf("reconcile %s/%s failed: %v", item.GetNamespace(), item.GetName(), err)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	c.metrics.retainInstances(live)
+	for key := range c.recentDecisions {
+		namespace, name, _ := strings.Cut(key, "/")
+		if !live[[2]string{namespace, name}] {
+			delete(c.recentDecisions, key)
+		}
+	}
+	for key := range c.authQueriers {
+		namespace, name, _ := strings.Cut(key, "/")
+		if !live[[2]string{namespace, name}] {
+			delete(c.authQueriers, key)
+		}
+	}
+	for key := range c.unsavedScaleTimes {
+		namespace, name, _ := strings.Cut(key, "/")
+		if !live[[2]string{namespace, name}] {
+			delete(c.unsavedScaleTimes, key)
+		}
+	}
+	// A recreated autoscaler starts with an empty status, so a snapshot
+	// left from its predecessor would suppress its first status write
+	for key := range c.lastReconcile {
+		namespace, name, _ := strings.Cut(key, "/")
+		if !live[[2]string{namespace, name}] {
+			delete(c.lastReconcile, key)
 		}
 	}
 }
 
 func (c *controller) reconcileAutoscaler(ctx context.Context, autoscaler *unstructured.Unstructured) error {
-	policy, err := parsePolicy(autoscaler)
+	policy, err := parsePolicy(autoscaler, c.defaults)
 	if err != nil {
 		return fmt.Errorf("parse policy: %w", err)
 	}
 
-	instances, err := c.listManagedInstances(ctx, policy.Namespace, policy.LabelSelector, policy.RouterName)
+	if policy.Mode == modeReplicas {
+		return c.reconcileReplicas(ctx, autoscaler, policy)
+	}
+
+	listed, err := c.listManagedInstances(ctx, policy.Namespace, policy.LabelSelector, policy.RouterName)
 	if err != nil {
 		return fmt.Errorf("list managed instances: %w", err)
 	}
+	now := time.Now()
 
 	decision, err := c.evaluateDecision(ctx, policy)
 	if err != nil {
 		return fmt.Errorf("evaluate decision: %w", err)
 	}
 
+	// Recommend mode only publishes, so it returns before drains advance:
+	// finishing a drain deletes the instance.
+	if policy.Mode == modeRecommend {
+		desired := recommendedInstances(policy, decision, len(listed))
+		return c.publishStatus(ctx, autoscaler, policy, decision, "NoOp", decision.Reason, len(listed), desired)
+	}
+
+	// Scale-down victims drain across reconciles instead of blocking this
+	// one; while draining they no longer count as capacity.
+	instances, draining := c.advanceDrains(ctx, policy, listed, now)
+
+	// What the metrics call for, even when a cooldown or failure below
+	// keeps the fleet where it is; desired > current for long means stuck.
+	desired := recommendedInstances(policy, decision, len(instances))
+
 	action := "NoOp"
 	actionReason := decision.Reason
-	now := time.Now()
 
 	if !decision.MetricsAvailable {
 		action = "Blocked"
@@ -187,22 +749,69 @@ func (c *controller) reconcileAutoscaler(ctx context.Context, autoscaler *unstru
 		}
 	}
 
+	stepTarget := ""
+	if policy.ScaleDownMode == scaleDownModeGradual {
+		stepTarget = strings.TrimSpace(autoscaler.GetAnnotations()[annotationScaleDownStep])
+	}
+	if stepTarget != "" && !(decision.MetricsAvailable && decision.ScaleDown && len(instances) > policy.MinInstances) {
+		// Metrics recovered (or floor reached) mid-shrink: stop the sequence so
+		// any further scale-down waits for the full cooldown again.
+		if err := c.patchAutoscalerAnnotations(ctx, policy.Namespace, policy.Name, map[string]string{
+			annotationScaleDownStep: "",
+		}); err != nil {
+			log.Printf("warning: clear gradual scale-down annotation failed: %v", err)
+		}
+		log.Printf("%s/%s gradual scale-down halted at %d instances", policy.Namespace, policy.Name, len(instances))
+		stepTarget = ""
+	}
+
+	_, scaleSpan := startSpan(ctx, "scale", policy)
 	if decision.MetricsAvailable {
 		switch {
 		case decision.ScaleUp && len(instances) < policy.MaxInstances:
-			if c.scaleCooldownPassed(autoscaler, true, policy.ScaleUpCooldownSeconds, now) {
-				newName, createErr := c.createInstance(ctx, policy, autoscaler, instances)
-				if createErr != nil {
+			if decision.Panic || c.scaleCooldownPassed(autoscaler, true, policy.ScaleUpCooldownSeconds, policy.ScaleDownResetsScaleUp, now) {
+				count := desired - len(instances)
+				if limit := c.defaults.NamespaceMaxInstances; limit > 0 {
+					total, err := c.countNamespaceInstances(ctx, policy.Namespace)
+					if err != nil {
+						action = "Blocked"
+						actionReason = fmt.Sprintf("namespace instance count failed: %v", err)
+						break
+					}
+					if total >= limit {
+						action = "Blocked"
+						actionReason = fmt.Sprintf("NamespaceCapReached: %d autoscaler-managed instances in %s (cap %d)",
+							total, policy.Namespace, limit)
+						break
+					}
+					if count > limit-total {
+						count = limit - total
+					}
+				}
+
+				var created []string
+				var createErr error
+				existing := append([]*unstructured.Unstructured(nil), instances...)
+				for len(created) < count {
+					newName, err := c.createInstance(ctx, policy, autoscaler, existing)
+					if err != nil {
+						createErr = err
+						break
+					}
+					created = append(created, newName)
+					// Only the name matters for picking the next one
+					placeholder := &unstructured.Unstructured{}
+					placeholder.SetName(newName)
+					existing = append(existing, placeholder)
+				}
+				if len(created) == 0 {
 					action = "Blocked"
 					actionReason = fmt.Sprintf("scale-up create failed: %v", createErr)
 				} else {
 					action = "ScaleUp"
-					actionReason = fmt.Sprintf("created %s (%s)", newName, decision.Trigger)
-					if err := c.patchAutoscalerAnnotations(ctx, policy.Namespace, policy.Name, map[string]string{
-						annotationLastScaleUp: strconv.FormatInt(now.Unix(), 10),
-						annotationLastAction:  actionReason,
-					}); err != nil {
-						log.Printf("warning: patch scale-up annotation failed: %v", err)
+					actionReason = fmt.Sprintf("created %s (%s)", strings.Join(created, ", "), decision.Trigger)
+					if createErr != nil {
+						actionReason += fmt.Sprintf("; %d more failed: %v", count-len(created), createErr)
 					}
 				}
 			} else {
@@ -210,36 +819,54 @@ func (c *controller) reconcileAutoscaler(ctx context.Context, autoscaler *unstru
 				actionReason = "scale-up cooldown active"
 			}
 		case decision.ScaleDown && len(instances) > policy.MinInstances:
-			if c.scaleCooldownPassed(autoscaler, false, policy.ScaleDownCooldownSeconds, now) {
-				candidate := newestInstance(instances)
+			cooldownPassed := c.scaleCooldownPassed(autoscaler, false, policy.ScaleDownCooldownSeconds, policy.ScaleUpResetsScaleDown, now)
+			if stepTarget != "" {
+				// Gradual sequence in progress: the previous step must have
+				// completed before the next one, re-checked on this interval.
+				if target, err := strconv.Atoi(stepTarget); err == nil && len(instances)+draining > target {
+					action = "NoOp"
+					actionReason = fmt.Sprintf("waiting for gradual scale-down step to reach %d instances", target)
+					break
+				}
+				cooldownPassed = true
+			}
+			if cooldownPassed {
+				candidate := c.scaleDownVictim(ctx, policy, instances)
 				if candidate == nil {
 					action = "NoOp"
-					actionReason = "no removable instance found"
+					actionReason = "no removable instance found (all protected)"
 					break
 				}
 
-				remaining := filterInstances(instances, candidate.GetName())
-				if err := c.reconcileRouterBackends(ctx, policy, remaining); err != nil {
+				// Deleting the instance evicts all of its pods at once; don't
+				// do it while that would break another workload's budget.
+				if blocked, err := c.disruptionBlocked(ctx, policy.Namespace, candidate); err != nil {
 					action = "Blocked"
-					actionReason = fmt.Sprintf("router detach failed: %v", err)
+					actionReason = fmt.Sprintf("PDB check failed: %v", err)
+					break
+				} else if blocked != "" {
+					action = "Blocked"
+					actionReason = fmt.Sprintf("scale-down of %s deferred: %s", candidate.GetName(), blocked)
 					break
 				}
 
-				time.Sleep(c.drainDelay)
-
-				if err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace(policy.Namespace).Delete(ctx, candidate.GetName(), metav1.DeleteOptions{}); err != nil {
+				// Only mark the victim here; later reconciles take it out
+				// of the router and delete it (advanceDrains), so no
+				// autoscaler waits on another's drain.
+				if err := c.markDraining(ctx, policy.Namespace, candidate.GetName(), now); err != nil {
 					action = "Blocked"
-					actionReason = fmt.Sprintf("scale-down delete failed: %v", err)
+					actionReason = fmt.Sprintf("scale-down drain failed: %v", err)
 					break
 				}
 
 				action = "ScaleDown"
-				actionReason = fmt.Sprintf("deleted %s", candidate.GetName())
-				if err := c.patchAutoscalerAnnotations(ctx, policy.Namespace, policy.Name, map[string]string{
-					annotationLastScaleDown: strconv.FormatInt(now.Unix(), 10),
-					annotationLastAction:    actionReason,
-				}); err != nil {
-					log.Printf("warning: patch scale-down annotation failed: %v", err)
+				actionReason = fmt.Sprintf("draining %s", candidate.GetName())
+				if policy.ScaleDownMode == scaleDownModeGradual {
+					if err := c.patchAutoscalerAnnotations(ctx, policy.Namespace, policy.Name, map[string]string{
+						annotationScaleDownStep: strconv.Itoa(len(instances) - 1),
+					}); err != nil {
+						log.Printf("warning: patch gradual scale-down annotation failed: %v", err)
+					}
 				}
 			} else {
 				action = "NoOp"
@@ -252,30 +879,233 @@ func (c *controller) reconcileAutoscaler(ctx context.Context, autoscaler *unstru
 		}
 	}
 
-	instances, err = c.listManagedInstances(ctx, policy.Namespace, policy.LabelSelector, policy.RouterName)
+	scaleSpan.SetAttributes(attribute.String("autoscaler.action", action))
+	scaleSpan.End()
+
+	listed, err = c.listManagedInstances(ctx, policy.Namespace, policy.LabelSelector, policy.RouterName)
 	if err != nil {
 		return fmt.Errorf("refresh managed instances: %w", err)
 	}
 
-	if err := c.reconcileRouterBackends(ctx, policy, instances); err != nil {
+	routerCtx, routerSpan := startSpan(ctx, "router", policy)
+	if err := c.reconcileRouterBackends(routerCtx, policy, listed, now); err != nil {
 		action = "Blocked"
 		actionReason = fmt.Sprintf("router reconcile failed: %v", err)
+		routerSpan.RecordError(err)
+		routerSpan.SetStatus(codes.Error, err.Error())
+	}
+	routerSpan.End()
+
+	instances = instances[:0]
+	for _, instance := range listed {
+		if _, ok := drainingSince(instance); !ok {
+			instances = append(instances, instance)
+		}
+	}
+
+	return c.publishStatus(ctx, autoscaler, policy, decision, action, actionReason, len(instances), desired)
+}
+
+// reconcileReplicas scales one LLMCluster by a replica per step through the
+// scale subresource. The result is bounded by minInstances/maxInstances and
+// by the target's own spec.autoscaling.minReplicas/maxReplicas, so the two
+// never fight; a step refused by the target's bounds is reported as Blocked.
+func (c *controller) reconcileReplicas(ctx context.Context, autoscaler *unstructured.Unstructured, policy autoscalerPolicy) error {
+	decision, err := c.evaluateDecision(ctx, policy)
+	if err != nil {
+		return fmt.Errorf("evaluate decision: %w", err)
+	}
+
+	clusters := c.dynamicClient.Resource(c.llmclusterGVR).Namespace(policy.Namespace)
+	target, err := clusters.Get(ctx, policy.TargetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get target LLMCluster %s: %w", policy.TargetName, err)
+	}
+	// A target CRD installed without the scale subresource answers NotFound;
+	// fall back to writing spec.replicas on the object itself.
+	scale, scaleSubresource := target, []string(nil)
+	if obj, err := clusters.Get(ctx, policy.TargetName, metav1.GetOptions{}, "scale"); err == nil {
+		scale, scaleSubresource = obj, []string{"scale"}
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("get scale of %s: %w", policy.TargetName, err)
+	}
+	current64, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	current := int(current64)
+
+	action := "NoOp"
+	actionReason := decision.Reason
+	desired := current
+	if !decision.MetricsAvailable {
+		action = "Blocked"
+		if actionReason == "" {
+			actionReason = "no metrics returned from Prometheus"
+		}
+	} else if decision.ScaleUp {
+		desired = current + scaleUpStep(policy, decision, current)
+	} else if decision.ScaleDown {
+		desired = current - 1
+	}
+
+	if bounded, limit := boundReplicas(desired, policy, target); bounded != desired {
+		if limit != "" && bounded == current {
+			// The step was wanted but the target's own bounds forbid it
+			action = "Blocked"
+			actionReason = fmt.Sprintf("conflict: wanted %d replicas (%s) but %s is %d",
+				desired, decision.Reason, limit, bounded)
+			log.Printf("%s/%s %s", policy.Namespace, policy.Name, actionReason)
+		}
+		desired = bounded
+	}
+
+	// Publish the bounded step the metrics call for even when the cooldown
+	// or a failed update keeps spec.replicas at current
+	wanted := desired
+	_, scaleSpan := startSpan(ctx, "scale", policy)
+	if desired != current {
+		scaleUp := desired > current
+		cooldown, crossReset := policy.ScaleDownCooldownSeconds, policy.ScaleUpResetsScaleDown
+		if scaleUp {
+			cooldown, crossReset = policy.ScaleUpCooldownSeconds, policy.ScaleDownResetsScaleUp
+		}
+		if !(scaleUp && decision.Panic) && !c.scaleCooldownPassed(autoscaler, scaleUp, cooldown, crossReset, time.Now()) {
+			action = "NoOp"
+			actionReason = "scale cooldown active"
+			desired = current
+		} else if err := unstructured.SetNestedField(scale.Object, int64(desired), "spec", "replicas"); err != nil {
+			return err
+		} else if _, err := clusters.Update(ctx, scale, metav1.UpdateOptions{}, scaleSubresource...); err != nil {
+			action = "Blocked"
+			actionReason = fmt.Sprintf("scale update failed: %v", err)
+			desired = current
+		} else {
+			action = "ScaleDown"
+			if scaleUp {
+				action = "ScaleUp"
+			}
+			actionReason = fmt.Sprintf("%s replicas %d -> %d (%s)", policy.TargetName, current, desired, decision.Reason)
+		}
+	}
+	scaleSpan.SetAttributes(attribute.String("autoscaler.action", action))
+	scaleSpan.End()
+
+	return c.publishStatus(ctx, autoscaler, policy, decision, action, actionReason, current, wanted)
+}
+
+// boundReplicas clamps desired to minInstances/maxInstances and to the
+// target's spec.autoscaling.minReplicas/maxReplicas when set. limit names
+// the target bound that applied ("" if only the policy bounds did).
+func boundReplicas(desired int, policy autoscalerPolicy, target *unstructured.Unstructured) (int, string) {
+	bounded, limit := desired, ""
+	if bounded > policy.MaxInstances {
+		bounded = policy.MaxInstances
+	}
+	if bounded < policy.MinInstances {
+		bounded = policy.MinInstances
+	}
+
+	if max, found, _ := unstructured.NestedInt64(target.Object, "spec", "autoscaling", "maxReplicas"); found && max > 0 && bounded > int(max) {
+		bounded, limit = int(max), target.GetName()+" spec.autoscaling.maxReplicas"
+	}
+	if min, found, _ := unstructured.NestedInt64(target.Object, "spec", "autoscaling", "minReplicas"); found && min > 0 && bounded < int(min) {
+		bounded, limit = int(min), target.GetName()+" spec.autoscaling.minReplicas"
+	}
+	return bounded, limit
+}
+
+// publishStatus writes the reconcile outcome to status and logs it, skipping
+// both when nothing changed since the previous cycle. A failed write after a
+// scale is returned, and its scale time kept in memory so the cooldown holds.
+func (c *controller) publishStatus(
+	ctx context.Context,
+	autoscaler *unstructured.Unstructured,
+	policy autoscalerPolicy,
+	decision scaleDecision,
+	action string,
+	actionReason string,
+	currentInstances int,
+	desiredInstances int,
+) error {
+	ctx, span := startSpan(ctx, "status", policy)
+	defer span.End()
+
+	// Every Blocked reconcile counts and emits an Event, even when the
+	// status below is unchanged, so alerts can fire on persistent blocks.
+	// Scale actions emit a Normal Event.
+	if action == "Blocked" {
+		reason := blockedReason(actionReason)
+		c.metrics.incBlocked(policy.Namespace, policy.Name, reason)
+		if c.recorder != nil {
+			c.recorder.AnnotatedEventf(autoscaler, decisionEventAnnotations(decision, reason),
+				corev1.EventTypeWarning, "ScalingBlocked", "%s: %s", reason, actionReason)
+		}
+	}
+	if (action == "ScaleUp" || action == "ScaleDown") && c.recorder != nil {
+		c.recorder.AnnotatedEventf(autoscaler, decisionEventAnnotations(decision, ""),
+			corev1.EventTypeNormal, action, "%s", actionReason)
+	}
+
+	c.metrics.setInstances(policy.Namespace, policy.Name, currentInstances, desiredInstances)
+
+	key := policy.Namespace + "/" + policy.Name
+	snapshot := reconcileSnapshot{
+		Action:           action,
+		Reason:           actionReason,
+		CurrentInstances: currentInstances,
+		DesiredInstances: desiredInstances,
+		Observed:         decision.Observed,
 	}
 
-	if err := c.patchAutoscalerAnnotations(ctx, policy.Namespace, policy.Name, map[string]string{
-		annotationCurrentInstance: strconv.Itoa(len(instances)),
-	}); err != nil {
-		log.Printf("warning: patch current instance annotation failed: %v", err)
+	// Scale actions always write through: the status carries the cooldown
+	// timestamps, so skipping one would reset the cooldown.
+	scaled := action == "ScaleUp" || action == "ScaleDown"
+	if previous, ok := c.lastReconcile[key]; ok && !scaled && previous.equal(snapshot) {
+		c.debugf("reconciled %s unchanged action=%s instances=%d", key, action, currentInstances)
+		return nil
 	}
 
-	if err := c.updateAutoscalerStatus(ctx, policy, decision, action, actionReason, len(instances)); err != nil {
-		log.Printf("warning: update status failed for %s/%s: %v", policy.Namespace, policy.Name, err)
+	if err := c.updateAutoscalerStatus(ctx, policy, decision, action, actionReason, currentInstances, desiredInstances); err != nil {
+		delete(c.lastReconcile, key)
+		if !scaled {
+			log.Printf("warning: update status failed for %s: %v", key, err)
+			return nil
+		}
+		field := "lastScaleDownTime"
+		if action == "ScaleUp" {
+			field = "lastScaleUpTime"
+		}
+		if c.unsavedScaleTimes[key] == nil {
+			c.unsavedScaleTimes[key] = map[string]time.Time{}
+		}
+		c.unsavedScaleTimes[key][field] = time.Now()
+		return fmt.Errorf("record %s in status: %w", action, err)
 	}
+	c.lastReconcile[key] = snapshot
+	delete(c.unsavedScaleTimes, key)
 
-	log.Printf("reconciled %s/%s action=%s instances=%d reason=%s", policy.Namespace, policy.Name, action, len(instances), actionReason)
+	log.Printf("reconciled %s action=%s instances=%d desired=%d reason=%s", key, action, currentInstances, desiredInstances, actionReason)
 	return nil
 }
 
+// decisionEventAnnotations annotates a decision Event with the observed
+// metric values and, when blocked, the reason label of the blocked metric,
+// so event-based alerts can match the metric-based ones.
+func decisionEventAnnotations(decision scaleDecision, reason string) map[string]string {
+	annotations := make(map[string]string, len(decision.Observed)+1)
+	for metricType, value := range decision.Observed {
+		annotations[eventAnnotationObservedPrefix+metricType] = strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	if reason != "" {
+		annotations[eventAnnotationBlockedReason] = reason
+	}
+	return annotations
+}
+
+func (c *controller) debugf(format string, args ...interface{}) {
+	if c.verbose {
+		log.Printf(format, args...)
+	}
+}
+
 func (c *controller) evaluateDecision(ctx context.Context, policy autoscalerPolicy) (scaleDecision, error) {
 	decision := scaleDecision{
 		ScaleUp:          false,
@@ -285,60 +1115,433 @@ func (c *controller) evaluateDecision(ctx context.Context, policy autoscalerPoli
 		Reason:           "within thresholds",
 	}
 
+	querier, address, err := c.querierFor(ctx, policy)
+	if err != nil {
+		return decision, err
+	}
+
+	// The query span ends before the decide span starts; every return
+	// inside the loop ends it too.
+	queryCtx, querySpan := startSpan(ctx, "query", policy)
+	now := time.Now()
+	observations := make([]metricObservation, 0, len(policy.Metrics))
 	for _, metric := range policy.Metrics {
-		query := strings.TrimSpace(metric.Query)
-		if query == "" {
-			query = defaultQuery(metric.Type, policy.AppLabel, policy.Namespace)
+		text := strings.TrimSpace(metric.Query)
+		if text == "" {
+			text = defaultQuery(metric.Type, policy.AppLabel)
 		}
-		if query == "" {
+		if text == "" {
+			querySpan.End()
 			return decision, fmt.Errorf("metric %s has empty query and no default available", metric.Type)
 		}
+		query, err := renderQuery(text, queryTemplateData{
+			AppLabel:  policy.AppLabel,
+			Namespace: policy.Namespace,
+			ReadyOnly: policy.ReadyOnly,
+			Window:    metric.Window,
+		})
+		if err != nil {
+			querySpan.End()
+			return decision, fmt.Errorf("metric %s query template: %w", metric.Type, err)
+		}
 
-		value, found, err := c.queryPrometheus(ctx, policy.PrometheusAddress, query)
+		values, err := querier.Query(queryCtx, address, query)
 		if err != nil {
+			querySpan.RecordError(err)
+			querySpan.End()
 			decision.MetricsAvailable = false
 			decision.ScaleUp = false
 			decision.ScaleDown = false
 			decision.Reason = fmt.Sprintf("Prometheus query failed for %s: %v", metric.Type, err)
 			return decision, nil
 		}
-		if !found {
+		if len(values) == 0 {
 			decision.MetricsAvailable = false
 			decision.ScaleUp = false
 			decision.ScaleDown = false
 			decision.Reason = fmt.Sprintf("Prometheus returned no data for %s", metric.Type)
+			querySpan.End()
 			return decision, nil
 		}
 
+		value := aggregateSeries(values, metric.Aggregation)
 		decision.Observed[metric.Type] = value
 
-		if value > metric.ScaleUp {
-			decision.ScaleUp = true
-			if decision.Trigger == "" {
-				decision.Trigger = fmt.Sprintf("%s %.2f > %.2f", metric.Type, value, metric.ScaleUp)
+		scaleUp, scaleDown := metric.thresholdsAt(now)
+		observations = append(observations, metricObservation{Metric: metric, Value: value, ScaleUp: scaleUp, ScaleDown: scaleDown})
+	}
+
+	querySpan.End()
+
+	_, decideSpan := startSpan(ctx, "decide", policy)
+	defer decideSpan.End()
+	combineObservations(policy.DecisionMode, &decision, observations)
+	decision = c.stabilize(policy, decision)
+	decideSpan.SetAttributes(
+		attribute.Bool("autoscaler.scale_up", decision.ScaleUp),
+		attribute.Bool("autoscaler.scale_down", decision.ScaleDown))
+	return decision, nil
+}
+
+// startSpan starts the span of one reconcile phase, tagged with the
+// autoscaler. Spans are no-ops unless --otlp-endpoint set up a tracer
+// provider.
+func startSpan(ctx context.Context, name string, policy autoscalerPolicy) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(
+		attribute.String("autoscaler.namespace", policy.Namespace),
+		attribute.String("autoscaler.name", policy.Name)))
+}
+
+// setupTracing exports spans over OTLP/gRPC to endpoint (host:port) and
+// returns the function flushing them on shutdown.
+func setupTracing(ctx context.Context, endpoint string, insecure bool) (func(context.Context) error, error) {
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(tracerName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// combineObservations sets the decision's direction, trigger and overload
+// from the observations according to the decision mode:
+//
+//   - any: scale up when any metric is above its scale-up threshold
+//   - all: scale up only when every metric is
+//   - weighted: scale up when the weighted mean of value/scaleUp exceeds 1,
+//     e.g. weights 0.6 and 0.4 on QueueLength and TTFT
+//
+// Scale-down needs every metric below its scale-down threshold, or in
+// weighted mode the weighted mean of value/scaleDown below 1. A Panic metric
+// above its threshold scales up in every mode.
+func combineObservations(mode string, decision *scaleDecision, observations []metricObservation) {
+	var above []string
+	panicBreach := ""
+	allAbove := len(observations) > 0
+	minOverload := math.Inf(1)
+	var weights, upScore, downScore float64
+	for _, o := range observations {
+		overload := thresholdRatio(o.Value, o.ScaleUp)
+		if o.Value > o.ScaleUp {
+			above = append(above, fmt.Sprintf("%s %.2f > %.2f", o.Metric.Type, o.Value, o.ScaleUp))
+			decision.Overload = math.Max(decision.Overload, overload)
+			if o.Metric.Panic && !decision.Panic {
+				decision.Panic = true
+				panicBreach = above[len(above)-1] + " (panic)"
 			}
+		} else {
+			allAbove = false
 		}
-		if !(value < metric.ScaleDown) {
+		minOverload = math.Min(minOverload, overload)
+		if !(o.Value < o.ScaleDown) {
 			decision.ScaleDown = false
 		}
+		weights += o.Metric.Weight
+		upScore += o.Metric.Weight * overload
+		downScore += o.Metric.Weight * thresholdRatio(o.Value, o.ScaleDown)
+	}
+
+	switch mode {
+	case decisionModeAll:
+		decision.ScaleUp = allAbove
+		if allAbove {
+			decision.Overload = minOverload
+			decision.Trigger = strings.Join(above, ", ")
+		}
+	case decisionModeWeighted:
+		if weights > 0 {
+			upScore /= weights
+			downScore /= weights
+			decision.ScaleUp = upScore > 1
+			decision.ScaleDown = !decision.ScaleUp && downScore < 1
+			if decision.ScaleUp {
+				decision.Overload = upScore
+				decision.Trigger = fmt.Sprintf("weighted score %.2f > 1", upScore)
+			}
+		}
+	default:
+		decision.ScaleUp = len(above) > 0
+		if decision.ScaleUp {
+			decision.Trigger = above[0]
+		}
 	}
 
+	if decision.Panic {
+		// A panic metric breached on its own: scale up regardless of mode
+		if !decision.ScaleUp {
+			decision.ScaleUp = true
+			decision.Trigger = panicBreach
+		} else if strings.HasPrefix(panicBreach, decision.Trigger+" ") {
+			decision.Trigger = panicBreach
+		} else {
+			decision.Trigger += ", " + panicBreach
+		}
+	}
 	if decision.ScaleUp {
+		decision.ScaleDown = false
 		decision.Reason = decision.Trigger
 	} else if decision.ScaleDown {
 		decision.Reason = "all metrics below scale-down thresholds"
+		if mode == decisionModeWeighted {
+			decision.Reason = fmt.Sprintf("weighted score %.2f below scale-down thresholds", downScore)
+		}
 	}
+}
 
-	return decision, nil
+// thresholdRatio is value/threshold, with a threshold of 0 giving +Inf for
+// a positive value and 1 (at the threshold) otherwise.
+func thresholdRatio(value, threshold float64) float64 {
+	if threshold > 0 {
+		return value / threshold
+	}
+	if value > 0 {
+		return math.Inf(1)
+	}
+	return 1
+}
+
+// querierFor returns the querier and address the policy's queries go to:
+// Prometheus directly (with the policy's credentials, if any), over a unix
+// socket, or through the apiserver proxy, behind the cycle cache when query sharing is on.
+// Credentialed queries aren't shared: another tenant's credentials may see
+// different data at the same address.
+func (c *controller) querierFor(ctx context.Context, policy autoscalerPolicy) (metricQuerier, string, error) {
+	querier, address := c.querier, policy.PrometheusAddress
+	if policy.ViaAPIServerProxy {
+		if c.proxyQuerier == nil {
+			return nil, "", fmt.Errorf("prometheus.viaAPIServerProxy requires an apiserver rest config")
+		}
+		proxyURL, err := apiServerProxyURL(c.apiServerHost, policy.PrometheusAddress, policy.Namespace)
+		if err != nil {
+			return nil, "", err
+		}
+		querier, address = c.proxyQuerier, proxyURL
+	} else if socket, ok := unixSocketPath(address); ok {
+		querier = c.socketQuerier(socket)
+	} else if policy.PrometheusAuth.enabled() {
+		authQuerier, err := c.authQuerier(ctx, policy)
+		if err != nil {
+			return nil, "", fmt.Errorf("prometheus credentials: %w", err)
+		}
+		return authQuerier, address, nil
+	}
+	if c.queryCache != nil {
+		querier = cachingQuerier{next: querier, cache: c.queryCache}
+	}
+	return querier, address, nil
+}
+
+// socketQuerier returns the querier for a Prometheus listening on a unix
+// socket, reusing one transport per socket path.
+func (c *controller) socketQuerier(socket string) *prometheusQuerier {
+	if querier, ok := c.socketQueriers[socket]; ok {
+		return querier
+	}
+	dialer := &net.Dialer{}
+	querier := &prometheusQuerier{
+		httpClient: &http.Client{
+			Timeout: c.queryTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+	c.socketQueriers[socket] = querier
+	return querier
+}
+
+// unixSocketPath returns the socket of a unix:///path/to.sock address.
+func unixSocketPath(address string) (string, bool) {
+	if !strings.HasPrefix(address, "unix://") {
+		return "", false
+	}
+	return strings.TrimPrefix(address, "unix://"), true
+}
+
+// authQuerier returns the policy's credentialed querier, building a new
+// client (and TLS transport) only when the referenced Secret data changed.
+func (c *controller) authQuerier(ctx context.Context, policy autoscalerPolicy) (*prometheusQuerier, error) {
+	auth := policy.PrometheusAuth
+	querier := &prometheusQuerier{}
+	var caPEM []byte
+	if ref := auth.BearerTokenSecret; ref.Name != "" {
+		token, err := c.secretValue(ctx, policy.Namespace, ref.Name, ref.Key)
+		if err != nil {
+			return nil, err
+		}
+		querier.bearerToken = strings.TrimSpace(string(token))
+	}
+	if name := auth.BasicAuthSecret; name != "" {
+		username, err := c.secretValue(ctx, policy.Namespace, name, "username")
+		if err != nil {
+			return nil, err
+		}
+		password, err := c.secretValue(ctx, policy.Namespace, name, "password")
+		if err != nil {
+			return nil, err
+		}
+		querier.username, querier.password = string(username), string(password)
+	}
+	if ref := auth.CASecret; ref.Name != "" {
+		var err error
+		if caPEM, err = c.secretValue(ctx, policy.Namespace, ref.Name, ref.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	sum := sha256.New()
+	for _, part := range []string{querier.bearerToken, querier.username, querier.password, string(caPEM), boolString(auth.InsecureSkipVerify)} {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+	fingerprint := hex.EncodeToString(sum.Sum(nil))
+	key := policy.Namespace + "/" + policy.Name
+	if entry, ok := c.authQueriers[key]; ok && entry.fingerprint == fingerprint {
+		return entry.querier, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(caPEM) > 0 || auth.InsecureSkipVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: auth.InsecureSkipVerify}
+		if len(caPEM) > 0 {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caPEM) {
+				return nil, fmt.Errorf("secret %s key %s holds no PEM certificates", auth.CASecret.Name, auth.CASecret.Key)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	querier.httpClient = &http.Client{Transport: transport, Timeout: c.queryTimeout}
+	c.authQueriers[key] = authQuerierEntry{fingerprint: fingerprint, querier: querier}
+	return querier, nil
+}
+
+// secretValue returns one decoded key of a Secret.
+func (c *controller) secretValue(ctx context.Context, namespace, name, key string) ([]byte, error) {
+	secret, err := c.dynamicClient.Resource(c.secretGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get secret %s: %w", name, err)
+	}
+	encoded, found, _ := unstructured.NestedString(secret.Object, "data", key)
+	if !found {
+		return nil, fmt.Errorf("secret %s has no key %s", name, key)
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+// scaleDownVictim picks the instance a scale-down removes. Load-based
+// policies query each instance's load and take the least loaded; if any
+// instance has no data the choice would be a guess, so it falls back to
+// the newest instance. Instances annotated protected are never picked.
+func (c *controller) scaleDownVictim(ctx context.Context, policy autoscalerPolicy, instances []*unstructured.Unstructured) *unstructured.Unstructured {
+	instances = unprotectedInstances(instances)
+	if policy.ScaleDownPolicy == scaleDownPolicyNewest || len(instances) < 2 {
+		return newestInstance(instances)
+	}
+
+	querier, address, err := c.querierFor(ctx, policy)
+	if err != nil {
+		log.Printf("warning: %s/%s scale-down victim falls back to newest: %v", policy.Namespace, policy.Name, err)
+		return newestInstance(instances)
+	}
+	loads := make(map[string]float64, len(instances))
+	for _, instance := range instances {
+		query, err := instanceLoadQuery(policy.ScaleDownPolicy, instance.GetName(), policy.Namespace)
+		if err != nil {
+			log.Printf("warning: %s/%s scale-down victim falls back to newest: %v", policy.Namespace, policy.Name, err)
+			return newestInstance(instances)
+		}
+		values, err := querier.Query(ctx, address, query)
+		if err != nil || len(values) == 0 {
+			log.Printf("warning: %s/%s scale-down victim falls back to newest: no %s data for %s (%v)",
+				policy.Namespace, policy.Name, policy.ScaleDownPolicy, instance.GetName(), err)
+			return newestInstance(instances)
+		}
+		loads[instance.GetName()] = aggregateSeries(values, aggregationSum)
+	}
+	victim := leastLoadedInstance(instances, loads)
+	c.debugf("%s/%s scale-down victim %s (%s %.2f)", policy.Namespace, policy.Name,
+		victim.GetName(), policy.ScaleDownPolicy, loads[victim.GetName()])
+	return victim
 }
 
-func (c *controller) queryPrometheus(ctx context.Context, baseURL, query string) (float64, bool, error) {
+// stabilize records decision and only lets it scale once the same direction
+// held for every sample in the policy's window: ceil(window / syncInterval)
+// consecutive decisions, so a single bursty sample (e.g. a TTFT spike)
+// doesn't scale. A sample without metrics breaks the run. Panic scale-ups
+// skip the window like they skip the cooldown.
+func (c *controller) stabilize(policy autoscalerPolicy, decision scaleDecision) scaleDecision {
+	upSamples := windowSamples(policy.ScaleUpWindowSeconds, c.syncInterval)
+	downSamples := windowSamples(policy.ScaleDownWindowSeconds, c.syncInterval)
+	keep := upSamples
+	if downSamples > keep {
+		keep = downSamples
+	}
+
+	key := policy.Namespace + "/" + policy.Name
+	recent := append(c.recentDecisions[key], decision)
+	if len(recent) > keep {
+		recent = recent[len(recent)-keep:]
+	}
+	c.recentDecisions[key] = recent
+
+	held := func(samples int, scaleUp bool) int {
+		n := 0
+		for i := len(recent) - 1; i >= 0 && n < samples; i-- {
+			if !recent[i].MetricsAvailable || (scaleUp && !recent[i].ScaleUp) || (!scaleUp && !recent[i].ScaleDown) {
+				break
+			}
+			n++
+		}
+		return n
+	}
+
+	if decision.ScaleUp && !decision.Panic {
+		if n := held(upSamples, true); n < upSamples {
+			decision.ScaleUp = false
+			decision.Reason = fmt.Sprintf("%s for %d/%d samples of the scale-up window", decision.Trigger, n, upSamples)
+		}
+	}
+	if decision.ScaleDown {
+		if n := held(downSamples, false); n < downSamples {
+			decision.ScaleDown = false
+			decision.Reason = fmt.Sprintf("below scale-down thresholds for %d/%d samples of the scale-down window", n, downSamples)
+		}
+	}
+	return decision
+}
+
+// windowSamples is the number of consecutive sync-interval samples that
+// cover windowSeconds, at least one.
+func windowSamples(windowSeconds int, syncInterval time.Duration) int {
+	if windowSeconds <= 0 || syncInterval <= 0 {
+		return 1
+	}
+	return int(math.Ceil(float64(windowSeconds) / syncInterval.Seconds()))
+}
+
+func (p *prometheusQuerier) Query(ctx context.Context, baseURL, query string) ([]float64, error) {
 	base := strings.TrimRight(baseURL, "/")
+	if _, ok := unixSocketPath(base); ok {
+		// The transport dials the socket; the host is only for the request line
+		base = "http://localhost"
+	}
 	endpoint := base + "/api/v1/query"
 
 	reqURL, err := url.Parse(endpoint)
 	if err != nil {
-		return 0, false, err
+		return nil, err
 	}
 
 	values := reqURL.Query()
@@ -347,56 +1550,176 @@ func (c *controller) queryPrometheus(ctx context.Context, baseURL, query string)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
 	if err != nil {
-		return 0, false, err
+		return nil, err
+	}
+	token := p.bearerToken
+	if p.bearerTokenFile != "" {
+		raw, err := os.ReadFile(p.bearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read bearer token: %w", err)
+		}
+		token = strings.TrimSpace(string(raw))
+	}
+	switch {
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case p.username != "":
+		req.SetBasicAuth(p.username, p.password)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return 0, false, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return 0, false, fmt.Errorf("prometheus status %d", resp.StatusCode)
+		return nil, fmt.Errorf("prometheus status %d", resp.StatusCode)
 	}
 
 	var payload struct {
 		Status string `json:"status"`
 		Error  string `json:"error"`
 		Data   struct {
-			ResultType string `json:"resultType"`
-			Result     []struct {
-				Value []interface{} `json:"value"`
-			} `json:"result"`
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
 		} `json:"data"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return 0, false, err
+		return nil, err
 	}
 	if payload.Status != "success" {
 		if payload.Error == "" {
 			payload.Error = "unknown prometheus error"
 		}
-		return 0, false, fmt.Errorf(payload.Error)
+		return nil, errors.New(payload.Error)
 	}
-	if len(payload.Data.Result) == 0 || len(payload.Data.Result[0].Value) < 2 {
-		return 0, false, nil
+	return decodeQueryResult(payload.Data.ResultType, payload.Data.Result)
+}
+
+// decodeQueryResult returns one value per series of a query result: the
+// scalar itself, each vector sample, or the latest point of each matrix
+// (range) series.
+func decodeQueryResult(resultType string, result json.RawMessage) ([]float64, error) {
+	var samples [][]interface{}
+	switch resultType {
+	case "scalar":
+		var sample []interface{}
+		if err := json.Unmarshal(result, &sample); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	case "vector":
+		var series []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(result, &series); err != nil {
+			return nil, err
+		}
+		for _, s := range series {
+			samples = append(samples, s.Value)
+		}
+	case "matrix":
+		var series []struct {
+			Values [][]interface{} `json:"values"`
+		}
+		if err := json.Unmarshal(result, &series); err != nil {
+			return nil, err
+		}
+		for _, s := range series {
+			if len(s.Values) > 0 {
+				samples = append(samples, s.Values[len(s.Values)-1])
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported prometheus result type %q", resultType)
 	}
 
-	raw := payload.Data.Result[0].Value[1]
-	switch v := raw.(type) {
-	case string:
-		f, err := strconv.ParseFloat(v, 64)
-		if err != nil {
-			return 0, false, err
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		if len(sample) < 2 {
+			continue
 		}
-		return f, true, nil
-	case float64:
-		return v, true, nil
+		switch v := sample[1].(type) {
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, f)
+		case float64:
+			values = append(values, v)
+		default:
+			return nil, fmt.Errorf("unexpected prometheus value type %T", sample[1])
+		}
+	}
+	return values, nil
+}
+
+// aggregateSeries combines the series a metric query returned into the
+// value compared against its thresholds.
+func aggregateSeries(values []float64, aggregation string) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		switch aggregation {
+		case aggregationMax:
+			result = math.Max(result, v)
+		default:
+			result += v
+		}
+	}
+	if aggregation == aggregationAvg {
+		result /= float64(len(values))
+	}
+	return result
+}
+
+// defaultAggregation is sum for fleet totals (queues, in-flight and
+// rejected requests) and max for per-instance signals (latency, GPU
+// utilization), so the worst instance drives scale-up.
+func defaultAggregation(metricType string) string {
+	switch metricType {
+	case "TTFT", "TPOT", "Latency", "GPUUtilization":
+		return aggregationMax
 	default:
-		return 0, false, fmt.Errorf("unexpected prometheus value type %T", raw)
+		return aggregationSum
+	}
+}
+
+// apiServerProxyURL rewrites an in-cluster Prometheus address such as
+// http://prometheus.monitoring:9090 into the kube-apiserver service proxy URL
+// /api/v1/namespaces/monitoring/services/http:prometheus:9090/proxy.
+// A bare service name resolves in defaultNamespace.
+func apiServerProxyURL(apiServerHost, address, defaultNamespace string) (string, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("parse prometheus address: %w", err)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("prometheus address %q has no host", address)
+	}
+
+	parts := strings.Split(u.Hostname(), ".")
+	service, namespace := parts[0], defaultNamespace
+	if len(parts) > 1 {
+		namespace = parts[1]
+	}
+
+	scheme := u.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if scheme == "https" {
+			port = "443"
+		}
 	}
+
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/services/%s:%s:%s/proxy%s",
+		strings.TrimRight(apiServerHost, "/"), namespace, scheme, service, port, strings.TrimRight(u.Path, "/")), nil
 }
 
 func (c *controller) listManagedInstances(ctx context.Context, namespace, selector, routerName string) ([]*unstructured.Unstructured, error) {
@@ -426,6 +1749,86 @@ func (c *controller) listManagedInstances(ctx context.Context, namespace, select
 	return instances, nil
 }
 
+// countNamespaceInstances counts the LLMClusters any autoscaler manages in
+// namespace, for the NamespaceMaxInstances cap
+func (c *controller) countNamespaceInstances(ctx context.Context, namespace string) (int, error) {
+	list, err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelManagedBy,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range list.Items {
+		if list.Items[i].GetDeletionTimestamp() == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// disruptionBlocked reports why deleting instance would violate a
+// PodDisruptionBudget, or "" if it wouldn't. Every PDB selecting one of the
+// instance's pods must allow that many disruptions; the instance's own PDB
+// is skipped since it is garbage-collected with the instance. A blocked
+// scale-down is retried on the next sync once the budget has headroom.
+func (c *controller) disruptionBlocked(ctx context.Context, namespace string, instance *unstructured.Unstructured) (string, error) {
+	pods, err := c.dynamicClient.Resource(c.podGVR).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=" + instance.GetName(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("list pods: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return "", nil
+	}
+
+	pdbs, err := c.dynamicClient.Resource(c.pdbGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("list poddisruptionbudgets: %w", err)
+	}
+
+	for i := range pdbs.Items {
+		pdb := &pdbs.Items[i]
+		if owner := metav1.GetControllerOf(pdb); owner != nil && owner.UID == instance.GetUID() {
+			continue
+		}
+
+		rawSelector, found, _ := unstructured.NestedMap(pdb.Object, "spec", "selector")
+		if !found {
+			continue
+		}
+		var labelSelector metav1.LabelSelector
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawSelector, &labelSelector); err != nil {
+			return "", fmt.Errorf("parse selector of PDB %s: %w", pdb.GetName(), err)
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+		if err != nil {
+			return "", fmt.Errorf("parse selector of PDB %s: %w", pdb.GetName(), err)
+		}
+		if selector.Empty() {
+			continue
+		}
+
+		covered := 0
+		for j := range pods.Items {
+			if selector.Matches(labels.Set(pods.Items[j].GetLabels())) {
+				covered++
+			}
+		}
+		if covered == 0 {
+			continue
+		}
+
+		allowed, _, _ := unstructured.NestedInt64(pdb.Object, "status", "disruptionsAllowed")
+		if allowed < int64(covered) {
+			return fmt.Sprintf("PDB %s allows %d disruptions, deleting would evict %d pods", pdb.GetName(), allowed, covered), nil
+		}
+	}
+	return "", nil
+}
+
 func (c *controller) createInstance(
 	ctx context.Context,
 	policy autoscalerPolicy,
@@ -438,7 +1841,7 @@ func (c *controller) createInstance(
 	for k, v := range policy.TemplateLabels {
 		labels[k] = v
 	}
-	labels["autoscaling.serving.ai/managed-by"] = autoscaler.GetName()
+	labels[labelManagedBy] = autoscaler.GetName()
 	if policy.AppLabel != "" {
 		if _, ok := labels["app"]; !ok {
 			labels["app"] = policy.AppLabel
@@ -472,36 +1875,126 @@ func (c *controller) createInstance(
 	return name, nil
 }
 
-func (c *controller) reconcileRouterBackends(ctx context.Context, policy autoscalerPolicy, instances []*unstructured.Unstructured) error {
-	if strings.TrimSpace(policy.RouterName) == "" {
-		return nil
+// advanceDrains moves scale-down victims through their drain: each is
+// listed in the router as draining for DrainGraceSeconds after being marked,
+// then detached, then deleted once drainDelay has also passed. It returns the
+// instances not draining and how many are still draining; a failed delete is
+// retried next reconcile.
+func (c *controller) advanceDrains(ctx context.Context, policy autoscalerPolicy, instances []*unstructured.Unstructured, now time.Time) ([]*unstructured.Unstructured, int) {
+	deleteAfter := time.Duration(policy.DrainGraceSeconds)*time.Second + c.drainDelay
+	active := make([]*unstructured.Unstructured, 0, len(instances))
+	draining := 0
+	for _, instance := range instances {
+		since, ok := drainingSince(instance)
+		if !ok {
+			active = append(active, instance)
+			continue
+		}
+		if now.Sub(since) < deleteAfter {
+			draining++
+			continue
+		}
+		err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace(policy.Namespace).Delete(ctx, instance.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			log.Printf("warning: %s/%s delete drained instance %s failed: %v", policy.Namespace, policy.Name, instance.GetName(), err)
+			draining++
+			continue
+		}
+		log.Printf("%s/%s deleted drained instance %s", policy.Namespace, policy.Name, instance.GetName())
 	}
+	return active, draining
+}
 
-	router, err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace(policy.Namespace).Get(ctx, policy.RouterName, metav1.GetOptions{})
+// markDraining starts an instance's drain by stamping it with the time.
+func (c *controller) markDraining(ctx context.Context, namespace, name string, now time.Time) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{annotationDrainingSince: strconv.FormatInt(now.Unix(), 10)},
+		},
+	})
 	if err != nil {
 		return err
 	}
+	_, err = c.dynamicClient.Resource(c.llmclusterGVR).Namespace(namespace).Patch(
+		ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// drainingSince returns when the instance was marked draining, if it was.
+func drainingSince(instance *unstructured.Unstructured) (time.Time, bool) {
+	epoch, err := strconv.ParseInt(instance.GetAnnotations()[annotationDrainingSince], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(epoch, 0), true
+}
+
+// reconcileRouterBackends points the router at the instances. Draining ones
+// stay listed, marked draining, for the policy's DrainGraceSeconds and are
+// left out after that.
+func (c *controller) reconcileRouterBackends(ctx context.Context, policy autoscalerPolicy, instances []*unstructured.Unstructured, now time.Time) error {
+	if strings.TrimSpace(policy.RouterName) == "" {
+		return nil
+	}
 
-	backends := make([]interface{}, 0, len(instances))
+	// Only Running instances take traffic; Creating, Progressing and
+	// Degraded ones are added back once they report Running. With none
+	// Running (first boot) every instance stays listed, since an empty list
+	// would point the router at its own pods.
+	grace := time.Duration(policy.DrainGraceSeconds) * time.Second
+	healthy := make([]*unstructured.Unstructured, 0, len(instances))
+	draining := map[string]bool{}
 	for _, instance := range instances {
+		if since, ok := drainingSince(instance); ok {
+			if now.Sub(since) < grace {
+				healthy = append(healthy, instance)
+				draining[instance.GetName()] = true
+			}
+			continue
+		}
+		if phase, _, _ := unstructured.NestedString(instance.Object, "status", "phase"); phase == "Running" {
+			healthy = append(healthy, instance)
+		}
+	}
+	if len(healthy) == 0 && len(instances) > 0 {
+		c.debugf("%s/%s no Running instances; routing to all %d", policy.Namespace, policy.RouterName, len(instances))
+		healthy = instances
+	}
+
+	backends := make([]interface{}, 0, len(healthy))
+	for _, instance := range healthy {
 		instanceName := instance.GetName()
 		backendName := instanceName
 		if prefix := policy.RouterBackendNamePrefix; prefix != "" && strings.HasPrefix(instanceName, prefix) {
 			backendName = strings.TrimPrefix(instanceName, prefix)
 		}
 
-		backends = append(backends, map[string]interface{}{
+		backend := map[string]interface{}{
 			"name":    backendName,
 			"service": instanceName,
 			"port":    int64(policy.RouterBackendPort),
-		})
+		}
+		if draining[instanceName] {
+			backend["draining"] = true
+		}
+		backends = append(backends, backend)
 	}
 
-	if err := unstructured.SetNestedSlice(router.Object, backends, "spec", "router", "backends"); err != nil {
+	// A merge patch replaces spec.router.backends (lists are replaced
+	// whole) and nothing else, so it can't revert fields the controller or
+	// a user changed since; CRDs don't support strategic merge patches.
+	// It carries no resourceVersion, so it never fails with a conflict and
+	// needs no retry.
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"router": map[string]interface{}{"backends": backends},
+		},
+	})
+	if err != nil {
 		return err
 	}
-
-	_, err = c.dynamicClient.Resource(c.llmclusterGVR).Namespace(policy.Namespace).Update(ctx, router, metav1.UpdateOptions{})
+	_, err = c.dynamicClient.Resource(c.llmclusterGVR).Namespace(policy.Namespace).Patch(
+		ctx, policy.RouterName, types.MergePatchType, patch, metav1.PatchOptions{})
 	return err
 }
 
@@ -512,12 +2005,8 @@ func (c *controller) updateAutoscalerStatus(
 	action string,
 	actionReason string,
 	currentInstances int,
+	desiredInstances int,
 ) error {
-	obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace(policy.Namespace).Get(ctx, policy.Name, metav1.GetOptions{})
-	if err != nil {
-		return err
-	}
-
 	now := time.Now().Format(time.RFC3339)
 
 	observedMetrics := map[string]interface{}{}
@@ -542,76 +2031,210 @@ func (c *controller) updateAutoscalerStatus(
 		},
 	}
 
-	status := map[string]interface{}{
-		"currentInstances": int64(currentInstances),
-		"desiredInstances": int64(currentInstances),
-		"lastScaleTime":    now,
-		"lastScaleAction":  action,
-		"observedMetrics":  observedMetrics,
-		"conditions":       conditions,
-	}
+	// The history and cooldown times build on the stored status, so each
+	// attempt starts from a fresh Get rather than overwriting another
+	// writer's update.
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace(policy.Namespace).Get(ctx, policy.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		status := map[string]interface{}{
+			"currentInstances": int64(currentInstances),
+			"desiredInstances": int64(desiredInstances),
+			"lastScaleTime":    now,
+			"lastScaleAction":  action,
+			"observedMetrics":  observedMetrics,
+			"conditions":       conditions,
+		}
+		// Cooldown bookkeeping lives in status (not annotations) so recording a
+		// scale never bumps the spec generation; carry it across rewrites,
+		// along with times a failed earlier write left unsaved.
+		for _, field := range []string{"lastScaleUpTime", "lastScaleDownTime"} {
+			last, ok := c.lastScaleTime(obj, field)
+			if ok {
+				status[field] = last.UTC().Format(time.RFC3339)
+			}
+		}
+		switch action {
+		case "ScaleUp":
+			status["lastScaleUpTime"] = now
+		case "ScaleDown":
+			status["lastScaleDownTime"] = now
+		}
+		if policy.MetricsHistoryLimit > 0 {
+			history, _, _ := unstructured.NestedSlice(obj.Object, "status", "observedMetricsHistory")
+			status["observedMetricsHistory"] = appendMetricsSample(history, map[string]interface{}{
+				"time":    now,
+				"action":  action,
+				"metrics": runtime.DeepCopyJSONValue(observedMetrics),
+			}, policy.MetricsHistoryLimit)
+		}
+		if policy.Mode == modeRecommend {
+			status["recommendation"] = map[string]interface{}{
+				"desiredInstances": int64(desiredInstances),
+				"reason":           actionReason,
+			}
+		}
 
-	if err := unstructured.SetNestedMap(obj.Object, status, "status"); err != nil {
+		if err := unstructured.SetNestedMap(obj.Object, status, "status"); err != nil {
+			return err
+		}
+		_, err = c.dynamicClient.Resource(c.autoscalerGVR).Namespace(policy.Namespace).UpdateStatus(ctx, obj, metav1.UpdateOptions{})
 		return err
-	}
+	})
+}
 
-	_, err = c.dynamicClient.Resource(c.autoscalerGVR).Namespace(policy.Namespace).UpdateStatus(ctx, obj, metav1.UpdateOptions{})
-	return err
+// appendMetricsSample appends sample to the observed-metrics history,
+// dropping the oldest samples beyond limit.
+func appendMetricsSample(history []interface{}, sample map[string]interface{}, limit int) []interface{} {
+	history = append(history, sample)
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return history
 }
 
+// patchAutoscalerAnnotations sets updates on the autoscaler's annotations,
+// re-reading it and retrying when a concurrent write wins the race.
 func (c *controller) patchAutoscalerAnnotations(ctx context.Context, namespace, name string, updates map[string]string) error {
-	obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		for k, v := range updates {
+			annotations[k] = v
+		}
+		obj.SetAnnotations(annotations)
+
+		_, err = c.dynamicClient.Resource(c.autoscalerGVR).Namespace(namespace).Update(ctx, obj, metav1.UpdateOptions{})
 		return err
-	}
+	})
+}
 
-	annotations := obj.GetAnnotations()
-	if annotations == nil {
-		annotations = map[string]string{}
-	}
-	for k, v := range updates {
-		annotations[k] = v
+// lastScaleTime returns the autoscaler's last scale time for a status field
+// (lastScaleUpTime or lastScaleDownTime): the latest of the status value, or
+// the legacy annotation while status has none, and any unsaved time.
+func (c *controller) lastScaleTime(autoscaler *unstructured.Unstructured, field string) (time.Time, bool) {
+	var last time.Time
+	found := false
+	if value, _, _ := unstructured.NestedString(autoscaler.Object, "status", field); value != "" {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			last, found = t, true
+		}
+	} else {
+		annotation := annotationLegacyLastScaleDown
+		if field == "lastScaleUpTime" {
+			annotation = annotationLegacyLastScaleUp
+		}
+		if epoch, err := strconv.ParseInt(autoscaler.GetAnnotations()[annotation], 10, 64); err == nil {
+			last, found = time.Unix(epoch, 0), true
+		}
 	}
-	obj.SetAnnotations(annotations)
 
-	_, err = c.dynamicClient.Resource(c.autoscalerGVR).Namespace(namespace).Update(ctx, obj, metav1.UpdateOptions{})
-	return err
+	key := autoscaler.GetNamespace() + "/" + autoscaler.GetName()
+	if unsaved, ok := c.unsavedScaleTimes[key][field]; ok && unsaved.After(last) {
+		last, found = unsaved, true
+	}
+	return last, found
 }
 
+// scaleCooldownPassed reports whether cooldownSeconds have elapsed since the
+// last scale in the same direction or, with crossReset, in either direction,
+// as recorded in status.lastScaleUpTime/lastScaleDownTime (see lastScaleTime).
 func (c *controller) scaleCooldownPassed(
 	autoscaler *unstructured.Unstructured,
 	scaleUp bool,
 	cooldownSeconds int,
+	crossReset bool,
 	now time.Time,
 ) bool {
 	if cooldownSeconds <= 0 {
 		return true
 	}
 
-	annotations := autoscaler.GetAnnotations()
-	if annotations == nil {
-		return true
+	fields := []string{"lastScaleDownTime", "lastScaleUpTime"}
+	if scaleUp {
+		fields[0], fields[1] = fields[1], fields[0]
+	}
+	if !crossReset {
+		fields = fields[:1]
 	}
 
-	key := annotationLastScaleDown
-	if scaleUp {
-		key = annotationLastScaleUp
+	for _, field := range fields {
+		last, ok := c.lastScaleTime(autoscaler, field)
+		if !ok {
+			continue
+		}
+
+		if now.Sub(last) < time.Duration(cooldownSeconds)*time.Second {
+			return false
+		}
 	}
+	return true
+}
 
-	value := strings.TrimSpace(annotations[key])
-	if value == "" {
-		return true
+// loadOperatorDefaults reads operatorDefaults from the ConfigMap ref
+// ("namespace/name"), starting from the built-in defaults.
+func loadOperatorDefaults(ctx context.Context, kubeClient kubernetes.Interface, ref string) (operatorDefaults, error) {
+	defaults := builtinDefaults()
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" {
+		return defaults, fmt.Errorf("defaults configmap %q must be namespace/name", ref)
 	}
 
-	lastEpoch, err := strconv.ParseInt(value, 10, 64)
+	cm, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return true
+		return defaults, err
 	}
 
-	return now.Unix()-lastEpoch >= int64(cooldownSeconds)
+	for key, value := range cm.Data {
+		value = strings.TrimSpace(value)
+		switch {
+		case key == "prometheusAddress":
+			if value != "" {
+				defaults.PrometheusAddress = value
+			}
+		case key == "scaleUpStabilizationSeconds":
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds < 0 {
+				return defaults, fmt.Errorf("%s: invalid seconds %q", key, value)
+			}
+			defaults.ScaleUpCooldownSeconds = seconds
+		case key == "scaleDownStabilizationSeconds":
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds < 0 {
+				return defaults, fmt.Errorf("%s: invalid seconds %q", key, value)
+			}
+			defaults.ScaleDownCooldownSeconds = seconds
+		case key == "namespaceMaxInstances":
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 0 {
+				return defaults, fmt.Errorf("%s: invalid instance count %q", key, value)
+			}
+			defaults.NamespaceMaxInstances = limit
+		case strings.HasPrefix(key, "query."):
+			if metricType := strings.TrimPrefix(key, "query."); metricType != "" && value != "" {
+				if _, err := renderQuery(value, queryTemplateData{}); err != nil {
+					return defaults, fmt.Errorf("%s: invalid query template: %v", key, err)
+				}
+				defaults.Queries[metricType] = value
+			}
+		default:
+			log.Printf("warning: defaults configmap %s: unknown key %q", ref, key)
+		}
+	}
+	return defaults, nil
 }
 
-func parsePolicy(autoscaler *unstructured.Unstructured) (autoscalerPolicy, error) {
+func parsePolicy(autoscaler *unstructured.Unstructured, defaults operatorDefaults) (autoscalerPolicy, error) {
 	spec, ok, err := unstructured.NestedMap(autoscaler.Object, "spec")
 	if err != nil {
 		return autoscalerPolicy{}, err
@@ -623,17 +2246,57 @@ func parsePolicy(autoscaler *unstructured.Unstructured) (autoscalerPolicy, error
 	policy := autoscalerPolicy{
 		Namespace:                autoscaler.GetNamespace(),
 		Name:                     autoscaler.GetName(),
-		PrometheusAddress:        defaultPrometheusAddress,
+		PrometheusAddress:        defaults.PrometheusAddress,
 		RouterBackendPort:        defaultRouterBackendPort,
-		ScaleUpCooldownSeconds:   defaultScaleUpCooldown,
-		ScaleDownCooldownSeconds: defaultScaleDownCooldown,
+		ScaleUpCooldownSeconds:   defaults.ScaleUpCooldownSeconds,
+		ScaleDownCooldownSeconds: defaults.ScaleDownCooldownSeconds,
+		ScaleDownMode:            scaleDownModeBatch,
+		DecisionMode:             decisionModeAny,
+		ScaleDownPolicy:          scaleDownPolicyNewest,
+		ScaleUpResetsScaleDown:   true,
+		MetricsHistoryLimit:      defaultMetricsHistory,
+		ScaleUpMaxStep:           1,
 		TemplateLabels:           map[string]string{},
 		TemplateAnnotations:      map[string]string{},
 	}
 
+	if mode, found, _ := unstructured.NestedString(spec, "mode"); found {
+		policy.Mode = strings.TrimSpace(mode)
+	}
+
 	if addr, found, _ := unstructured.NestedString(spec, "prometheus", "address"); found && strings.TrimSpace(addr) != "" {
 		policy.PrometheusAddress = addr
 	}
+	if viaProxy, found, _ := unstructured.NestedBool(spec, "prometheus", "viaAPIServerProxy"); found {
+		policy.ViaAPIServerProxy = viaProxy
+	}
+	auth := &policy.PrometheusAuth
+	auth.BearerTokenSecret.Name, _, _ = unstructured.NestedString(spec, "prometheus", "bearerTokenSecret", "name")
+	auth.BearerTokenSecret.Key, _, _ = unstructured.NestedString(spec, "prometheus", "bearerTokenSecret", "key")
+	auth.BasicAuthSecret, _, _ = unstructured.NestedString(spec, "prometheus", "basicAuthSecret", "name")
+	auth.CASecret.Name, _, _ = unstructured.NestedString(spec, "prometheus", "tls", "caSecret", "name")
+	auth.CASecret.Key, _, _ = unstructured.NestedString(spec, "prometheus", "tls", "caSecret", "key")
+	auth.InsecureSkipVerify, _, _ = unstructured.NestedBool(spec, "prometheus", "tls", "insecureSkipVerify")
+	if auth.BearerTokenSecret.Name != "" && auth.BasicAuthSecret != "" {
+		return autoscalerPolicy{}, fmt.Errorf("prometheus.bearerTokenSecret and prometheus.basicAuthSecret are mutually exclusive")
+	}
+	if auth.BearerTokenSecret.Key == "" {
+		auth.BearerTokenSecret.Key = "token"
+	}
+	if auth.CASecret.Key == "" {
+		auth.CASecret.Key = "ca.crt"
+	}
+	if auth.enabled() && policy.ViaAPIServerProxy {
+		return autoscalerPolicy{}, fmt.Errorf("prometheus credentials and TLS settings don't apply with viaAPIServerProxy")
+	}
+	if socket, ok := unixSocketPath(policy.PrometheusAddress); ok {
+		if socket == "" {
+			return autoscalerPolicy{}, fmt.Errorf("prometheus.address %q has no socket path", policy.PrometheusAddress)
+		}
+		if auth.enabled() || policy.ViaAPIServerProxy {
+			return autoscalerPolicy{}, fmt.Errorf("a unix:// prometheus.address takes no credentials, TLS or viaAPIServerProxy")
+		}
+	}
 
 	if appLabel, found, _ := unstructured.NestedString(spec, "scaleTargetRef", "appLabel"); found {
 		policy.AppLabel = appLabel
@@ -642,6 +2305,18 @@ func parsePolicy(autoscaler *unstructured.Unstructured) (autoscalerPolicy, error
 	if selector, found, _ := unstructured.NestedString(spec, "scaleTargetRef", "labelSelector"); found {
 		policy.LabelSelector = selector
 	}
+	if readyOnly, found, _ := unstructured.NestedBool(spec, "scaleTargetRef", "readyOnly"); found {
+		policy.ReadyOnly = readyOnly
+	}
+	if name, found, _ := unstructured.NestedString(spec, "scaleTargetRef", "name"); found {
+		policy.TargetName = strings.TrimSpace(name)
+	}
+	if policy.Mode == modeReplicas && policy.TargetName == "" {
+		return autoscalerPolicy{}, fmt.Errorf("spec.scaleTargetRef.name is required in replicas mode")
+	}
+	if strings.TrimSpace(policy.LabelSelector) == "" && policy.Mode == modeReplicas {
+		policy.LabelSelector = fmt.Sprintf("app=%s", policy.TargetName)
+	}
 	if strings.TrimSpace(policy.LabelSelector) == "" {
 		if policy.AppLabel == "" {
 			return autoscalerPolicy{}, fmt.Errorf("spec.scaleTargetRef.labelSelector (or appLabel) is required")
@@ -662,6 +2337,13 @@ func parsePolicy(autoscaler *unstructured.Unstructured) (autoscalerPolicy, error
 		return autoscalerPolicy{}, fmt.Errorf("minInstances cannot exceed maxInstances")
 	}
 
+	if limit, found, _ := unstructured.NestedInt64(spec, "metricsHistoryLimit"); found {
+		if limit < 0 {
+			return autoscalerPolicy{}, fmt.Errorf("metricsHistoryLimit must be >= 0")
+		}
+		policy.MetricsHistoryLimit = int(limit)
+	}
+
 	metrics, found, err := unstructured.NestedSlice(spec, "metrics")
 	if err != nil {
 		return autoscalerPolicy{}, err
@@ -682,6 +2364,14 @@ func parsePolicy(autoscaler *unstructured.Unstructured) (autoscalerPolicy, error
 			return autoscalerPolicy{}, fmt.Errorf("metric.type is required")
 		}
 		query := stringValue(m["query"])
+		if strings.TrimSpace(query) == "" {
+			query = defaults.Queries[metricType]
+		}
+		// Queries are text/template templates over queryTemplateData;
+		// render once now so a malformed one fails here, not every sync
+		if _, err := renderQuery(query, queryTemplateData{}); err != nil {
+			return autoscalerPolicy{}, fmt.Errorf("metric %s query template: %v", metricType, err)
+		}
 
 		threshold, ok := m["threshold"].(map[string]interface{})
 		if !ok {
@@ -697,11 +2387,49 @@ func parsePolicy(autoscaler *unstructured.Unstructured) (autoscalerPolicy, error
 			return autoscalerPolicy{}, fmt.Errorf("metric.threshold.scaleDown is required for %s", metricType)
 		}
 
+		schedules, err := parseThresholdSchedules(m["schedules"], metricType)
+		if err != nil {
+			return autoscalerPolicy{}, err
+		}
+
+		panicMode := metricType == "RejectedRequestRate"
+		if v, ok := m["panic"].(bool); ok {
+			panicMode = v
+		}
+
+		aggregation := stringValue(m["aggregation"])
+		switch aggregation {
+		case "":
+			aggregation = defaultAggregation(metricType)
+		case aggregationSum, aggregationAvg, aggregationMax:
+		default:
+			return autoscalerPolicy{}, fmt.Errorf("metric.aggregation must be sum, avg or max for %s, got %q", metricType, aggregation)
+		}
+
+		weight := 1.0
+		if v, found := m["weight"]; found {
+			if weight, ok = floatValue(v); !ok || weight <= 0 {
+				return autoscalerPolicy{}, fmt.Errorf("metric.weight must be a number > 0 for %s", metricType)
+			}
+		}
+
+		window := strings.TrimSpace(stringValue(m["window"]))
+		if window == "" {
+			window = defaultQueryWindow
+		} else if !promDurationPattern.MatchString(window) {
+			return autoscalerPolicy{}, fmt.Errorf("metric.window must be a PromQL duration such as 5m for %s, got %q", metricType, window)
+		}
+
 		policy.Metrics = append(policy.Metrics, metricPolicy{
-			Type:      metricType,
-			Query:     query,
-			ScaleUp:   up,
-			ScaleDown: down,
+			Type:        metricType,
+			Query:       query,
+			ScaleUp:     up,
+			ScaleDown:   down,
+			Schedules:   schedules,
+			Panic:       panicMode,
+			Aggregation: aggregation,
+			Weight:      weight,
+			Window:      window,
 		})
 	}
 
@@ -711,6 +2439,59 @@ func parsePolicy(autoscaler *unstructured.Unstructured) (autoscalerPolicy, error
 	if down, found, _ := unstructured.NestedInt64(spec, "behavior", "scaleDownStabilizationSeconds"); found {
 		policy.ScaleDownCooldownSeconds = int(down)
 	}
+	if step, found, _ := unstructured.NestedInt64(spec, "behavior", "scaleUpMaxStep"); found {
+		policy.ScaleUpMaxStep = int(step)
+	}
+	if pct, found, _ := unstructured.NestedInt64(spec, "behavior", "scaleUpMaxPercent"); found {
+		policy.ScaleUpMaxPercent = int(pct)
+	}
+	if policy.ScaleUpMaxStep < 1 || policy.ScaleUpMaxPercent < 0 {
+		return autoscalerPolicy{}, fmt.Errorf("behavior.scaleUpMaxStep must be >= 1 and scaleUpMaxPercent >= 0")
+	}
+	if window, found, _ := unstructured.NestedInt64(spec, "behavior", "scaleUpWindowSeconds"); found {
+		policy.ScaleUpWindowSeconds = int(window)
+	}
+	if window, found, _ := unstructured.NestedInt64(spec, "behavior", "scaleDownWindowSeconds"); found {
+		policy.ScaleDownWindowSeconds = int(window)
+	}
+	if reset, found, _ := unstructured.NestedBool(spec, "behavior", "scaleUpResetsScaleDown"); found {
+		policy.ScaleUpResetsScaleDown = reset
+	}
+	if reset, found, _ := unstructured.NestedBool(spec, "behavior", "scaleDownResetsScaleUp"); found {
+		policy.ScaleDownResetsScaleUp = reset
+	}
+	if mode, found, _ := unstructured.NestedString(spec, "behavior", "scaleDownMode"); found && strings.TrimSpace(mode) != "" {
+		switch mode {
+		case scaleDownModeBatch, scaleDownModeGradual:
+			policy.ScaleDownMode = mode
+		default:
+			return autoscalerPolicy{}, fmt.Errorf("behavior.scaleDownMode must be %q or %q", scaleDownModeBatch, scaleDownModeGradual)
+		}
+	}
+	if mode, found, _ := unstructured.NestedString(spec, "behavior", "decisionMode"); found && strings.TrimSpace(mode) != "" {
+		switch mode {
+		case decisionModeAny, decisionModeAll, decisionModeWeighted:
+			policy.DecisionMode = mode
+		default:
+			return autoscalerPolicy{}, fmt.Errorf("behavior.decisionMode must be %q, %q or %q",
+				decisionModeAny, decisionModeAll, decisionModeWeighted)
+		}
+	}
+	if grace, found, _ := unstructured.NestedInt64(spec, "behavior", "drainGracePeriodSeconds"); found {
+		if grace < 0 {
+			return autoscalerPolicy{}, fmt.Errorf("behavior.drainGracePeriodSeconds must be >= 0")
+		}
+		policy.DrainGraceSeconds = int(grace)
+	}
+	if victim, found, _ := unstructured.NestedString(spec, "behavior", "scaleDownPolicy"); found && strings.TrimSpace(victim) != "" {
+		switch victim {
+		case scaleDownPolicyNewest, scaleDownPolicyLeastActiveRequests, scaleDownPolicyLeastGPUUtilization:
+			policy.ScaleDownPolicy = victim
+		default:
+			return autoscalerPolicy{}, fmt.Errorf("behavior.scaleDownPolicy must be %q, %q or %q",
+				scaleDownPolicyNewest, scaleDownPolicyLeastActiveRequests, scaleDownPolicyLeastGPUUtilization)
+		}
+	}
 
 	if name, found, _ := unstructured.NestedString(spec, "routerRef", "name"); found {
 		policy.RouterName = strings.TrimSpace(name)
@@ -722,6 +2503,11 @@ func parsePolicy(autoscaler *unstructured.Unstructured) (autoscalerPolicy, error
 		policy.RouterBackendNamePrefix = prefix
 	}
 
+	// Replicas mode scales an existing LLMCluster; no instance template
+	if policy.Mode == modeReplicas {
+		return policy, nil
+	}
+
 	if prefix, found, _ := unstructured.NestedString(spec, "instanceTemplate", "namePrefix"); found {
 		policy.TemplateNamePrefix = prefix
 	}
@@ -787,33 +2573,254 @@ func parsePolicy(autoscaler *unstructured.Unstructured) (autoscalerPolicy, error
 	return policy, nil
 }
 
-func defaultQuery(metricType, appLabel, namespace string) string {
-	switch metricType {
-	case "QueueLength":
-		if appLabel == "" {
-			return ""
-		}
-		return fmt.Sprintf(`sum(redis_queue_length{app="%s",queue="request_queue"})`, appLabel)
-	case "TTFT":
-		if appLabel == "" {
-			return ""
-		}
-		return fmt.Sprintf(`histogram_quantile(0.95, sum(rate(llm_ttft_seconds_bucket{app="%s"}[2m])) by (le)) * 1000`, appLabel)
-	case "TPOT":
-		if appLabel == "" {
-			return ""
-		}
-		return fmt.Sprintf(`histogram_quantile(0.95, sum(rate(llm_tpot_seconds_bucket{app="%s"}[2m])) by (le)) * 1000`, appLabel)
-	case "Latency":
-		if appLabel == "" {
-			return ""
-		}
-		return fmt.Sprintf(`histogram_quantile(0.95, sum(rate(llm_request_latency_seconds_bucket{app="%s"}[2m])) by (le)) * 1000`, appLabel)
-	case "GPUUtilization":
-		return fmt.Sprintf(`avg(DCGM_FI_DEV_GPU_UTIL{namespace="%s"})`, namespace)
+// thresholdsAt returns the scale-up/scale-down thresholds active at now,
+// falling back to the static thresholds when no schedule window matches.
+func (m metricPolicy) thresholdsAt(now time.Time) (float64, float64) {
+	for _, schedule := range m.Schedules {
+		if schedule.active(now) {
+			return schedule.ScaleUp, schedule.ScaleDown
+		}
+	}
+	return m.ScaleUp, m.ScaleDown
+}
+
+func (s thresholdSchedule) active(now time.Time) bool {
+	local := now.In(s.Location)
+	if len(s.Days) > 0 && !s.Days[local.Weekday()] {
+		return false
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	if s.StartMinute <= s.EndMinute {
+		return minute >= s.StartMinute && minute < s.EndMinute
+	}
+	return minute >= s.StartMinute || minute < s.EndMinute
+}
+
+func parseThresholdSchedules(raw interface{}, metricType string) ([]thresholdSchedule, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("metric.schedules must be a list for %s", metricType)
+	}
+
+	schedules := make([]thresholdSchedule, 0, len(items))
+	for _, item := range items {
+		w, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid schedule item for %s", metricType)
+		}
+
+		start, err := parseClockMinute(stringValue(w["start"]))
+		if err != nil {
+			return nil, fmt.Errorf("metric.schedules.start for %s: %w", metricType, err)
+		}
+		end, err := parseClockMinute(stringValue(w["end"]))
+		if err != nil {
+			return nil, fmt.Errorf("metric.schedules.end for %s: %w", metricType, err)
+		}
+
+		location := time.UTC
+		if tz := stringValue(w["timezone"]); tz != "" {
+			location, err = time.LoadLocation(tz)
+			if err != nil {
+				return nil, fmt.Errorf("metric.schedules.timezone for %s: %w", metricType, err)
+			}
+		}
+
+		days := map[time.Weekday]bool{}
+		if rawDays, ok := w["days"].([]interface{}); ok {
+			for _, d := range rawDays {
+				day, ok := parseWeekday(stringValue(d))
+				if !ok {
+					return nil, fmt.Errorf("metric.schedules.days has invalid day %q for %s", stringValue(d), metricType)
+				}
+				days[day] = true
+			}
+		}
+
+		threshold, ok := w["threshold"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("metric.schedules.threshold is required for %s", metricType)
+		}
+		up, ok := floatValue(threshold["scaleUp"])
+		if !ok {
+			return nil, fmt.Errorf("metric.schedules.threshold.scaleUp is required for %s", metricType)
+		}
+		down, ok := floatValue(threshold["scaleDown"])
+		if !ok {
+			return nil, fmt.Errorf("metric.schedules.threshold.scaleDown is required for %s", metricType)
+		}
+
+		schedules = append(schedules, thresholdSchedule{
+			StartMinute: start,
+			EndMinute:   end,
+			Days:        days,
+			Location:    location,
+			ScaleUp:     up,
+			ScaleDown:   down,
+		})
+	}
+	return schedules, nil
+}
+
+// parseClockMinute parses "HH:MM" into minutes since midnight.
+func parseClockMinute(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func parseWeekday(value string) (time.Weekday, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "sun", "sunday":
+		return time.Sunday, true
+	case "mon", "monday":
+		return time.Monday, true
+	case "tue", "tuesday":
+		return time.Tuesday, true
+	case "wed", "wednesday":
+		return time.Wednesday, true
+	case "thu", "thursday":
+		return time.Thursday, true
+	case "fri", "friday":
+		return time.Friday, true
+	case "sat", "saturday":
+		return time.Saturday, true
 	default:
+		return 0, false
+	}
+}
+
+// queryTemplateData is what metric query templates are rendered with, e.g.
+// sum(rate(my_requests_total{app="{{.AppLabel}}"}[{{.Window}}])).
+// Instance is set only for per-instance queries (scale-down victim load).
+type queryTemplateData struct {
+	AppLabel  string
+	Namespace string
+	Instance  string
+	ReadyOnly bool
+	Window    string
+}
+
+// defaultQueryWindow is the range of the built-in rate/deriv queries.
+const defaultQueryWindow = "2m"
+
+// promDurationPattern matches PromQL durations such as 90s, 5m or 1h30m.
+var promDurationPattern = regexp.MustCompile(`^([0-9]+(ms|[smhdwy]))+$`)
+
+// podMatchers selects the app's pods, only Ready ones with readyOnly, so
+// instances still loading the model don't dilute per-pod latency series.
+const podMatchers = `app="{{.AppLabel}}"{{if .ReadyOnly}},` + readyMetricMatcher + `{{end}}`
+
+// defaultQueryTemplates are the built-in query templates per metric type.
+var defaultQueryTemplates = map[string]string{
+	"QueueLength": `sum(redis_queue_length{app="{{.AppLabel}}",queue="request_queue"})`,
+	// Queued requests gained per second; rises before the queue is long.
+	"QueueGrowthRate": `deriv(sum(redis_queue_length{app="{{.AppLabel}}",queue="request_queue"})[{{.Window}}:15s])`,
+	"TTFT":            `histogram_quantile(0.95, sum(rate(llm_ttft_seconds_bucket{` + podMatchers + `}[{{.Window}}])) by (le)) * 1000`,
+	"TPOT":            `histogram_quantile(0.95, sum(rate(llm_tpot_seconds_bucket{` + podMatchers + `}[{{.Window}}])) by (le)) * 1000`,
+	"Latency":         `histogram_quantile(0.95, sum(rate(llm_request_latency_seconds_bucket{` + podMatchers + `}[{{.Window}}])) by (le)) * 1000`,
+	"ActiveRequests":  `sum(vllm:num_requests_running{` + podMatchers + `})`,
+	// Requests turned away with 429 per second; any sustained rate means
+	// the fleet is already saturated.
+	"RejectedRequestRate": `sum(rate(llm_requests_rejected_total{` + podMatchers + `}[{{.Window}}]))`,
+	"GPUUtilization":      `avg(DCGM_FI_DEV_GPU_UTIL{namespace="{{.Namespace}}"{{if .ReadyOnly}},` + readyMetricMatcher + `{{end}}})`,
+}
+
+// Per-instance load templates for the load-based scale-down policies; an
+// instance's pods carry app=<instance name>.
+const (
+	instanceActiveRequestsQuery = `sum(vllm:num_requests_running{app="{{.Instance}}",namespace="{{.Namespace}}"})`
+	instanceGPUUtilizationQuery = `avg(DCGM_FI_DEV_GPU_UTIL{namespace="{{.Namespace}}",pod=~"{{.Instance}}-.*"})`
+)
+
+// defaultQuery returns the built-in query template for a metric type, or ""
+// if there is none. All but GPUUtilization select pods by app label and have
+// no default without one.
+func defaultQuery(metricType, appLabel string) string {
+	if appLabel == "" && metricType != "GPUUtilization" {
 		return ""
 	}
+	return defaultQueryTemplates[metricType]
+}
+
+// renderQuery renders a query template; plain PromQL renders unchanged.
+// Unknown fields such as {{.Foo}} are errors, caught by parsePolicy.
+func renderQuery(text string, data queryTemplateData) (string, error) {
+	tmpl, err := template.New("query").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// instanceLoadQuery returns the PromQL measuring one instance's load for a
+// load-based scale-down policy.
+func instanceLoadQuery(scaleDownPolicy, instanceName, namespace string) (string, error) {
+	text := instanceActiveRequestsQuery
+	if scaleDownPolicy == scaleDownPolicyLeastGPUUtilization {
+		text = instanceGPUUtilizationQuery
+	}
+	return renderQuery(text, queryTemplateData{Namespace: namespace, Instance: instanceName})
+}
+
+// scaleUpStep returns how many instances (or replicas) one scale-up adds:
+// enough to bring the most overloaded metric back to its threshold, assuming
+// load spreads evenly (current × value/threshold), within the policy's step
+// limit. The limit is ScaleUpMaxStep or ScaleUpMaxPercent of current,
+// whichever is larger, so the default of one keeps single steps.
+func scaleUpStep(policy autoscalerPolicy, decision scaleDecision, current int) int {
+	limit := policy.ScaleUpMaxStep
+	if pct := policy.ScaleUpMaxPercent; pct > 0 {
+		if byPercent := int(math.Ceil(float64(current) * float64(pct) / 100)); byPercent > limit {
+			limit = byPercent
+		}
+	}
+	if limit <= 1 {
+		return 1
+	}
+
+	step := limit
+	if current > 0 && !math.IsInf(decision.Overload, 1) {
+		step = int(math.Ceil(float64(current)*decision.Overload)) - current
+	}
+	if step < 1 {
+		step = 1
+	}
+	if step > limit {
+		step = limit
+	}
+	return step
+}
+
+// recommendedInstances returns the instance count the decision calls for,
+// scaleUpStep up or one down, clamped to [MinInstances, MaxInstances].
+func recommendedInstances(policy autoscalerPolicy, decision scaleDecision, current int) int {
+	desired := current
+	if decision.MetricsAvailable {
+		switch {
+		case decision.ScaleUp:
+			desired = current + scaleUpStep(policy, decision, current)
+		case decision.ScaleDown:
+			desired = current - 1
+		}
+	}
+	if desired < policy.MinInstances {
+		desired = policy.MinInstances
+	}
+	if desired > policy.MaxInstances {
+		desired = policy.MaxInstances
+	}
+	return desired
 }
 
 func newestInstance(instances []*unstructured.Unstructured) *unstructured.Unstructured {
@@ -823,10 +2830,13 @@ func newestInstance(instances []*unstructured.Unstructured) *unstructured.Unstru
 	return instances[len(instances)-1]
 }
 
-func filterInstances(instances []*unstructured.Unstructured, removeName string) []*unstructured.Unstructured {
+// unprotectedInstances drops instances annotated
+// autoscaling.serving.ai/protected=true (e.g. pinned to reserved capacity),
+// which scale-down must never remove.
+func unprotectedInstances(instances []*unstructured.Unstructured) []*unstructured.Unstructured {
 	out := make([]*unstructured.Unstructured, 0, len(instances))
 	for _, instance := range instances {
-		if instance.GetName() == removeName {
+		if protected, _ := strconv.ParseBool(instance.GetAnnotations()[annotationProtected]); protected {
 			continue
 		}
 		out = append(out, instance)
@@ -834,6 +2844,18 @@ func filterInstances(instances []*unstructured.Unstructured, removeName string)
 	return out
 }
 
+// leastLoadedInstance returns the instance with the lowest load; ties go to
+// the newest, matching the Newest policy.
+func leastLoadedInstance(instances []*unstructured.Unstructured, loads map[string]float64) *unstructured.Unstructured {
+	var victim *unstructured.Unstructured
+	for i := len(instances) - 1; i >= 0; i-- {
+		if victim == nil || loads[instances[i].GetName()] < loads[victim.GetName()] {
+			victim = instances[i]
+		}
+	}
+	return victim
+}
+
 func nextInstanceName(prefix string, existing []*unstructured.Unstructured) string {
 	maxIndex := 0
 	for _, item := range existing {
@@ -932,7 +2954,7 @@ func startHealthServer(ctx context.Context, addr string) {
 	}()
 }
 
-func startMetricsServer(ctx context.Context, addr string) {
+func startMetricsServer(ctx context.Context, addr string, metrics *autoscalerMetrics) {
 	if strings.TrimSpace(addr) == "" || addr == "0" {
 		return
 	}
@@ -940,7 +2962,7 @@ func startMetricsServer(ctx context.Context, addr string) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
-		_, _ = w.Write([]byte("# llmcluster autoscaler metrics are exported by logging in this example\n"))
+		_, _ = w.Write([]byte(metrics.render()))
 	})
 
 	server := &http.Server{
@@ -992,6 +3014,11 @@ func main() {
 		healthProbeBindAddress  string
 		metricsBindAddress      string
 		zapLogLevel             string
+		defaultsConfigMap       string
+		shareQueries            bool
+		bearerTokenFile         string
+		otlpEndpoint            string
+		otlpInsecure            bool
 	)
 
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig (optional)")
@@ -1003,9 +3030,13 @@ func main() {
 	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "", "Leader election lease namespace")
 	flag.StringVar(&healthProbeBindAddress, "health-probe-bind-address", ":8081", "Health probe bind address")
 	flag.StringVar(&metricsBindAddress, "metrics-bind-address", ":8080", "Metrics bind address")
-	flag.StringVar(&zapLogLevel, "zap-log-level", "info", "Log level placeholder for deployment compatibility")
+	flag.StringVar(&zapLogLevel, "zap-log-level", "info", "Log level (debug also logs unchanged reconciles)")
+	flag.BoolVar(&shareQueries, "share-queries", true, "Send identical Prometheus queries once per sync cycle across autoscalers")
+	flag.StringVar(&defaultsConfigMap, "defaults-configmap", "", "ConfigMap (namespace/name) with operator-wide autoscaler defaults")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "", "OTLP/gRPC collector (host:port) receiving reconcile traces; empty disables tracing")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "Send traces to the OTLP collector without TLS")
+	flag.StringVar(&bearerTokenFile, "prometheus-bearer-token-file", "", "File with a bearer token sent on Prometheus queries without spec.prometheus credentials (else $PROMETHEUS_BEARER_TOKEN)")
 	flag.Parse()
-	_ = zapLogLevel // Kept for arg compatibility with deployment manifest.
 
 	if strings.TrimSpace(leaderElectionNamespace) == "" {
 		leaderElectionNamespace = os.Getenv("POD_NAMESPACE")
@@ -1029,13 +3060,51 @@ func main() {
 		log.Fatalf("create kubernetes client failed: %v", err)
 	}
 
-	ctrl := newController(dynamicClient, syncInterval, queryTimeout, drainDelay)
+	ctrl, err := newController(dynamicClient, restConfig, syncInterval, queryTimeout, drainDelay)
+	if err != nil {
+		log.Fatalf("create controller failed: %v", err)
+	}
+	ctrl.verbose = zapLogLevel == "debug"
+	ctrl.shareQueries = shareQueries
+	if querier, ok := ctrl.querier.(*prometheusQuerier); ok {
+		querier.bearerTokenFile = bearerTokenFile
+		querier.bearerToken = os.Getenv("PROMETHEUS_BEARER_TOKEN")
+	}
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	ctrl.recorder = broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "llmcluster-autoscaler"})
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	if otlpEndpoint != "" {
+		shutdown, err := setupTracing(ctx, otlpEndpoint, otlpInsecure)
+		if err != nil {
+			log.Fatalf("set up tracing failed: %v", err)
+		}
+		defer func() {
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(flushCtx); err != nil {
+				log.Printf("warning: flush traces failed: %v", err)
+			}
+		}()
+		log.Printf("exporting reconcile traces to %s", otlpEndpoint)
+	}
+
+	if defaultsConfigMap != "" {
+		defaults, err := loadOperatorDefaults(ctx, kubeClient, defaultsConfigMap)
+		if err != nil {
+			log.Fatalf("load defaults configmap failed: %v", err)
+		}
+		ctrl.defaults = defaults
+		log.Printf("loaded operator defaults from %s (prometheus=%s, %d library queries)",
+			defaultsConfigMap, defaults.PrometheusAddress, len(defaults.Queries))
+	}
+
 	startHealthServer(ctx, healthProbeBindAddress)
-	startMetricsServer(ctx, metricsBindAddress)
+	startMetricsServer(ctx, metricsBindAddress, ctrl.metrics)
 
 	if !leaderElect {
 		ctrl.run(ctx)
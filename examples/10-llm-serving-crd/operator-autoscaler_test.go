@@ -0,0 +1,2229 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeQuerier is a metricQuerier returning canned values per query text,
+// or err for every query.
+type fakeQuerier struct {
+	values  map[string][]float64
+	err     error
+	queries []string
+}
+
+func (f *fakeQuerier) Query(_ context.Context, _, query string) ([]float64, error) {
+	f.queries = append(f.queries, query)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.values[query], nil
+}
+
+// newTestController returns a controller querying querier, backed by a
+// fake dynamic client holding objects.
+func newTestController(querier metricQuerier, objects ...runtime.Object) *controller {
+	c := &controller{
+		autoscalerGVR:     schema.GroupVersionResource{Group: "serving.ai", Version: "v1alpha1", Resource: "llmclusterautoscalers"},
+		llmclusterGVR:     schema.GroupVersionResource{Group: "serving.ai", Version: "v1alpha1", Resource: "llmclusters"},
+		podGVR:            schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+		pdbGVR:            schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"},
+		secretGVR:         schema.GroupVersionResource{Version: "v1", Resource: "secrets"},
+		querier:           querier,
+		defaults:          builtinDefaults(),
+		metrics:           newAutoscalerMetrics(),
+		lastReconcile:     map[string]reconcileSnapshot{},
+		recentDecisions:   map[string][]scaleDecision{},
+		unsavedScaleTimes: map[string]map[string]time.Time{},
+		authQueriers:      map[string]authQuerierEntry{},
+		socketQueriers:    map[string]*prometheusQuerier{},
+	}
+	c.dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		c.autoscalerGVR: "LLMClusterAutoscalerList",
+		c.llmclusterGVR: "LLMClusterList",
+		c.podGVR:        "PodList",
+		c.pdbGVR:        "PodDisruptionBudgetList",
+		c.secretGVR:     "SecretList",
+	}, objects...)
+	return c
+}
+
+// newTestInstance returns a Running LLMCluster instance of app "llama".
+func newTestInstance(name string) *unstructured.Unstructured {
+	instance := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "serving.ai/v1alpha1",
+		"kind":       "LLMCluster",
+		"status":     map[string]interface{}{"phase": "Running"},
+	}}
+	instance.SetNamespace("default")
+	instance.SetName(name)
+	instance.SetLabels(map[string]string{"app": "llama", "serving.ai/role": "instance"})
+	return instance
+}
+
+// newTestAutoscaler returns an LLMClusterAutoscaler for app "llama" with
+// spec merged over 1-5 instances. Numbers must be int64 or float64, as
+// decoded from JSON.
+func newTestAutoscaler(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	merged := map[string]interface{}{
+		"scaleTargetRef": map[string]interface{}{"appLabel": "llama"},
+		"minInstances":   int64(1),
+		"maxInstances":   int64(5),
+		"instanceTemplate": map[string]interface{}{
+			"namePrefix": "llama-",
+			"spec":       map[string]interface{}{"model": "meta-llama/Meta-Llama-3-8B", "replicas": int64(1)},
+		},
+	}
+	for k, v := range spec {
+		merged[k] = v
+	}
+	autoscaler := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "serving.ai/v1alpha1",
+		"kind":       "LLMClusterAutoscaler",
+		"spec":       merged,
+	}}
+	autoscaler.SetNamespace("default")
+	autoscaler.SetName(name)
+	return autoscaler
+}
+
+// testMetric is a metric with a fixed query text, so fakeQuerier can answer
+// it by name.
+func testMetric(metricType, query string, scaleUp, scaleDown float64) map[string]interface{} {
+	return map[string]interface{}{
+		"type":      metricType,
+		"query":     query,
+		"threshold": map[string]interface{}{"scaleUp": scaleUp, "scaleDown": scaleDown},
+	}
+}
+
+func TestEvaluateDecisionThresholds(t *testing.T) {
+	queue := testMetric("QueueLength", "queue", 100, 20)
+	ttft := testMetric("TTFT", "ttft", 2000, 500)
+
+	tests := []struct {
+		name      string
+		mode      string
+		metrics   []interface{}
+		values    map[string][]float64
+		err       error
+		scaleUp   bool
+		scaleDown bool
+		available bool
+	}{
+		{name: "above scale-up", metrics: []interface{}{queue}, values: map[string][]float64{"queue": {100.01}}, scaleUp: true, available: true},
+		{name: "at scale-up holds", metrics: []interface{}{queue}, values: map[string][]float64{"queue": {100}}, available: true},
+		{name: "at scale-down holds", metrics: []interface{}{queue}, values: map[string][]float64{"queue": {20}}, available: true},
+		{name: "below scale-down", metrics: []interface{}{queue}, values: map[string][]float64{"queue": {19.99}}, scaleDown: true, available: true},
+		{name: "series summed before comparison", metrics: []interface{}{queue}, values: map[string][]float64{"queue": {60, 41}}, scaleUp: true, available: true},
+		{name: "no samples blocks", metrics: []interface{}{queue}, values: map[string][]float64{}},
+		{name: "query error blocks", metrics: []interface{}{queue}, err: errors.New("connection refused")},
+		{
+			name:      "any: one metric above scales up",
+			metrics:   []interface{}{queue, ttft},
+			values:    map[string][]float64{"queue": {101}, "ttft": {100}},
+			scaleUp:   true,
+			available: true,
+		},
+		{
+			name:      "any: scale-down needs every metric below",
+			metrics:   []interface{}{queue, ttft},
+			values:    map[string][]float64{"queue": {10}, "ttft": {500}},
+			available: true,
+		},
+		{
+			name:      "all: one metric above holds",
+			mode:      decisionModeAll,
+			metrics:   []interface{}{queue, ttft},
+			values:    map[string][]float64{"queue": {101}, "ttft": {1999}},
+			available: true,
+		},
+		{
+			name:      "all: every metric above scales up",
+			mode:      decisionModeAll,
+			metrics:   []interface{}{queue, ttft},
+			values:    map[string][]float64{"queue": {101}, "ttft": {2001}},
+			scaleUp:   true,
+			available: true,
+		},
+		{
+			name:      "weighted: mean ratio of exactly 1 holds",
+			mode:      decisionModeWeighted,
+			metrics:   []interface{}{queue, ttft},
+			values:    map[string][]float64{"queue": {150}, "ttft": {1000}},
+			available: true,
+		},
+		{
+			name:      "weighted: mean ratio above 1 scales up",
+			mode:      decisionModeWeighted,
+			metrics:   []interface{}{queue, ttft},
+			values:    map[string][]float64{"queue": {150}, "ttft": {1002}},
+			scaleUp:   true,
+			available: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := map[string]interface{}{"metrics": tt.metrics}
+			if tt.mode != "" {
+				spec["behavior"] = map[string]interface{}{"decisionMode": tt.mode}
+			}
+			policy, err := parsePolicy(newTestAutoscaler("llama", spec), builtinDefaults())
+			if err != nil {
+				t.Fatalf("parsePolicy: %v", err)
+			}
+
+			c := newTestController(&fakeQuerier{values: tt.values, err: tt.err})
+			decision, err := c.evaluateDecision(context.Background(), policy)
+			if err != nil {
+				t.Fatalf("evaluateDecision: %v", err)
+			}
+			if decision.ScaleUp != tt.scaleUp || decision.ScaleDown != tt.scaleDown || decision.MetricsAvailable != tt.available {
+				t.Errorf("got scaleUp=%v scaleDown=%v available=%v (%s), want %v %v %v",
+					decision.ScaleUp, decision.ScaleDown, decision.MetricsAvailable, decision.Reason,
+					tt.scaleUp, tt.scaleDown, tt.available)
+			}
+		})
+	}
+}
+
+func TestReconcileTracesPhases(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+	})
+	c := newTestController(&fakeQuerier{values: map[string][]float64{"queue": {50}}},
+		autoscaler, newTestInstance("llama-a"), newTestInstance("llama-b"))
+	c.reconcileAll(context.Background())
+
+	spans := recorder.Ended()
+	var root sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		if span.Name() == "reconcileAutoscaler" {
+			root = span
+		}
+	}
+	if root == nil {
+		t.Fatalf("no reconcileAutoscaler span among %d spans", len(spans))
+	}
+
+	phases := map[string]bool{}
+	for _, span := range spans {
+		if span.Parent().SpanID() != root.SpanContext().SpanID() {
+			continue
+		}
+		for _, attr := range span.Attributes() {
+			if attr.Key == "autoscaler.name" && attr.Value.AsString() == "llama" {
+				phases[span.Name()] = true
+			}
+		}
+	}
+	for _, phase := range []string{"query", "decide", "scale", "router", "status"} {
+		if !phases[phase] {
+			t.Errorf("missing %q span under reconcileAutoscaler, got %v", phase, phases)
+		}
+	}
+}
+
+// conflictOnce makes the first update of resource (and subresource) fail
+// with 409 Conflict, as when another writer got there first.
+func conflictOnce(c *controller, subresource string) *int {
+	attempts := 0
+	fake := c.dynamicClient.(*dynamicfake.FakeDynamicClient)
+	fake.PrependReactor("update", c.autoscalerGVR.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != subresource {
+			return false, nil, nil
+		}
+		attempts++
+		if attempts == 1 {
+			return true, nil, apierrors.NewConflict(c.autoscalerGVR.GroupResource(), "llama", errors.New("object was modified"))
+		}
+		return false, nil, nil
+	})
+	return &attempts
+}
+
+func TestAutoscalerWritesRetryOnConflict(t *testing.T) {
+	ctx := context.Background()
+	policy := autoscalerPolicy{Namespace: "default", Name: "llama"}
+
+	c := newTestController(&fakeQuerier{}, newTestAutoscaler("llama", nil))
+	attempts := conflictOnce(c, "")
+	if err := c.patchAutoscalerAnnotations(ctx, "default", "llama", map[string]string{annotationScaleDownStep: "2"}); err != nil {
+		t.Fatalf("patchAutoscalerAnnotations: %v", err)
+	}
+	obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *attempts != 2 || obj.GetAnnotations()[annotationScaleDownStep] != "2" {
+		t.Errorf("annotations after %d attempts: %v", *attempts, obj.GetAnnotations())
+	}
+
+	c = newTestController(&fakeQuerier{}, newTestAutoscaler("llama", nil))
+	attempts = conflictOnce(c, "status")
+	decision := scaleDecision{MetricsAvailable: true}
+	if err := c.updateAutoscalerStatus(ctx, policy, decision, "ScaleUp", "queue high", 3, 3); err != nil {
+		t.Fatalf("updateAutoscalerStatus: %v", err)
+	}
+	obj, err = c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action, _, _ := unstructured.NestedString(obj.Object, "status", "lastScaleAction"); *attempts != 2 || action != "ScaleUp" {
+		t.Errorf("status after %d attempts: lastScaleAction=%q", *attempts, action)
+	}
+}
+
+func TestScaleCooldownPassed(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(seconds int) string {
+		return now.Add(-time.Duration(seconds) * time.Second).Format(time.RFC3339)
+	}
+	agoEpoch := func(seconds int) string {
+		return strconv.FormatInt(now.Add(-time.Duration(seconds)*time.Second).Unix(), 10)
+	}
+
+	tests := []struct {
+		name        string
+		status      map[string]interface{}
+		annotations map[string]string
+		unsaved     map[string]time.Time
+		scaleUp     bool
+		crossReset  bool
+		want        bool
+	}{
+		{name: "never scaled", scaleUp: true, want: true},
+		{name: "scale-up within cooldown", status: map[string]interface{}{"lastScaleUpTime": ago(30)}, scaleUp: true},
+		{name: "scale-up after cooldown", status: map[string]interface{}{"lastScaleUpTime": ago(121)}, scaleUp: true, want: true},
+		{name: "other direction ignored", status: map[string]interface{}{"lastScaleDownTime": ago(30)}, scaleUp: true, want: true},
+		{name: "other direction with cross reset", status: map[string]interface{}{"lastScaleDownTime": ago(30)}, scaleUp: true, crossReset: true},
+		{name: "scale-down within cooldown", status: map[string]interface{}{"lastScaleDownTime": ago(30)}},
+		{name: "legacy annotation", annotations: map[string]string{annotationLegacyLastScaleUp: agoEpoch(30)}, scaleUp: true},
+		{
+			name:        "status wins over legacy annotation",
+			status:      map[string]interface{}{"lastScaleUpTime": ago(600)},
+			annotations: map[string]string{annotationLegacyLastScaleUp: agoEpoch(30)},
+			scaleUp:     true,
+			want:        true,
+		},
+		{
+			name:    "unsaved scale time",
+			status:  map[string]interface{}{"lastScaleUpTime": ago(600)},
+			unsaved: map[string]time.Time{"lastScaleUpTime": now.Add(-30 * time.Second)},
+			scaleUp: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			autoscaler := newTestAutoscaler("llama", nil)
+			if tt.status != nil {
+				autoscaler.Object["status"] = tt.status
+			}
+			autoscaler.SetAnnotations(tt.annotations)
+			c := newTestController(&fakeQuerier{})
+			if tt.unsaved != nil {
+				c.unsavedScaleTimes["default/llama"] = tt.unsaved
+			}
+			if got := c.scaleCooldownPassed(autoscaler, tt.scaleUp, 120, tt.crossReset, now); got != tt.want {
+				t.Errorf("scaleCooldownPassed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileHonorsCooldownFromStatus(t *testing.T) {
+	ctx := context.Background()
+	autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+	})
+	autoscaler.Object["status"] = map[string]interface{}{
+		"lastScaleUpTime": time.Now().Add(-10 * time.Second).Format(time.RFC3339),
+	}
+	c := newTestController(&fakeQuerier{values: map[string][]float64{"queue": {500}}}, autoscaler, newTestInstance("llama-a"))
+
+	if err := c.reconcileAutoscaler(ctx, autoscaler); err != nil {
+		t.Fatal(err)
+	}
+	list, err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace("default").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 {
+		t.Errorf("scaled up to %d instances during the cooldown", len(list.Items))
+	}
+	obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if len(conditions) == 0 || conditions[0].(map[string]interface{})["message"] != "scale-up cooldown active" {
+		t.Errorf("conditions = %v, want the scale-up cooldown reported", conditions)
+	}
+}
+
+func TestFailedScaleStatusWriteKeepsCooldown(t *testing.T) {
+	ctx := context.Background()
+	autoscaler := newTestAutoscaler("llama", nil)
+	policy := autoscalerPolicy{Namespace: "default", Name: "llama"}
+	decision := scaleDecision{MetricsAvailable: true}
+
+	c := newTestController(&fakeQuerier{}, autoscaler)
+	failing := true
+	fake := c.dynamicClient.(*dynamicfake.FakeDynamicClient)
+	fake.PrependReactor("update", c.autoscalerGVR.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if failing && action.GetSubresource() == "status" {
+			return true, nil, errors.New("etcdserver: request timed out")
+		}
+		return false, nil, nil
+	})
+
+	if err := c.publishStatus(ctx, autoscaler, policy, decision, "ScaleUp", "queue high", 2, 2); err == nil {
+		t.Fatal("failed status write after a scale-up was not returned")
+	}
+	if c.scaleCooldownPassed(autoscaler, true, 120, false, time.Now()) {
+		t.Error("cooldown passed although the scale-up time was never written")
+	}
+
+	failing = false
+	if err := c.publishStatus(ctx, autoscaler, policy, decision, "NoOp", "scale-up cooldown active", 2, 2); err != nil {
+		t.Fatal(err)
+	}
+	obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _, _ := unstructured.NestedString(obj.Object, "status", "lastScaleUpTime"); value == "" {
+		t.Error("unsaved scale-up time not persisted by the next status write")
+	}
+	if len(c.unsavedScaleTimes) != 0 {
+		t.Errorf("unsaved scale times kept after a successful write: %v", c.unsavedScaleTimes)
+	}
+}
+
+func TestDecodeQueryResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		resultType string
+		result     string
+		want       []float64
+		wantErr    bool
+	}{
+		{name: "scalar", resultType: "scalar", result: `[1700000000.5, "42"]`, want: []float64{42}},
+		{
+			name:       "vector series",
+			resultType: "vector",
+			result: `[
+				{"metric": {"pod": "llama-a-0"}, "value": [1700000000, "3"]},
+				{"metric": {"pod": "llama-b-0"}, "value": [1700000000, "1.5"]},
+				{"metric": {"pod": "llama-c-0"}, "value": [1700000000, "NaN"]}
+			]`,
+			want: []float64{3, 1.5, math.NaN()},
+		},
+		{name: "empty vector", resultType: "vector", result: `[]`, want: []float64{}},
+		{
+			name:       "matrix takes the latest point",
+			resultType: "matrix",
+			result: `[
+				{"metric": {"pod": "llama-a-0"}, "values": [[1700000000, "1"], [1700000015, "7"]]},
+				{"metric": {"pod": "llama-b-0"}, "values": []},
+				{"metric": {"pod": "llama-c-0"}, "values": [[1700000015, "2"]]}
+			]`,
+			want: []float64{7, 2},
+		},
+		{name: "unsupported type", resultType: "string", result: `[1700000000, "x"]`, wantErr: true},
+		{name: "bad number", resultType: "vector", result: `[{"value": [1700000000, "many"]}]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeQueryResult(tt.resultType, json.RawMessage(tt.result))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("values = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] && !(math.IsNaN(got[i]) && math.IsNaN(tt.want[i])) {
+					t.Errorf("values = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateSeries(t *testing.T) {
+	values := []float64{3, 9, 1.5, 2.5}
+	for aggregation, want := range map[string]float64{
+		aggregationSum: 16,
+		aggregationAvg: 4,
+		aggregationMax: 9,
+		"":             16,
+	} {
+		if got := aggregateSeries(values, aggregation); got != want {
+			t.Errorf("aggregateSeries(%v, %q) = %v, want %v", values, aggregation, got, want)
+		}
+	}
+	if got := aggregateSeries([]float64{5}, aggregationAvg); got != 5 {
+		t.Errorf("single series avg = %v, want 5", got)
+	}
+}
+
+func TestPrometheusQueryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": "error", "errorType": "bad_data", "error": "parse error at char 5: 100% unexpected"}`)
+	}))
+	defer server.Close()
+
+	p := &prometheusQuerier{httpClient: server.Client()}
+	_, err := p.Query(context.Background(), server.URL, "sum(")
+	if err == nil || err.Error() != "parse error at char 5: 100% unexpected" {
+		t.Errorf("err = %v, want the Prometheus message verbatim", err)
+	}
+}
+
+func TestThresholdSchedules(t *testing.T) {
+	queue := testMetric("QueueLength", "queue", 100, 20)
+	queue["schedules"] = []interface{}{
+		map[string]interface{}{
+			"start":     "09:00",
+			"end":       "18:00",
+			"days":      []interface{}{"Mon", "Tue", "Wed", "Thu", "Fri"},
+			"threshold": map[string]interface{}{"scaleUp": float64(40), "scaleDown": float64(10)},
+		},
+		map[string]interface{}{
+			"start":     "22:00",
+			"end":       "06:00",
+			"threshold": map[string]interface{}{"scaleUp": float64(200), "scaleDown": float64(50)},
+		},
+	}
+	policy, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{"metrics": []interface{}{queue}}), builtinDefaults())
+	if err != nil {
+		t.Fatalf("parsePolicy: %v", err)
+	}
+	metric := policy.Metrics[0]
+
+	// 2026-01-05 is a Monday
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 1, day, hour, minute, 0, 0, time.UTC) }
+	tests := []struct {
+		name     string
+		now      time.Time
+		up, down float64
+	}{
+		{name: "weekday peak", now: at(5, 12, 0), up: 40, down: 10},
+		{name: "peak start is inclusive", now: at(5, 9, 0), up: 40, down: 10},
+		{name: "peak end is exclusive", now: at(5, 18, 0), up: 100, down: 20},
+		{name: "weekend at peak hours", now: at(10, 12, 0), up: 100, down: 20},
+		{name: "overnight before midnight", now: at(5, 23, 30), up: 200, down: 50},
+		{name: "overnight after midnight", now: at(6, 5, 59), up: 200, down: 50},
+		{name: "no window matches", now: at(6, 7, 0), up: 100, down: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if up, down := metric.thresholdsAt(tt.now); up != tt.up || down != tt.down {
+				t.Errorf("thresholds = %v/%v, want %v/%v", up, down, tt.up, tt.down)
+			}
+		})
+	}
+}
+
+func TestRecommendModeMutatesNothing(t *testing.T) {
+	ctx := context.Background()
+	autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+		"mode":    modeRecommend,
+		"metrics": []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+	})
+	// A drain left over from before the switch to recommend mode, long
+	// past its window: finishing it would delete the instance.
+	drained := newTestInstance("llama-b")
+	drained.SetAnnotations(map[string]string{annotationDrainingSince: "1"})
+	c := newTestController(&fakeQuerier{values: map[string][]float64{"queue": {500}}}, autoscaler, newTestInstance("llama-a"), drained)
+	fake := c.dynamicClient.(*dynamicfake.FakeDynamicClient)
+
+	if err := c.reconcileAutoscaler(ctx, autoscaler); err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range fake.Actions() {
+		switch action.GetVerb() {
+		case "get", "list", "watch":
+		case "update":
+			if action.GetResource() == c.autoscalerGVR && action.GetSubresource() == "status" {
+				continue
+			}
+			t.Errorf("recommend mode issued %s %s/%s", action.GetVerb(), action.GetResource().Resource, action.GetSubresource())
+		default:
+			t.Errorf("recommend mode issued %s %s", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+
+	obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "recommendation", "desiredInstances")
+	reason, _, _ := unstructured.NestedString(obj.Object, "status", "recommendation", "reason")
+	if desired != 3 || reason == "" {
+		t.Errorf("recommendation = %d (%q), want 3 with a reason", desired, reason)
+	}
+	if current, _, _ := unstructured.NestedInt64(obj.Object, "status", "currentInstances"); current != 2 {
+		t.Errorf("currentInstances = %d, want 2", current)
+	}
+}
+
+func TestScaleDownVictim(t *testing.T) {
+	instances := []*unstructured.Unstructured{newTestInstance("llama-1"), newTestInstance("llama-2"), newTestInstance("llama-3")}
+	loadQuery := func(scaleDownPolicy, name string) string {
+		query, err := instanceLoadQuery(scaleDownPolicy, name, "default")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return query
+	}
+	loads := func(scaleDownPolicy string, values ...float64) map[string][]float64 {
+		out := map[string][]float64{}
+		for i, v := range values {
+			out[loadQuery(scaleDownPolicy, instances[i].GetName())] = []float64{v}
+		}
+		return out
+	}
+
+	for name, tc := range map[string]struct {
+		scaleDownPolicy string
+		values          map[string][]float64
+		protected       string
+		want            string
+	}{
+		"newest ignores load": {
+			scaleDownPolicy: scaleDownPolicyNewest,
+			values:          loads(scaleDownPolicyLeastActiveRequests, 0, 5, 9),
+			want:            "llama-3",
+		},
+		"least active requests": {
+			scaleDownPolicy: scaleDownPolicyLeastActiveRequests,
+			values:          loads(scaleDownPolicyLeastActiveRequests, 4, 1, 9),
+			want:            "llama-2",
+		},
+		"least GPU utilization": {
+			scaleDownPolicy: scaleDownPolicyLeastGPUUtilization,
+			values:          loads(scaleDownPolicyLeastGPUUtilization, 20, 80, 35),
+			want:            "llama-1",
+		},
+		"tie goes to newest": {
+			scaleDownPolicy: scaleDownPolicyLeastActiveRequests,
+			values:          loads(scaleDownPolicyLeastActiveRequests, 2, 2, 2),
+			want:            "llama-3",
+		},
+		"missing data falls back to newest": {
+			scaleDownPolicy: scaleDownPolicyLeastActiveRequests,
+			values:          loads(scaleDownPolicyLeastActiveRequests, 0, 3),
+			want:            "llama-3",
+		},
+		"protected instance skipped": {
+			scaleDownPolicy: scaleDownPolicyLeastActiveRequests,
+			values:          loads(scaleDownPolicyLeastActiveRequests, 0, 3, 9),
+			protected:       "llama-1",
+			want:            "llama-2",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			candidates := make([]*unstructured.Unstructured, len(instances))
+			for i, instance := range instances {
+				candidates[i] = instance.DeepCopy()
+				if instance.GetName() == tc.protected {
+					candidates[i].SetAnnotations(map[string]string{annotationProtected: "true"})
+				}
+			}
+			c := newTestController(&fakeQuerier{values: tc.values})
+			policy := autoscalerPolicy{Namespace: "default", Name: "llama", ScaleDownPolicy: tc.scaleDownPolicy}
+
+			victim := c.scaleDownVictim(context.Background(), policy, candidates)
+			if victim == nil || victim.GetName() != tc.want {
+				t.Fatalf("victim = %v, want %s", victim, tc.want)
+			}
+		})
+	}
+}
+
+func TestScaleDownVictimQueryError(t *testing.T) {
+	instances := []*unstructured.Unstructured{newTestInstance("llama-1"), newTestInstance("llama-2")}
+	c := newTestController(&fakeQuerier{err: fmt.Errorf("connection refused")})
+	policy := autoscalerPolicy{Namespace: "default", Name: "llama", ScaleDownPolicy: scaleDownPolicyLeastActiveRequests}
+
+	if victim := c.scaleDownVictim(context.Background(), policy, instances); victim.GetName() != "llama-2" {
+		t.Errorf("victim = %s, want the newest instance llama-2", victim.GetName())
+	}
+}
+
+func TestAdvanceDrainsWaitsForDrainWindow(t *testing.T) {
+	ctx := context.Background()
+	marked := time.Now()
+	victim := newTestInstance("llama-2")
+	victim.SetAnnotations(map[string]string{annotationDrainingSince: strconv.FormatInt(marked.Unix(), 10)})
+	instances := []*unstructured.Unstructured{newTestInstance("llama-1"), victim}
+
+	c := newTestController(&fakeQuerier{}, instances[0], victim)
+	c.drainDelay = time.Minute
+	policy := autoscalerPolicy{Namespace: "default", Name: "llama", DrainGraceSeconds: 30}
+	exists := func() bool {
+		_, err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace("default").Get(ctx, victim.GetName(), metav1.GetOptions{})
+		return err == nil
+	}
+
+	for _, elapsed := range []time.Duration{0, 30 * time.Second, 89 * time.Second} {
+		active, draining := c.advanceDrains(ctx, policy, instances, marked.Add(elapsed))
+		if len(active) != 1 || active[0].GetName() != "llama-1" || draining != 1 {
+			t.Fatalf("after %s: active %d, draining %d, want 1 and 1", elapsed, len(active), draining)
+		}
+		if !exists() {
+			t.Fatalf("victim deleted %s into a 90s drain", elapsed)
+		}
+	}
+
+	if _, draining := c.advanceDrains(ctx, policy, instances, marked.Add(90*time.Second)); draining != 0 {
+		t.Errorf("draining = %d after the window, want 0", draining)
+	}
+	if exists() {
+		t.Error("victim not deleted once the drain window passed")
+	}
+}
+
+func TestReconcileKeepsDrainingVictim(t *testing.T) {
+	ctx := context.Background()
+	autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+	})
+	victim := newTestInstance("llama-2")
+	victim.SetAnnotations(map[string]string{annotationDrainingSince: strconv.FormatInt(time.Now().Unix(), 10)})
+	c := newTestController(&fakeQuerier{values: map[string][]float64{"queue": {50}}}, autoscaler, newTestInstance("llama-1"), victim)
+	c.drainDelay = time.Minute
+
+	start := time.Now()
+	if err := c.reconcileAutoscaler(ctx, autoscaler); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("reconcile blocked for %s waiting on the drain", elapsed)
+	}
+	if _, err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace("default").Get(ctx, victim.GetName(), metav1.GetOptions{}); err != nil {
+		t.Errorf("draining victim deleted before its drain window: %v", err)
+	}
+}
+
+// newTestSecret returns a Secret in namespace default holding data.
+func newTestSecret(name string, data map[string]string) *unstructured.Unstructured {
+	encoded := map[string]interface{}{}
+	for k, v := range data {
+		encoded[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data":       encoded,
+	}}
+	secret.SetNamespace("default")
+	secret.SetName(name)
+	return secret
+}
+
+func TestPrometheusAuthOverTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, basic := r.BasicAuth()
+		if r.Header.Get("Authorization") != "Bearer s3cret" && !(basic && user == "prom" && password == "pw") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [0, "42"]}]}}`)
+	}))
+	defer server.Close()
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	secrets := []runtime.Object{
+		newTestSecret("prom-token", map[string]string{"token": "s3cret\n"}),
+		newTestSecret("prom-basic", map[string]string{"username": "prom", "password": "pw"}),
+		newTestSecret("prom-wrong-token", map[string]string{"token": "wrong"}),
+		newTestSecret("prom-ca", map[string]string{"ca.crt": caPEM}),
+	}
+	token := secretKeyRef{Name: "prom-token", Key: "token"}
+	ca := secretKeyRef{Name: "prom-ca", Key: "ca.crt"}
+
+	for name, tc := range map[string]struct {
+		auth    prometheusAuth
+		wantErr string
+	}{
+		"bearer token and CA":        {auth: prometheusAuth{BearerTokenSecret: token, CASecret: ca}},
+		"basic auth and CA":          {auth: prometheusAuth{BasicAuthSecret: "prom-basic", CASecret: ca}},
+		"insecure skip verify":       {auth: prometheusAuth{BearerTokenSecret: token, InsecureSkipVerify: true}},
+		"unknown certificate":        {auth: prometheusAuth{BearerTokenSecret: token}, wantErr: "certificate"},
+		"wrong token":                {auth: prometheusAuth{BearerTokenSecret: secretKeyRef{Name: "prom-wrong-token", Key: "token"}, CASecret: ca}, wantErr: "status 401"},
+		"missing credentials secret": {auth: prometheusAuth{BasicAuthSecret: "absent", CASecret: ca}, wantErr: "secret absent"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := newTestController(&fakeQuerier{err: errors.New("default querier used")}, secrets...)
+			policy := autoscalerPolicy{Namespace: "default", Name: "llama", PrometheusAddress: server.URL, PrometheusAuth: tc.auth}
+
+			values, err := func() ([]float64, error) {
+				querier, address, err := c.querierFor(context.Background(), policy)
+				if err != nil {
+					return nil, err
+				}
+				return querier.Query(context.Background(), address, "up")
+			}()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want it to mention %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(values) != 1 || values[0] != 42 {
+				t.Errorf("values = %v, want [42]", values)
+			}
+		})
+	}
+}
+
+func TestAPIServerProxyURL(t *testing.T) {
+	for _, tc := range []struct {
+		address string
+		want    string
+		wantErr bool
+	}{
+		{address: "http://prometheus.monitoring:9090", want: "https://apiserver/api/v1/namespaces/monitoring/services/http:prometheus:9090/proxy"},
+		{address: "http://prometheus.monitoring.svc.cluster.local:9090/", want: "https://apiserver/api/v1/namespaces/monitoring/services/http:prometheus:9090/proxy"},
+		{address: "http://prometheus", want: "https://apiserver/api/v1/namespaces/team-a/services/http:prometheus:80/proxy"},
+		{address: "https://thanos-query.monitoring", want: "https://apiserver/api/v1/namespaces/monitoring/services/https:thanos-query:443/proxy"},
+		{address: "http://prometheus.monitoring:9090/prom", want: "https://apiserver/api/v1/namespaces/monitoring/services/http:prometheus:9090/proxy/prom"},
+		{address: "/api/v1", wantErr: true},
+	} {
+		got, err := apiServerProxyURL("https://apiserver/", tc.address, "team-a")
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: got %q, want an error", tc.address, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%s: got %q, %v; want %q", tc.address, got, err, tc.want)
+		}
+	}
+}
+
+func TestQueryViaAPIServerProxy(t *testing.T) {
+	apiserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/monitoring/services/http:prometheus:9090/proxy/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer sa-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [0, "7"]}]}}`)
+	}))
+	defer apiserver.Close()
+
+	c, err := newController(nil, &rest.Config{Host: apiserver.URL, BearerToken: "sa-token"}, 0, 5*time.Second, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.querier = &fakeQuerier{err: errors.New("direct querier used")}
+	policy := autoscalerPolicy{Namespace: "default", PrometheusAddress: "http://prometheus.monitoring:9090", ViaAPIServerProxy: true}
+
+	querier, address, err := c.querierFor(context.Background(), policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := querier.Query(context.Background(), address, "up")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0] != 7 {
+		t.Errorf("values = %v, want [7]", values)
+	}
+}
+
+func TestQueryOverUnixSocket(t *testing.T) {
+	// Kept short: unix socket paths are limited to ~100 bytes.
+	dir, err := os.MkdirTemp("", "prom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "prom.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" || r.URL.Query().Get("query") != "up" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [0, "3"]}]}}`)
+	}))
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	c := newTestController(&fakeQuerier{err: errors.New("TCP querier used")})
+	autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+		"prometheus": map[string]interface{}{"address": "unix://" + socket},
+		"metrics":    []interface{}{testMetric("QueueLength", "up", 100, 1)},
+	})
+	policy, err := parsePolicy(autoscaler, builtinDefaults())
+	if err != nil {
+		t.Fatalf("parsePolicy: %v", err)
+	}
+	decision, err := c.evaluateDecision(context.Background(), policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Observed["QueueLength"] != 3 {
+		t.Errorf("observed = %v, want QueueLength 3 read over the socket", decision.Observed)
+	}
+
+	for name, prometheus := range map[string]map[string]interface{}{
+		"proxy":       {"address": "unix://" + socket, "viaAPIServerProxy": true},
+		"credentials": {"address": "unix://" + socket, "bearerTokenSecret": map[string]interface{}{"name": "prom-token", "key": "token"}},
+		"no path":     {"address": "unix://"},
+	} {
+		if _, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{"prometheus": prometheus}), builtinDefaults()); err == nil {
+			t.Errorf("%s: parsePolicy accepted a unix address it can't serve", name)
+		}
+	}
+}
+
+func TestAuthQuerierReusedUntilSecretChanges(t *testing.T) {
+	ctx := context.Background()
+	c := newTestController(&fakeQuerier{}, newTestSecret("prom-token", map[string]string{"token": "one"}))
+	policy := autoscalerPolicy{Namespace: "default", Name: "llama", PrometheusAuth: prometheusAuth{BearerTokenSecret: secretKeyRef{Name: "prom-token", Key: "token"}}}
+
+	first, err := c.authQuerier(ctx, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := c.authQuerier(ctx, policy); again != first {
+		t.Error("querier rebuilt although the Secret did not change")
+	}
+
+	rotated := newTestSecret("prom-token", map[string]string{"token": "two"})
+	if _, err := c.dynamicClient.Resource(c.secretGVR).Namespace("default").Update(ctx, rotated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	next, err := c.authQuerier(ctx, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == first || next.bearerToken != "two" {
+		t.Errorf("rotated token not picked up: bearerToken %q", next.bearerToken)
+	}
+}
+
+func TestRenderQuery(t *testing.T) {
+	data := queryTemplateData{AppLabel: "llama", Namespace: "serving", Instance: "llama-2", Window: "5m"}
+	for text, want := range map[string]string{
+		`sum(up{app="{{.AppLabel}}",namespace="{{.Namespace}}"})`:            `sum(up{app="llama",namespace="serving"})`,
+		`sum(rate(my_requests_total{pod=~"{{.Instance}}-.*"}[{{.Window}}]))`: `sum(rate(my_requests_total{pod=~"llama-2-.*"}[5m]))`,
+		`sum(redis_queue_length{queue="request_queue"})`:                     `sum(redis_queue_length{queue="request_queue"})`,
+	} {
+		got, err := renderQuery(text, data)
+		if err != nil {
+			t.Errorf("renderQuery(%q): %v", text, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("renderQuery(%q) = %q, want %q", text, got, want)
+		}
+	}
+
+	for metricType, text := range defaultQueryTemplates {
+		got, err := renderQuery(text, data)
+		if err != nil {
+			t.Errorf("default %s query: %v", metricType, err)
+			continue
+		}
+		if strings.Contains(got, "{{") || (metricType != "GPUUtilization" && !strings.Contains(got, `app="llama"`)) {
+			t.Errorf("default %s query rendered to %q", metricType, got)
+		}
+	}
+}
+
+func TestParsePolicyRejectsMalformedQueryTemplate(t *testing.T) {
+	for query, want := range map[string]string{
+		`sum(up{app="{{.AppLabel}"})`:           "bad character",
+		`sum(up{app="{{.Cluster}}"})`:           "Cluster",
+		`sum(up{app="{{if .ReadyOnly}}llama"})`: "unexpected EOF",
+	} {
+		autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+			"metrics": []interface{}{testMetric("QueueLength", query, 100, 20)},
+		})
+		_, err := parsePolicy(autoscaler, builtinDefaults())
+		if err == nil || !strings.Contains(err.Error(), "QueueLength query template") || !strings.Contains(err.Error(), want) {
+			t.Errorf("parsePolicy with query %q: err = %v, want a QueueLength template error mentioning %q", query, err, want)
+		}
+	}
+}
+
+func TestDecisionModesOnSameObservations(t *testing.T) {
+	weighted := func(metricType, query string, scaleUp, scaleDown, weight float64) map[string]interface{} {
+		m := testMetric(metricType, query, scaleUp, scaleDown)
+		m["weight"] = weight
+		return m
+	}
+	// The queue is 30% over its scale-up threshold while TTFT sits at 70% of
+	// its own; for scale-down the queue is at half its threshold and TTFT
+	// 20% over.
+	hot := map[string][]float64{"queue": {130}, "ttft": {1400}}
+	cool := map[string][]float64{"queue": {10}, "ttft": {600}}
+
+	tests := []struct {
+		name      string
+		mode      string
+		weights   [2]float64
+		values    map[string][]float64
+		scaleUp   bool
+		scaleDown bool
+	}{
+		{name: "any scales up", mode: decisionModeAny, values: hot, scaleUp: true},
+		{name: "all holds", mode: decisionModeAll, values: hot},
+		{name: "weighted equal holds at 1.0", mode: decisionModeWeighted, values: hot},
+		{name: "weighted toward queue scales up", mode: decisionModeWeighted, weights: [2]float64{0.6, 0.4}, values: hot, scaleUp: true},
+		{name: "weighted toward TTFT holds", mode: decisionModeWeighted, weights: [2]float64{0.2, 0.8}, values: hot},
+		{name: "any keeps one metric above scale-down", mode: decisionModeAny, values: cool},
+		{name: "all keeps one metric above scale-down", mode: decisionModeAll, values: cool},
+		{name: "weighted toward queue scales down", mode: decisionModeWeighted, weights: [2]float64{0.8, 0.2}, values: cool, scaleDown: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := []interface{}{testMetric("QueueLength", "queue", 100, 20), testMetric("TTFT", "ttft", 2000, 500)}
+			if tt.weights[0] > 0 {
+				metrics = []interface{}{
+					weighted("QueueLength", "queue", 100, 20, tt.weights[0]),
+					weighted("TTFT", "ttft", 2000, 500, tt.weights[1]),
+				}
+			}
+			spec := map[string]interface{}{
+				"metrics":  metrics,
+				"behavior": map[string]interface{}{"decisionMode": tt.mode},
+			}
+			policy, err := parsePolicy(newTestAutoscaler("llama", spec), builtinDefaults())
+			if err != nil {
+				t.Fatalf("parsePolicy: %v", err)
+			}
+
+			c := newTestController(&fakeQuerier{values: tt.values})
+			decision, err := c.evaluateDecision(context.Background(), policy)
+			if err != nil {
+				t.Fatalf("evaluateDecision: %v", err)
+			}
+			if decision.ScaleUp != tt.scaleUp || decision.ScaleDown != tt.scaleDown {
+				t.Errorf("got scaleUp=%v scaleDown=%v (%s), want %v %v",
+					decision.ScaleUp, decision.ScaleDown, decision.Reason, tt.scaleUp, tt.scaleDown)
+			}
+		})
+	}
+}
+
+func TestParsePolicyDecisionMode(t *testing.T) {
+	metrics := []interface{}{testMetric("QueueLength", "queue", 100, 20)}
+	policy, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{"metrics": metrics}), builtinDefaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.DecisionMode != decisionModeAny {
+		t.Errorf("default decisionMode = %q, want %q", policy.DecisionMode, decisionModeAny)
+	}
+
+	spec := map[string]interface{}{
+		"metrics":  metrics,
+		"behavior": map[string]interface{}{"decisionMode": "majority"},
+	}
+	if _, err := parsePolicy(newTestAutoscaler("llama", spec), builtinDefaults()); err == nil {
+		t.Error("unknown decisionMode accepted")
+	}
+
+	metric := testMetric("QueueLength", "queue", 100, 20)
+	metric["weight"] = float64(0)
+	spec = map[string]interface{}{"metrics": []interface{}{metric}}
+	if _, err := parsePolicy(newTestAutoscaler("llama", spec), builtinDefaults()); err == nil || !strings.Contains(err.Error(), "weight") {
+		t.Errorf("zero weight: err = %v, want a weight error", err)
+	}
+}
+
+func TestReadyOnlyExcludesLoadingPods(t *testing.T) {
+	const (
+		readyQuery = `histogram_quantile(0.95, sum(rate(llm_ttft_seconds_bucket{app="llama",serving_ai_loading=""}[2m])) by (le)) * 1000`
+		allQuery   = `histogram_quantile(0.95, sum(rate(llm_ttft_seconds_bucket{app="llama"}[2m])) by (le)) * 1000`
+	)
+	// Loading pods serve nothing yet and pull the fleet-wide p95 down
+	values := map[string][]float64{readyQuery: {2500}, allQuery: {1200}}
+
+	for _, readyOnly := range []bool{true, false} {
+		ttft := map[string]interface{}{"type": "TTFT", "threshold": map[string]interface{}{"scaleUp": float64(2000), "scaleDown": float64(500)}}
+		policy, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{
+			"scaleTargetRef": map[string]interface{}{"appLabel": "llama", "readyOnly": readyOnly},
+			"metrics":        []interface{}{ttft},
+		}), builtinDefaults())
+		if err != nil {
+			t.Fatalf("parsePolicy: %v", err)
+		}
+		querier := &fakeQuerier{values: values}
+		decision, err := newTestController(querier).evaluateDecision(context.Background(), policy)
+		if err != nil {
+			t.Fatalf("evaluateDecision: %v", err)
+		}
+
+		want, wantValue := allQuery, 1200.0
+		if readyOnly {
+			want, wantValue = readyQuery, 2500
+		}
+		if len(querier.queries) != 1 || querier.queries[0] != want {
+			t.Errorf("readyOnly=%v queried %q, want %q", readyOnly, querier.queries, want)
+		}
+		if decision.Observed["TTFT"] != wantValue || decision.ScaleUp != readyOnly {
+			t.Errorf("readyOnly=%v: observed %v, scaleUp %v; want %v, %v", readyOnly, decision.Observed["TTFT"], decision.ScaleUp, wantValue, readyOnly)
+		}
+	}
+}
+
+func TestUnchangedStatusNotRewritten(t *testing.T) {
+	ctx := context.Background()
+	autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+	})
+	c := newTestController(&fakeQuerier{values: map[string][]float64{"queue": {50}}}, autoscaler, newTestInstance("llama-a"))
+	fake := c.dynamicClient.(*dynamicfake.FakeDynamicClient)
+	statusWrites := func() int {
+		writes := 0
+		for _, action := range fake.Actions() {
+			if action.GetVerb() == "update" && action.GetSubresource() == "status" && action.GetResource() == c.autoscalerGVR {
+				writes++
+			}
+		}
+		fake.ClearActions()
+		return writes
+	}
+
+	c.reconcileAll(ctx)
+	if writes := statusWrites(); writes != 1 {
+		t.Fatalf("first cycle wrote status %d times, want 1", writes)
+	}
+	c.reconcileAll(ctx)
+	if writes := statusWrites(); writes != 0 {
+		t.Errorf("unchanged cycle wrote status %d times, want 0", writes)
+	}
+
+	// Deleted and recreated under the same name: the new object's empty
+	// status must be filled in
+	autoscalers := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default")
+	if err := autoscalers.Delete(ctx, "llama", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	c.reconcileAll(ctx)
+	if _, ok := c.lastReconcile["default/llama"]; ok {
+		t.Error("snapshot of the deleted autoscaler kept")
+	}
+	if _, err := autoscalers.Create(ctx, newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+	}), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	statusWrites()
+	c.reconcileAll(ctx)
+	if writes := statusWrites(); writes != 1 {
+		t.Errorf("recreated autoscaler got %d status writes, want 1", writes)
+	}
+	obj, err := autoscalers.Get(ctx, "llama", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current, _, _ := unstructured.NestedInt64(obj.Object, "status", "currentInstances"); current != 1 {
+		t.Errorf("recreated autoscaler status.currentInstances = %d, want 1", current)
+	}
+}
+
+func TestQuerySpanEndsOnEveryPath(t *testing.T) {
+	for name, querier := range map[string]*fakeQuerier{
+		"ok":       {values: map[string][]float64{"queue": {50}}},
+		"no data":  {values: map[string][]float64{}},
+		"failed":   {err: errors.New("connection refused")},
+		"template": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			query := "queue"
+			if name == "template" {
+				query = "{{.Missing"
+			}
+			policy, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{
+				"metrics": []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+			}), builtinDefaults())
+			if err != nil {
+				t.Fatal(err)
+			}
+			policy.Metrics[0].Query = query
+			_, _ = newTestController(querier).evaluateDecision(context.Background(), policy)
+
+			ended := 0
+			for _, span := range recorder.Ended() {
+				if span.Name() == "query" {
+					ended++
+				}
+			}
+			if started := len(recorder.Started()); ended != 1 {
+				t.Errorf("%d query spans ended of %d spans started, want 1", ended, started)
+			}
+		})
+	}
+}
+
+func TestStabilizeSuppressesFlapping(t *testing.T) {
+	up := scaleDecision{ScaleUp: true, MetricsAvailable: true, Trigger: "TTFT 2400.00 > 2000.00"}
+	down := scaleDecision{ScaleDown: true, MetricsAvailable: true}
+	hold := scaleDecision{MetricsAvailable: true}
+	missing := scaleDecision{}
+	panicUp := up
+	panicUp.Panic = true
+
+	tests := []struct {
+		name      string
+		decisions []scaleDecision
+		// wantUp/wantDown are the stabilized results, one per decision
+		wantUp   []bool
+		wantDown []bool
+	}{
+		{
+			name:      "sustained breach scales on the third sample",
+			decisions: []scaleDecision{up, up, up, up},
+			wantUp:    []bool{false, false, true, true},
+			wantDown:  []bool{false, false, false, false},
+		},
+		{
+			name:      "bursty TTFT never holds for the window",
+			decisions: []scaleDecision{up, hold, up, up, hold, up},
+			wantUp:    []bool{false, false, false, false, false, false},
+			wantDown:  []bool{false, false, false, false, false, false},
+		},
+		{
+			name:      "alternating up and down flaps neither way",
+			decisions: []scaleDecision{up, down, up, down, up, down},
+			wantUp:    []bool{false, false, false, false, false, false},
+			wantDown:  []bool{false, false, false, false, false, false},
+		},
+		{
+			name:      "missing metrics restart the run",
+			decisions: []scaleDecision{down, missing, down, down},
+			wantUp:    []bool{false, false, false, false},
+			wantDown:  []bool{false, false, false, true},
+		},
+		{
+			name:      "panic skips the window",
+			decisions: []scaleDecision{hold, panicUp},
+			wantUp:    []bool{false, true},
+			wantDown:  []bool{false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(&fakeQuerier{})
+			c.syncInterval = 30 * time.Second
+			// 90s = 3 samples up, 60s = 2 samples down
+			policy := autoscalerPolicy{Namespace: "default", Name: "llama", ScaleUpWindowSeconds: 90, ScaleDownWindowSeconds: 60}
+			for i, decision := range tt.decisions {
+				got := c.stabilize(policy, decision)
+				if got.ScaleUp != tt.wantUp[i] || got.ScaleDown != tt.wantDown[i] {
+					t.Errorf("sample %d: scaleUp=%v scaleDown=%v (%s), want %v %v",
+						i, got.ScaleUp, got.ScaleDown, got.Reason, tt.wantUp[i], tt.wantDown[i])
+				}
+			}
+			if n := len(c.recentDecisions["default/llama"]); n > 3 {
+				t.Errorf("%d decisions kept, want at most the 3-sample window", n)
+			}
+		})
+	}
+
+	// Without a window every sample acts
+	c := newTestController(&fakeQuerier{})
+	c.syncInterval = 30 * time.Second
+	if got := c.stabilize(autoscalerPolicy{Name: "llama"}, up); !got.ScaleUp {
+		t.Error("no window: a single breaching sample did not scale up")
+	}
+}
+
+func TestScaleUpStepForOverload(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxStep    int
+		maxPercent int
+		overload   float64
+		current    int
+		want       int
+	}{
+		{name: "default step of one", maxStep: 1, overload: 10, current: 2, want: 1},
+		{name: "10x overload sized to the load", maxStep: 50, overload: 10, current: 2, want: 18},
+		{name: "10x overload capped by maxStep", maxStep: 4, overload: 10, current: 2, want: 4},
+		{name: "slight overload adds one", maxStep: 4, overload: 1.05, current: 2, want: 1},
+		{name: "percent cap above maxStep", maxStep: 2, maxPercent: 50, overload: 10, current: 10, want: 5},
+		{name: "zero threshold takes the whole limit", maxStep: 3, overload: math.Inf(1), current: 2, want: 3},
+		{name: "from zero instances", maxStep: 3, overload: 10, current: 0, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := autoscalerPolicy{ScaleUpMaxStep: tt.maxStep, ScaleUpMaxPercent: tt.maxPercent}
+			if got := scaleUpStep(policy, scaleDecision{ScaleUp: true, Overload: tt.overload}, tt.current); got != tt.want {
+				t.Errorf("scaleUpStep = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileScalesUpSeveralForOverload(t *testing.T) {
+	ctx := context.Background()
+	autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+		"metrics":  []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+		"behavior": map[string]interface{}{"scaleUpMaxStep": int64(10)},
+	})
+	// 10x the threshold on 2 instances calls for 20, capped at maxInstances 5
+	c := newTestController(&fakeQuerier{values: map[string][]float64{"queue": {1000}}},
+		autoscaler, newTestInstance("llama-a"), newTestInstance("llama-b"))
+	c.reconcileAll(ctx)
+
+	instances, err := c.listManagedInstances(ctx, "default", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 5 {
+		t.Errorf("%d instances after a 10x overload, want maxInstances 5", len(instances))
+	}
+	obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	action, _, _ := unstructured.NestedString(obj.Object, "status", "lastScaleAction")
+	desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredInstances")
+	if action != "ScaleUp" || desired != 5 {
+		t.Errorf("status lastScaleAction=%q desiredInstances=%d, want ScaleUp to 5", action, desired)
+	}
+}
+
+// newTestRouter returns the router LLMCluster "llama-router" with a custom
+// image and one stale backend.
+func newTestRouter() *unstructured.Unstructured {
+	router := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "serving.ai/v1alpha1",
+		"kind":       "LLMCluster",
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"router": map[string]interface{}{
+				"image":    "example.com/router:v1",
+				"backends": []interface{}{map[string]interface{}{"name": "old", "service": "llama-old", "port": int64(8000)}},
+			},
+		},
+	}}
+	router.SetNamespace("default")
+	router.SetName("llama-router")
+	return router
+}
+
+// routerBackends returns the backend names of "llama-router", with
+// draining ones suffixed "(draining)".
+func routerBackends(t *testing.T, c *controller) []string {
+	t.Helper()
+	router, err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace("default").Get(context.Background(), "llama-router", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backends, _, _ := unstructured.NestedSlice(router.Object, "spec", "router", "backends")
+	names := make([]string, 0, len(backends))
+	for _, b := range backends {
+		backend := b.(map[string]interface{})
+		name := backend["name"].(string)
+		if draining, _ := backend["draining"].(bool); draining {
+			name += "(draining)"
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestRouterBackendsMergePatch(t *testing.T) {
+	ctx := context.Background()
+	policy := autoscalerPolicy{Namespace: "default", RouterName: "llama-router", RouterBackendPort: 8000}
+	c := newTestController(&fakeQuerier{}, newTestRouter())
+	fake := c.dynamicClient.(*dynamicfake.FakeDynamicClient)
+
+	// Someone edits the router between the autoscaler's list and its
+	// write; a full update from the listed copy would revert the edit or
+	// fail with a conflict
+	edited := false
+	fake.PrependReactor("patch", c.llmclusterGVR.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if edited {
+			return false, nil, nil
+		}
+		edited = true
+		obj, err := fake.Tracker().Get(c.llmclusterGVR, "default", "llama-router")
+		if err != nil {
+			return true, nil, err
+		}
+		router := obj.(*unstructured.Unstructured).DeepCopy()
+		_ = unstructured.SetNestedField(router.Object, "example.com/router:v2", "spec", "router", "image")
+		router.SetResourceVersion("2")
+		return false, nil, fake.Tracker().Update(c.llmclusterGVR, router, "default")
+	})
+
+	if err := c.reconcileRouterBackends(ctx, policy, []*unstructured.Unstructured{newTestInstance("llama-a")}, time.Now()); err != nil {
+		t.Fatalf("reconcileRouterBackends: %v", err)
+	}
+
+	for _, action := range fake.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("router written with an update: %v", action)
+		}
+		if patch, ok := action.(k8stesting.PatchAction); ok {
+			if patch.GetPatchType() != types.MergePatchType || strings.Contains(string(patch.GetPatch()), "resourceVersion") {
+				t.Errorf("router patch %s %s, want a merge patch without resourceVersion", patch.GetPatchType(), patch.GetPatch())
+			}
+		}
+	}
+	router, err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace("default").Get(ctx, "llama-router", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	image, _, _ := unstructured.NestedString(router.Object, "spec", "router", "image")
+	replicas, _, _ := unstructured.NestedInt64(router.Object, "spec", "replicas")
+	if image != "example.com/router:v2" || replicas != 2 {
+		t.Errorf("router image %q replicas %d, want the concurrent edit and replicas kept", image, replicas)
+	}
+	if got := routerBackends(t, c); strings.Join(got, ",") != "llama-a" {
+		t.Errorf("backends = %v, want the stale list replaced by llama-a", got)
+	}
+}
+
+func TestRouterBackendsOnlyRunning(t *testing.T) {
+	policy := autoscalerPolicy{Namespace: "default", RouterName: "llama-router", RouterBackendPort: 8000}
+	withPhase := func(name, phase string) *unstructured.Unstructured {
+		instance := newTestInstance(name)
+		_ = unstructured.SetNestedField(instance.Object, phase, "status", "phase")
+		return instance
+	}
+
+	tests := []struct {
+		name      string
+		instances []*unstructured.Unstructured
+		want      string
+	}{
+		{
+			name:      "not yet Running left out",
+			instances: []*unstructured.Unstructured{withPhase("llama-a", "Running"), withPhase("llama-b", "Creating"), withPhase("llama-c", "Progressing"), withPhase("llama-d", "Degraded")},
+			want:      "llama-a",
+		},
+		{
+			name:      "added back once Running",
+			instances: []*unstructured.Unstructured{withPhase("llama-a", "Running"), withPhase("llama-b", "Running")},
+			want:      "llama-a,llama-b",
+		},
+		{
+			name:      "none Running keeps every instance",
+			instances: []*unstructured.Unstructured{withPhase("llama-a", "Creating"), withPhase("llama-b", "Degraded")},
+			want:      "llama-a,llama-b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(&fakeQuerier{}, newTestRouter())
+			if err := c.reconcileRouterBackends(context.Background(), policy, tt.instances, time.Now()); err != nil {
+				t.Fatalf("reconcileRouterBackends: %v", err)
+			}
+			if got := strings.Join(routerBackends(t, c), ","); got != tt.want {
+				t.Errorf("backends = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// A name prefix is stripped from the backend name, not the service
+	c := newTestController(&fakeQuerier{}, newTestRouter())
+	policy.RouterBackendNamePrefix = "llama-"
+	if err := c.reconcileRouterBackends(context.Background(), policy, []*unstructured.Unstructured{withPhase("llama-a", "Running")}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	router, _ := c.dynamicClient.Resource(c.llmclusterGVR).Namespace("default").Get(context.Background(), "llama-router", metav1.GetOptions{})
+	backends, _, _ := unstructured.NestedSlice(router.Object, "spec", "router", "backends")
+	if backend := backends[0].(map[string]interface{}); backend["name"] != "a" || backend["service"] != "llama-a" || backend["port"] != int64(8000) {
+		t.Errorf("backend = %v, want name a for service llama-a:8000", backend)
+	}
+}
+
+func TestDrainingBackendKeptUntilGraceExpires(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	policy := autoscalerPolicy{Namespace: "default", RouterName: "llama-router", RouterBackendPort: 8000, DrainGraceSeconds: 60}
+	victim := newTestInstance("llama-b")
+	victim.SetAnnotations(map[string]string{annotationDrainingSince: strconv.FormatInt(now.Unix(), 10)})
+	instances := []*unstructured.Unstructured{newTestInstance("llama-a"), victim}
+
+	for _, tt := range []struct {
+		after time.Duration
+		want  string
+	}{
+		{after: 0, want: "llama-a,llama-b(draining)"},
+		{after: 59 * time.Second, want: "llama-a,llama-b(draining)"},
+		{after: 60 * time.Second, want: "llama-a"},
+	} {
+		c := newTestController(&fakeQuerier{}, newTestRouter())
+		if err := c.reconcileRouterBackends(context.Background(), policy, instances, now.Add(tt.after)); err != nil {
+			t.Fatalf("reconcileRouterBackends: %v", err)
+		}
+		if got := strings.Join(routerBackends(t, c), ","); got != tt.want {
+			t.Errorf("%s into the drain: backends = %s, want %s", tt.after, got, tt.want)
+		}
+	}
+
+	// No grace period: dropped from the router right away
+	policy.DrainGraceSeconds = 0
+	c := newTestController(&fakeQuerier{}, newTestRouter())
+	if err := c.reconcileRouterBackends(context.Background(), policy, instances, now); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(routerBackends(t, c), ","); got != "llama-a" {
+		t.Errorf("without a grace period: backends = %s, want llama-a", got)
+	}
+}
+
+func TestCycleQueryCacheSharesIdenticalQueries(t *testing.T) {
+	ctx := context.Background()
+	queue := testMetric("QueueLength", "queue", 100, 20)
+	other := testMetric("QueueLength", "other-queue", 100, 20)
+	querier := &fakeQuerier{values: map[string][]float64{"queue": {50}, "other-queue": {50}}}
+	c := newTestController(querier,
+		newTestAutoscaler("llama-a", map[string]interface{}{"metrics": []interface{}{queue}}),
+		newTestAutoscaler("llama-b", map[string]interface{}{"metrics": []interface{}{queue}}),
+		newTestAutoscaler("llama-c", map[string]interface{}{"metrics": []interface{}{other}}))
+	c.shareQueries = true
+
+	c.reconcileAll(ctx)
+	if got := strings.Join(querier.queries, ","); got != "queue,other-queue" {
+		t.Errorf("first cycle sent %s, want each distinct query once", got)
+	}
+	if c.queryCache.hits != 1 {
+		t.Errorf("cache hits = %d, want 1", c.queryCache.hits)
+	}
+
+	// The next cycle starts empty and queries again
+	querier.queries = nil
+	querier.values["queue"] = []float64{60}
+	c.reconcileAll(ctx)
+	if got := strings.Join(querier.queries, ","); got != "queue,other-queue" {
+		t.Errorf("second cycle sent %s, want both queries again", got)
+	}
+	obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama-b", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if observed, _, _ := unstructured.NestedFloat64(obj.Object, "status", "observedMetrics", "QueueLength"); observed != 60 {
+		t.Errorf("llama-b observed %v, want this cycle's 60", observed)
+	}
+
+	// Errors are shared too, not retried within the cycle
+	querier.queries = nil
+	querier.err = errors.New("connection refused")
+	c.reconcileAll(ctx)
+	if len(querier.queries) != 2 {
+		t.Errorf("failing cycle sent %d queries, want 2", len(querier.queries))
+	}
+
+	// Without sharing every autoscaler queries for itself
+	querier.queries, querier.err = nil, nil
+	c = newTestController(querier,
+		newTestAutoscaler("llama-a", map[string]interface{}{"metrics": []interface{}{queue}}),
+		newTestAutoscaler("llama-b", map[string]interface{}{"metrics": []interface{}{queue}}),
+		newTestAutoscaler("llama-c", map[string]interface{}{"metrics": []interface{}{other}}))
+	c.reconcileAll(ctx)
+	if len(querier.queries) != 3 {
+		t.Errorf("unshared cycle sent %d queries, want 3", len(querier.queries))
+	}
+}
+
+func TestScaleUpClampedAtNamespaceCap(t *testing.T) {
+	ctx := context.Background()
+	managed := func(name, app, autoscaler string) *unstructured.Unstructured {
+		instance := newTestInstance(name)
+		instance.SetLabels(map[string]string{"app": app, "serving.ai/role": "instance", labelManagedBy: autoscaler})
+		return instance
+	}
+	overloaded := func() *unstructured.Unstructured {
+		return newTestAutoscaler("llama", map[string]interface{}{
+			"metrics":  []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+			"behavior": map[string]interface{}{"scaleUpMaxStep": int64(10)},
+		})
+	}
+	status := func(c *controller) (string, string) {
+		obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		action, _, _ := unstructured.NestedString(obj.Object, "status", "lastScaleAction")
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, condition := range conditions {
+			if condition := condition.(map[string]interface{}); condition["type"] == "Ready" {
+				return action, condition["message"].(string)
+			}
+		}
+		return action, ""
+	}
+	querier := &fakeQuerier{values: map[string][]float64{"queue": {1000}}}
+
+	// 3 of the namespace's 4 used, one by another autoscaler: 1 more fits
+	// although the overload calls for maxInstances
+	c := newTestController(querier, overloaded(),
+		managed("llama-a", "llama", "llama"), managed("llama-b", "llama", "llama"), managed("chat-a", "chat", "chat"))
+	c.defaults.NamespaceMaxInstances = 4
+	c.reconcileAll(ctx)
+	total, err := c.countNamespaceInstances(ctx, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 {
+		t.Errorf("%d managed instances in the namespace, want the cap of 4", total)
+	}
+	if action, reason := status(c); action != "ScaleUp" || !strings.Contains(reason, "llama-01") || strings.Contains(reason, "llama-02") {
+		t.Errorf("status %s: %s, want one instance created", action, reason)
+	}
+
+	// At the cap nothing is created and the block says why
+	c = newTestController(querier, overloaded(),
+		managed("llama-a", "llama", "llama"), managed("llama-b", "llama", "llama"), managed("chat-a", "chat", "chat"), managed("chat-b", "chat", "chat"))
+	c.defaults.NamespaceMaxInstances = 4
+	c.reconcileAll(ctx)
+	if action, reason := status(c); action != "Blocked" || !strings.HasPrefix(reason, "NamespaceCapReached: 4 autoscaler-managed instances in default (cap 4)") {
+		t.Errorf("status %s: %s, want Blocked by the namespace cap", action, reason)
+	}
+	if total, _ := c.countNamespaceInstances(ctx, "default"); total != 4 {
+		t.Errorf("%d managed instances at the cap, want 4", total)
+	}
+}
+
+func TestGradualScaleDownStepsAndHalts(t *testing.T) {
+	ctx := context.Background()
+	autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+		"metrics":  []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+		"behavior": map[string]interface{}{"scaleDownMode": "gradual"},
+	})
+	querier := &fakeQuerier{values: map[string][]float64{"queue": {5}}}
+	c := newTestController(querier, autoscaler, newTestInstance("llama-1"), newTestInstance("llama-2"),
+		newTestInstance("llama-3"), newTestInstance("llama-4"))
+	c.drainDelay = time.Hour
+
+	instances := c.dynamicClient.Resource(c.llmclusterGVR).Namespace("default")
+	reconcile := func() (step string, draining []string) {
+		t.Helper()
+		current, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.reconcileAutoscaler(ctx, current); err != nil {
+			t.Fatal(err)
+		}
+		current, err = c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		list, err := instances.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range list.Items {
+			if _, ok := item.GetAnnotations()[annotationDrainingSince]; ok {
+				draining = append(draining, item.GetName())
+			}
+		}
+		return current.GetAnnotations()[annotationScaleDownStep], draining
+	}
+	finishDrains := func(names []string) {
+		t.Helper()
+		for _, name := range names {
+			if err := instances.Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	step, draining := reconcile()
+	if step != "3" || len(draining) != 1 {
+		t.Fatalf("first step: target %q, draining %v; want target 3 and one victim", step, draining)
+	}
+
+	// The step hasn't completed: the next interval must not take another.
+	if step, again := reconcile(); step != "3" || len(again) != 1 {
+		t.Fatalf("before the step completed: target %q, draining %v; want no second victim", step, again)
+	}
+
+	// Step verified, so the next one goes ahead inside the scale-down cooldown.
+	finishDrains(draining)
+	step, draining = reconcile()
+	if step != "2" || len(draining) != 1 {
+		t.Fatalf("second step: target %q, draining %v; want target 2 and one victim", step, draining)
+	}
+
+	// Load returns mid-shrink: the sequence stops and nothing more drains.
+	finishDrains(draining)
+	querier.values["queue"] = []float64{50}
+	if step, draining := reconcile(); step != "" || len(draining) != 0 {
+		t.Fatalf("after load returned: target %q, draining %v; want the sequence cleared", step, draining)
+	}
+	querier.values["queue"] = []float64{5}
+	if _, draining := reconcile(); len(draining) != 0 {
+		t.Errorf("draining %v, want a new scale-down to wait for the full cooldown", draining)
+	}
+}
+
+func TestMetricWindowRendered(t *testing.T) {
+	custom := testMetric("Custom", `sum(rate(my_requests_total{app="{{.AppLabel}}"}[{{.Window}}]))`, 100, 10)
+	custom["window"] = "30s"
+	ttft := map[string]interface{}{
+		"type":      "TTFT",
+		"window":    "5m",
+		"threshold": map[string]interface{}{"scaleUp": float64(2000), "scaleDown": float64(500)},
+	}
+	tpot := map[string]interface{}{
+		"type":      "TPOT",
+		"threshold": map[string]interface{}{"scaleUp": float64(100), "scaleDown": float64(20)},
+	}
+	policy, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{custom, ttft, tpot},
+	}), builtinDefaults())
+	if err != nil {
+		t.Fatalf("parsePolicy: %v", err)
+	}
+
+	// One metric per decision: a metric without data ends the evaluation.
+	metrics := policy.Metrics
+	for i, want := range []string{
+		`sum(rate(my_requests_total{app="llama"}[30s]))`,
+		"[5m]",
+		"[" + defaultQueryWindow + "]",
+	} {
+		querier := &fakeQuerier{}
+		policy.Metrics = metrics[i : i+1]
+		if _, err := newTestController(querier).evaluateDecision(context.Background(), policy); err != nil {
+			t.Fatal(err)
+		}
+		if len(querier.queries) != 1 || !strings.Contains(querier.queries[0], want) || strings.Contains(querier.queries[0], "{{") {
+			t.Errorf("%s queries = %q, want one containing %q", metrics[i].Type, querier.queries, want)
+		}
+	}
+
+	if _, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{map[string]interface{}{
+			"type":      "TTFT",
+			"window":    "5 minutes",
+			"threshold": map[string]interface{}{"scaleUp": float64(2000), "scaleDown": float64(500)},
+		}},
+	}), builtinDefaults()); err == nil {
+		t.Error("parsePolicy accepted a window that isn't a PromQL duration")
+	}
+}
+
+func TestReconcileReplicasScaleSubresource(t *testing.T) {
+	ctx := context.Background()
+	for name, tc := range map[string]struct {
+		replicas        int64
+		noSubresource   bool
+		wantReplicas    int64
+		wantSubresource string
+		wantMessage     string
+	}{
+		"via scale subresource":      {replicas: 2, wantReplicas: 3, wantSubresource: "scale", wantMessage: "llama replicas 2 -> 3"},
+		"subresource missing":        {replicas: 2, noSubresource: true, wantReplicas: 3, wantMessage: "llama replicas 2 -> 3"},
+		"capped at maxReplicas":      {replicas: 3, wantReplicas: 3, wantMessage: "conflict: wanted 4 replicas"},
+		"capped without subresource": {replicas: 3, noSubresource: true, wantReplicas: 3, wantMessage: "conflict: wanted 4 replicas"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+				"mode":           "replicas",
+				"scaleTargetRef": map[string]interface{}{"name": "llama"},
+				"metrics":        []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+			})
+			target := newTestInstance("llama")
+			unstructured.SetNestedField(target.Object, tc.replicas, "spec", "replicas")
+			unstructured.SetNestedField(target.Object, int64(3), "spec", "autoscaling", "maxReplicas")
+			c := newTestController(&fakeQuerier{values: map[string][]float64{"queue": {500}}}, autoscaler, target)
+
+			fake := c.dynamicClient.(*dynamicfake.FakeDynamicClient)
+			var updates []string
+			fake.PrependReactor("*", "llmclusters", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "scale" && tc.noSubresource {
+					return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "serving.ai", Resource: "llmclusters"}, "llama")
+				}
+				if action.GetVerb() == "update" {
+					updates = append(updates, action.GetSubresource())
+				}
+				return false, nil, nil
+			})
+
+			if err := c.reconcileAutoscaler(ctx, autoscaler); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if replicas, _, _ := unstructured.NestedInt64(got.Object, "spec", "replicas"); replicas != tc.wantReplicas {
+				t.Errorf("replicas = %d, want %d", replicas, tc.wantReplicas)
+			}
+			if tc.wantReplicas != tc.replicas && (len(updates) != 1 || updates[0] != tc.wantSubresource) {
+				t.Errorf("updates to subresources %q, want one to %q", updates, tc.wantSubresource)
+			}
+			obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+			if len(conditions) == 0 || !strings.Contains(conditions[0].(map[string]interface{})["message"].(string), tc.wantMessage) {
+				t.Errorf("conditions = %v, want the message to contain %q", conditions, tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestOperatorDefaultsFromConfigMap(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := kubefake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "serving-system", Name: "autoscaler-defaults"},
+		Data: map[string]string{
+			"prometheusAddress":             "http://thanos-query.monitoring:9090",
+			"scaleUpStabilizationSeconds":   "30",
+			"scaleDownStabilizationSeconds": "600",
+			"namespaceMaxInstances":         "8",
+			"query.QueueLength":             `sum(vllm:num_requests_waiting{app="{{.AppLabel}}"})`,
+		},
+	})
+	defaults, err := loadOperatorDefaults(ctx, kubeClient, "serving-system/autoscaler-defaults")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaults.NamespaceMaxInstances != 8 {
+		t.Errorf("namespaceMaxInstances = %d, want 8", defaults.NamespaceMaxInstances)
+	}
+
+	queue := map[string]interface{}{
+		"type":      "QueueLength",
+		"threshold": map[string]interface{}{"scaleUp": float64(100), "scaleDown": float64(20)},
+	}
+	inherited, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{"metrics": []interface{}{queue}}), defaults)
+	if err != nil {
+		t.Fatalf("parsePolicy: %v", err)
+	}
+	if inherited.PrometheusAddress != "http://thanos-query.monitoring:9090" || inherited.ScaleUpCooldownSeconds != 30 ||
+		inherited.ScaleDownCooldownSeconds != 600 || inherited.Metrics[0].Query != `sum(vllm:num_requests_waiting{app="{{.AppLabel}}"})` {
+		t.Errorf("policy = %+v, want the ConfigMap defaults", inherited)
+	}
+
+	custom := testMetric("QueueLength", "sum(my_queue)", 100, 20)
+	overridden, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{
+		"metrics":    []interface{}{custom},
+		"prometheus": map[string]interface{}{"address": "http://prometheus.team-a:9090"},
+		"behavior":   map[string]interface{}{"scaleUpStabilizationSeconds": int64(0)},
+	}), defaults)
+	if err != nil {
+		t.Fatalf("parsePolicy: %v", err)
+	}
+	if overridden.PrometheusAddress != "http://prometheus.team-a:9090" || overridden.ScaleUpCooldownSeconds != 0 ||
+		overridden.ScaleDownCooldownSeconds != 600 || overridden.Metrics[0].Query != "sum(my_queue)" {
+		t.Errorf("policy = %+v, want the spec values over the defaults", overridden)
+	}
+
+	for name, data := range map[string]map[string]string{
+		"negative seconds": {"scaleUpStabilizationSeconds": "-1"},
+		"bad template":     {"query.TTFT": "{{.Foo}}"},
+	} {
+		kubeClient := kubefake.NewSimpleClientset(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "serving-system", Name: "autoscaler-defaults"},
+			Data:       data,
+		})
+		if _, err := loadOperatorDefaults(ctx, kubeClient, "serving-system/autoscaler-defaults"); err == nil {
+			t.Errorf("%s: loadOperatorDefaults accepted %v", name, data)
+		}
+	}
+}
+
+// renderedDefaultQuery is metricType's built-in query as evaluateDecision
+// sends it for newTestAutoscaler.
+func renderedDefaultQuery(t *testing.T, metricType string) string {
+	t.Helper()
+	query, err := renderQuery(defaultQueryTemplates[metricType], queryTemplateData{AppLabel: "llama", Namespace: "default", Window: defaultQueryWindow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return query
+}
+
+func TestQueueGrowthRateDecision(t *testing.T) {
+	query := renderedDefaultQuery(t, "QueueGrowthRate")
+	if !strings.HasPrefix(query, "deriv(") || !strings.Contains(query, `app="llama"`) {
+		t.Fatalf("default QueueGrowthRate query = %q", query)
+	}
+	policy, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{map[string]interface{}{
+			"type":      "QueueGrowthRate",
+			"threshold": map[string]interface{}{"scaleUp": float64(5), "scaleDown": float64(0)},
+		}},
+	}), builtinDefaults())
+	if err != nil {
+		t.Fatalf("parsePolicy: %v", err)
+	}
+
+	for _, tc := range []struct {
+		rate               float64
+		scaleUp, scaleDown bool
+	}{
+		{rate: 8, scaleUp: true},
+		{rate: 2},
+		{rate: -1.5, scaleDown: true},
+	} {
+		c := newTestController(&fakeQuerier{values: map[string][]float64{query: {tc.rate}}})
+		decision, err := c.evaluateDecision(context.Background(), policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decision.ScaleUp != tc.scaleUp || decision.ScaleDown != tc.scaleDown || decision.Observed["QueueGrowthRate"] != tc.rate {
+			t.Errorf("rate %v: scaleUp=%v scaleDown=%v observed=%v, want %v %v",
+				tc.rate, decision.ScaleUp, decision.ScaleDown, decision.Observed, tc.scaleUp, tc.scaleDown)
+		}
+	}
+}
+
+func TestActiveRequestsDecision(t *testing.T) {
+	query := renderedDefaultQuery(t, "ActiveRequests")
+	if query != `sum(vllm:num_requests_running{app="llama"})` {
+		t.Fatalf("default ActiveRequests query = %q", query)
+	}
+	policy, err := parsePolicy(newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{map[string]interface{}{
+			"type":      "ActiveRequests",
+			"threshold": map[string]interface{}{"scaleUp": float64(64), "scaleDown": float64(16)},
+		}},
+	}), builtinDefaults())
+	if err != nil {
+		t.Fatalf("parsePolicy: %v", err)
+	}
+
+	// Concurrent requests add up across the instances' series.
+	for _, tc := range []struct {
+		series             []float64
+		scaleUp, scaleDown bool
+	}{
+		{series: []float64{40, 30}, scaleUp: true},
+		{series: []float64{20, 10}},
+		{series: []float64{10, 5}, scaleDown: true},
+	} {
+		c := newTestController(&fakeQuerier{values: map[string][]float64{query: tc.series}})
+		decision, err := c.evaluateDecision(context.Background(), policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decision.ScaleUp != tc.scaleUp || decision.ScaleDown != tc.scaleDown {
+			t.Errorf("series %v: scaleUp=%v scaleDown=%v (%s), want %v %v",
+				tc.series, decision.ScaleUp, decision.ScaleDown, decision.Reason, tc.scaleUp, tc.scaleDown)
+		}
+	}
+}
+
+func TestRejectedRequestRateScalesUpPastCooldown(t *testing.T) {
+	ctx := context.Background()
+	query := renderedDefaultQuery(t, "RejectedRequestRate")
+	for name, tc := range map[string]struct {
+		panic     interface{}
+		rate      float64
+		wantCount int
+	}{
+		"rejections above threshold":    {rate: 2.5, wantCount: 2},
+		"rejections at threshold":       {rate: 0.5, wantCount: 1},
+		"panic disabled keeps cooldown": {panic: false, rate: 2.5, wantCount: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rejected := map[string]interface{}{
+				"type":      "RejectedRequestRate",
+				"threshold": map[string]interface{}{"scaleUp": float64(0.5), "scaleDown": float64(0)},
+			}
+			if tc.panic != nil {
+				rejected["panic"] = tc.panic
+			}
+			autoscaler := newTestAutoscaler("llama", map[string]interface{}{"metrics": []interface{}{rejected}})
+			autoscaler.Object["status"] = map[string]interface{}{
+				"lastScaleUpTime": time.Now().Add(-10 * time.Second).Format(time.RFC3339),
+			}
+			c := newTestController(&fakeQuerier{values: map[string][]float64{query: {tc.rate}}}, autoscaler, newTestInstance("llama-a"))
+
+			if err := c.reconcileAutoscaler(ctx, autoscaler); err != nil {
+				t.Fatal(err)
+			}
+			list, err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace("default").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list.Items) != tc.wantCount {
+				t.Errorf("%d instances, want %d", len(list.Items), tc.wantCount)
+			}
+		})
+	}
+}
+
+func TestMetricsHistoryTrimmedToLimit(t *testing.T) {
+	history := []interface{}{}
+	for i := 1; i <= 4; i++ {
+		history = appendMetricsSample(history, map[string]interface{}{"n": int64(i)}, 3)
+	}
+	if len(history) != 3 || history[0].(map[string]interface{})["n"] != int64(2) || history[2].(map[string]interface{})["n"] != int64(4) {
+		t.Errorf("history = %v, want samples 2-4", history)
+	}
+
+	ctx := context.Background()
+	for _, limit := range []int64{2, 0} {
+		autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+			"metrics":             []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+			"metricsHistoryLimit": limit,
+		})
+		querier := &fakeQuerier{values: map[string][]float64{}}
+		c := newTestController(querier, autoscaler, newTestInstance("llama-a"))
+
+		// Distinct observations, so every cycle writes status
+		for _, queue := range []float64{30, 40, 50} {
+			querier.values["queue"] = []float64{queue}
+			current, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.reconcileAutoscaler(ctx, current); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples, found, _ := unstructured.NestedSlice(obj.Object, "status", "observedMetricsHistory")
+		if limit == 0 {
+			if found {
+				t.Errorf("limit 0: history = %v, want none", samples)
+			}
+			continue
+		}
+		var queues []float64
+		for _, sample := range samples {
+			queue, _, _ := unstructured.NestedFloat64(sample.(map[string]interface{}), "metrics", "QueueLength")
+			queues = append(queues, queue)
+		}
+		if len(queues) != 2 || queues[0] != 40 || queues[1] != 50 {
+			t.Errorf("limit %d: history QueueLength = %v, want [40 50]", limit, queues)
+		}
+	}
+}
+
+func TestInstanceGaugesFollowLiveAutoscalers(t *testing.T) {
+	ctx := context.Background()
+	autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+	})
+	// The cooldown keeps current below desired
+	autoscaler.Object["status"] = map[string]interface{}{
+		"lastScaleUpTime": time.Now().Add(-10 * time.Second).Format(time.RFC3339),
+	}
+	c := newTestController(&fakeQuerier{values: map[string][]float64{"queue": {500}}}, autoscaler, newTestInstance("llama-a"))
+
+	c.reconcileAll(ctx)
+	rendered := c.metrics.render()
+	for _, want := range []string{
+		`llmcluster_autoscaler_current_instances{namespace="default",autoscaler="llama"} 1`,
+		`llmcluster_autoscaler_desired_instances{namespace="default",autoscaler="llama"} 2`,
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("metrics missing %q:\n%s", want, rendered)
+		}
+	}
+
+	if err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Delete(ctx, "llama", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	c.reconcileAll(ctx)
+	if rendered := c.metrics.render(); strings.Contains(rendered, `autoscaler="llama"`) {
+		t.Errorf("deleted autoscaler still exported:\n%s", rendered)
+	}
+}
+
+// annotatedEvent is an Event captured by eventCapture.
+type annotatedEvent struct {
+	eventType, reason, message string
+	annotations                map[string]string
+}
+
+// eventCapture is a record.EventRecorder keeping annotations, which
+// record.FakeRecorder drops.
+type eventCapture struct {
+	events []annotatedEvent
+}
+
+func (e *eventCapture) Event(object runtime.Object, eventType, reason, message string) {
+	e.AnnotatedEventf(object, nil, eventType, reason, "%s", message)
+}
+
+func (e *eventCapture) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	e.AnnotatedEventf(object, nil, eventType, reason, messageFmt, args...)
+}
+
+func (e *eventCapture) AnnotatedEventf(_ runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	e.events = append(e.events, annotatedEvent{eventType, reason, fmt.Sprintf(messageFmt, args...), annotations})
+}
+
+func TestDecisionEventsCarryMetricAnnotations(t *testing.T) {
+	ctx := context.Background()
+	autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+		"metrics": []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+	})
+	querier := &fakeQuerier{values: map[string][]float64{"queue": {500}}}
+	c := newTestController(querier, autoscaler, newTestInstance("llama-a"))
+	events := &eventCapture{}
+	c.recorder = events
+
+	if err := c.reconcileAutoscaler(ctx, autoscaler); err != nil {
+		t.Fatal(err)
+	}
+	if len(events.events) != 1 {
+		t.Fatalf("events = %+v, want one ScaleUp", events.events)
+	}
+	if e := events.events[0]; e.eventType != corev1.EventTypeNormal || e.reason != "ScaleUp" ||
+		e.annotations[eventAnnotationObservedPrefix+"QueueLength"] != "500" || e.annotations[eventAnnotationBlockedReason] != "" {
+		t.Errorf("scale-up event = %+v", e)
+	}
+
+	// Two cycles with no data: the status is unchanged on the second, but
+	// each Blocked cycle still counts and emits.
+	events.events = nil
+	querier.values = map[string][]float64{}
+	for i := 0; i < 2; i++ {
+		if err := c.reconcileAutoscaler(ctx, autoscaler); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(events.events) != 2 {
+		t.Fatalf("events = %+v, want a ScalingBlocked per cycle", events.events)
+	}
+	for _, e := range events.events {
+		if e.eventType != corev1.EventTypeWarning || e.reason != "ScalingBlocked" ||
+			e.annotations[eventAnnotationBlockedReason] != "metrics_unavailable" {
+			t.Errorf("blocked event = %+v", e)
+		}
+	}
+	if want := `llmcluster_autoscaler_blocked{namespace="default",autoscaler="llama",reason="metrics_unavailable"} 2`; !strings.Contains(c.metrics.render(), want) {
+		t.Errorf("metrics missing %q:\n%s", want, c.metrics.render())
+	}
+}
+
+func TestScaleDownHeldByPodDisruptionBudget(t *testing.T) {
+	ctx := context.Background()
+	newPod := func(instance string) *unstructured.Unstructured {
+		pod := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Pod"}}
+		pod.SetNamespace("default")
+		pod.SetName(instance + "-0")
+		pod.SetLabels(map[string]string{"app": instance, "tier": "inference"})
+		return pod
+	}
+	newPDB := func(name string, allowed int64) *unstructured.Unstructured {
+		pdb := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "policy/v1",
+			"kind":       "PodDisruptionBudget",
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"tier": "inference"}},
+			},
+			"status": map[string]interface{}{"disruptionsAllowed": allowed},
+		}}
+		pdb.SetNamespace("default")
+		pdb.SetName(name)
+		return pdb
+	}
+
+	for name, tc := range map[string]struct {
+		allowed     int64
+		wantDrained bool
+	}{
+		"no disruptions allowed": {allowed: 0},
+		"disruption allowed":     {allowed: 1, wantDrained: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			autoscaler := newTestAutoscaler("llama", map[string]interface{}{
+				"metrics": []interface{}{testMetric("QueueLength", "queue", 100, 20)},
+			})
+			c := newTestController(&fakeQuerier{values: map[string][]float64{"queue": {5}}}, autoscaler,
+				newTestInstance("llama-1"), newTestInstance("llama-2"),
+				newPod("llama-1"), newPod("llama-2"), newPDB("inference-budget", tc.allowed))
+
+			if err := c.reconcileAutoscaler(ctx, autoscaler); err != nil {
+				t.Fatal(err)
+			}
+			list, err := c.dynamicClient.Resource(c.llmclusterGVR).Namespace("default").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			drained := false
+			for _, item := range list.Items {
+				if _, ok := item.GetAnnotations()[annotationDrainingSince]; ok {
+					drained = true
+				}
+			}
+			if drained != tc.wantDrained {
+				t.Errorf("drained = %v, want %v", drained, tc.wantDrained)
+			}
+
+			obj, err := c.dynamicClient.Resource(c.autoscalerGVR).Namespace("default").Get(ctx, "llama", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+			if len(conditions) == 0 {
+				t.Fatal("no status conditions written")
+			}
+			message, _ := conditions[0].(map[string]interface{})["message"].(string)
+			if blocked := strings.Contains(message, "PDB inference-budget allows 0 disruptions"); blocked == tc.wantDrained {
+				t.Errorf("condition message %q, want blocked=%v", message, !tc.wantDrained)
+			}
+		})
+	}
+}